@@ -6,4 +6,5 @@ require (
 	github.com/alecthomas/kong v0.2.11
 	github.com/hashicorp/go-retryablehttp v0.6.7
 	github.com/jedib0t/go-pretty/v6 v6.0.4
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,39 @@
+package account
+
+import (
+	"encoding/json"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	accountId = 1
+)
+
+type (
+	API struct {
+		Client types.TransportInterface
+	}
+)
+
+func NewAPI(client types.TransportInterface) *API {
+	return &API{
+		Client: client,
+	}
+}
+
+// GetAccountFunds returns the balance of a wallet, the main wallet when wallet is empty
+func (a *API) GetAccountFunds(wallet string) (*types.AccountFundsResponse, error) {
+	buf, err := a.Client.Call(accountId, "AccountAPING/v1.0/getAccountFunds", &types.AccountFundsParams{
+		Wallet: wallet,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result types.AccountFundsResponse
+	if err := json.Unmarshal(buf, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,352 @@
+package arbitrage
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	MarketTypeMatchOdds    = "MATCH_ODDS"
+	MarketTypeOverUnder25  = "OVER_UNDER_25"
+	MarketTypeCorrectScore = "CORRECT_SCORE"
+	MarketTypeWin          = "WIN"
+	MarketTypePlace        = "PLACE"
+
+	DefaultCommission = 0.05
+
+	// Match odds runners are listed home, away, draw
+	homePriority = 1
+	awayPriority = 2
+	drawPriority = 3
+
+	anyOtherHomeWin = "Any Other Home Win"
+	anyOtherAwayWin = "Any Other Away Win"
+	anyOtherDraw    = "Any Other Draw"
+)
+
+var (
+	MarketTypes = []string{MarketTypeMatchOdds, MarketTypeOverUnder25, MarketTypeCorrectScore, MarketTypeWin, MarketTypePlace}
+)
+
+type (
+	// Scanner compares the prices of related markets on an event and reports
+	// where backing in one and laying in another would lock in a profit
+	Scanner struct {
+		API betting.APIInterface
+		// Commission charged on net winnings, 0.05 for 5%
+		Commission float64
+		// Threshold is the smallest edge after commission reported, 0.01 for 1%
+		Threshold float64
+	}
+
+	// Market pairs a market's catalogue, which must include the market
+	// description and runner names, with its book
+	Market struct {
+		Catalogue *types.MarketCatalogueWrapper
+		Book      *types.MarketBookWrapper
+	}
+
+	// Leg is one side of a mispricing. A leg over several selections is
+	// dutched and Price is the price of the combined bet
+	Leg struct {
+		MarketId     string
+		MarketType   string
+		Side         string
+		SelectionIds []int
+		Price        types.Decimal
+	}
+
+	// Mispricing is an outcome priced in two markets so that backing it in one
+	// and laying it in the other wins at least Edge per unit staked, after
+	// commission, whether the outcome wins or loses
+	Mispricing struct {
+		EventId string
+		Outcome string
+		Back    Leg
+		Lay     Leg
+		Edge    float64
+	}
+
+	// outcome is the selections of a market that between them make up a result
+	outcome struct {
+		market       *Market
+		selectionIds []int
+	}
+)
+
+func NewScanner(api betting.APIInterface) *Scanner {
+	return &Scanner{
+		API:        api,
+		Commission: DefaultCommission,
+	}
+}
+
+// Scan fetches the related markets of an event and checks their prices
+func (s *Scanner) Scan(eventId string) ([]Mispricing, error) {
+	catalogues, err := s.API.ListMarketCatalogue(&types.MarketFilter{
+		EventIds:        []string{eventId},
+		MarketTypeCodes: MarketTypes,
+	}, 100, []string{"EVENT", "MARKET_START_TIME", "MARKET_DESCRIPTION", "RUNNER_DESCRIPTION"})
+	if err != nil {
+		return nil, err
+	}
+	if len(catalogues) == 0 {
+		return nil, nil
+	}
+
+	marketIds := make([]string, 0, len(catalogues))
+	for _, catalogue := range catalogues {
+		marketIds = append(marketIds, catalogue.MarketId)
+	}
+	books, err := s.API.ListMarketBook(marketIds, &types.PriceProjection{PriceData: []string{"EX_BEST_OFFERS"}}, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var markets []Market
+	for i := range catalogues {
+		for j := range books {
+			if books[j].MarketId == catalogues[i].MarketId {
+				markets = append(markets, Market{Catalogue: &catalogues[i], Book: &books[j]})
+			}
+		}
+	}
+	return s.Check(eventId, markets), nil
+}
+
+// Check compares the markets of one event: correct score against match odds
+// and over/under 2.5 goals, and each race's place market against its win market
+func (s *Scanner) Check(eventId string, markets []Market) []Mispricing {
+	byType := map[string][]*Market{}
+	for i := range markets {
+		market := &markets[i]
+		if market.Book.Status != types.MarketStatusOpen {
+			continue
+		}
+		marketType := marketType(market.Catalogue)
+		byType[marketType] = append(byType[marketType], market)
+	}
+
+	var found []Mispricing
+	if scores := first(byType[MarketTypeCorrectScore]); scores != nil {
+		if odds := first(byType[MarketTypeMatchOdds]); odds != nil {
+			found = append(found, s.checkMatchOdds(eventId, odds, scores)...)
+		}
+		if goals := first(byType[MarketTypeOverUnder25]); goals != nil {
+			found = append(found, s.checkOverUnder(eventId, goals, scores)...)
+		}
+	}
+	for _, win := range byType[MarketTypeWin] {
+		for _, place := range byType[MarketTypePlace] {
+			if place.Catalogue.MarketStartTime == win.Catalogue.MarketStartTime {
+				found = append(found, s.checkPlace(eventId, win, place)...)
+			}
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Edge > found[j].Edge })
+	return found
+}
+
+// checkMatchOdds prices home, away and the draw by dutching the correct scores
+func (s *Scanner) checkMatchOdds(eventId string, odds, scores *Market) []Mispricing {
+	var home, away, draw []int
+	for _, selection := range scores.Catalogue.Selections {
+		result, _, ok := parseScore(selection.Name)
+		switch {
+		case !ok:
+			continue
+		case result > 0:
+			home = append(home, selection.SelectionId)
+		case result < 0:
+			away = append(away, selection.SelectionId)
+		default:
+			draw = append(draw, selection.SelectionId)
+		}
+	}
+
+	var found []Mispricing
+	for _, selection := range odds.Catalogue.Selections {
+		var group []int
+		switch selection.Ranking {
+		case homePriority:
+			group = home
+		case awayPriority:
+			group = away
+		case drawPriority:
+			group = draw
+		}
+		if len(group) == 0 {
+			continue
+		}
+		found = append(found, s.compare(eventId, selection.Name,
+			outcome{market: odds, selectionIds: []int{selection.SelectionId}},
+			outcome{market: scores, selectionIds: group})...)
+	}
+	return found
+}
+
+// checkOverUnder prices under and over 2.5 goals by dutching the correct
+// scores. The any other scores all have at least four goals
+func (s *Scanner) checkOverUnder(eventId string, goals, scores *Market) []Mispricing {
+	var under, over []int
+	for _, selection := range scores.Catalogue.Selections {
+		_, total, ok := parseScore(selection.Name)
+		switch {
+		case !ok:
+		case total < 3:
+			under = append(under, selection.SelectionId)
+		default:
+			over = append(over, selection.SelectionId)
+		}
+	}
+
+	var found []Mispricing
+	for _, selection := range goals.Catalogue.Selections {
+		group := over
+		if strings.HasPrefix(selection.Name, "Under") {
+			group = under
+		}
+		if len(group) == 0 {
+			continue
+		}
+		found = append(found, s.compare(eventId, selection.Name,
+			outcome{market: goals, selectionIds: []int{selection.SelectionId}},
+			outcome{market: scores, selectionIds: group})...)
+	}
+	return found
+}
+
+// checkPlace finds runners whose place price exceeds their win lay price.
+// Backing the place and laying the win for the same stake cannot lose, since
+// a winner is also placed
+func (s *Scanner) checkPlace(eventId string, win, place *Market) []Mispricing {
+	var found []Mispricing
+	for _, selection := range place.Catalogue.Selections {
+		back := outcome{market: place, selectionIds: []int{selection.SelectionId}}
+		lay := outcome{market: win, selectionIds: []int{selection.SelectionId}}
+		if m, ok := s.mispricing(eventId, selection.Name, back, lay); ok {
+			found = append(found, m)
+		}
+	}
+	return found
+}
+
+// compare checks backing each outcome against laying the other
+func (s *Scanner) compare(eventId, name string, a, b outcome) []Mispricing {
+	var found []Mispricing
+	if m, ok := s.mispricing(eventId, name, a, b); ok {
+		found = append(found, m)
+	}
+	if m, ok := s.mispricing(eventId, name, b, a); ok {
+		found = append(found, m)
+	}
+	return found
+}
+
+func (s *Scanner) mispricing(eventId, name string, back, lay outcome) (Mispricing, bool) {
+	backPrice, ok := back.price(types.SideBack)
+	if !ok {
+		return Mispricing{}, false
+	}
+	layPrice, ok := lay.price(types.SideLay)
+	if !ok {
+		return Mispricing{}, false
+	}
+	// Laying net/layPrice per unit backed levels the book before commission
+	// on the lay market. Each market charges commission on its own winnings,
+	// the back market when the outcome wins and the lay market when it loses,
+	// so the edge is the worse of the two
+	net := 1 + (backPrice.Float64()-1)*(1-s.Commission)
+	layStake := net / layPrice.Float64()
+	wins := net - 1 - layStake*(layPrice.Float64()-1)
+	loses := layStake*(1-s.Commission) - 1
+	edge := math.Min(wins, loses)
+	if edge <= 0 || edge < s.Threshold {
+		return Mispricing{}, false
+	}
+	return Mispricing{
+		EventId: eventId,
+		Outcome: name,
+		Back:    back.leg(types.SideBack, backPrice),
+		Lay:     lay.leg(types.SideLay, layPrice),
+		Edge:    edge,
+	}, true
+}
+
+// price is the price of dutching the outcome's selections at their best
+// offers, 1 / sum(1 / price)
+func (o outcome) price(side string) (types.Decimal, bool) {
+	var book float64
+	for _, selectionId := range o.selectionIds {
+		runner := o.market.Book.Runner(selectionId, 0)
+		if runner == nil || runner.Status != types.RunnerStatusActive {
+			return 0, false
+		}
+		best, ok := runner.BestBack()
+		if side == types.SideLay {
+			best, ok = runner.BestLay()
+		}
+		if !ok {
+			return 0, false
+		}
+		book += 1 / best.Price.Float64()
+	}
+	if book == 0 {
+		return 0, false
+	}
+	return types.NewPrice(1 / book), true
+}
+
+func (o outcome) leg(side string, price types.Decimal) Leg {
+	return Leg{
+		MarketId:     o.market.Catalogue.MarketId,
+		MarketType:   marketType(o.market.Catalogue),
+		Side:         side,
+		SelectionIds: o.selectionIds,
+		Price:        price,
+	}
+}
+
+// parseScore reads a correct score runner name such as "2 - 1" or "Any Other
+// Home Win", returning the sign of the result and the number of goals
+func parseScore(name string) (result, goals int, ok bool) {
+	switch name {
+	case anyOtherHomeWin:
+		return 1, 4, true
+	case anyOtherAwayWin:
+		return -1, 4, true
+	case anyOtherDraw:
+		return 0, 8, true
+	}
+	var home, away int
+	if _, err := fmt.Sscanf(name, "%d - %d", &home, &away); err != nil {
+		return 0, 0, false
+	}
+	switch {
+	case home > away:
+		result = 1
+	case home < away:
+		result = -1
+	}
+	return result, home + away, true
+}
+
+func marketType(catalogue *types.MarketCatalogueWrapper) string {
+	if catalogue.Description == nil {
+		return ""
+	}
+	return catalogue.Description.MarketType
+}
+
+func first(markets []*Market) *Market {
+	if len(markets) == 0 {
+		return nil
+	}
+	return markets[0]
+}
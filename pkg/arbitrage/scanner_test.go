@@ -0,0 +1,201 @@
+package arbitrage
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type (
+	quote struct {
+		id        int
+		name      string
+		back, lay string
+	}
+
+	marketAPI struct {
+		betting.APIInterface
+		markets []Market
+	}
+)
+
+func (a *marketAPI) ListMarketCatalogue(filter *types.MarketFilter, maxResults int, marketProjection []string) ([]types.MarketCatalogueWrapper, error) {
+	var catalogues []types.MarketCatalogueWrapper
+	for _, market := range a.markets {
+		catalogues = append(catalogues, *market.Catalogue)
+	}
+	return catalogues, nil
+}
+
+func (a *marketAPI) ListMarketBook(marketIds []string, priceProjection *types.PriceProjection, orderProjection string, matchProjection string) ([]types.MarketBookWrapper, error) {
+	var books []types.MarketBookWrapper
+	for _, market := range a.markets {
+		books = append(books, *market.Book)
+	}
+	return books, nil
+}
+
+func d(s string) types.Decimal { return types.MustParseDecimal(s) }
+
+func market(id, marketType, start string, quotes ...quote) Market {
+	catalogue := &types.MarketCatalogueWrapper{
+		MarketId:        id,
+		MarketStartTime: start,
+		Description:     &types.MarketDescription{MarketType: marketType},
+	}
+	book := &types.MarketBookWrapper{MarketId: id, Status: types.MarketStatusOpen}
+	for i, q := range quotes {
+		catalogue.Selections = append(catalogue.Selections, types.Selection{SelectionId: q.id, Name: q.name, Ranking: i + 1})
+		runner := types.Runner{SelectionID: q.id, Status: types.RunnerStatusActive}
+		if q.back != "" {
+			runner.Exchange.AvailableToBack = []types.Odds{{Price: d(q.back), Size: d("100")}}
+		}
+		if q.lay != "" {
+			runner.Exchange.AvailableToLay = []types.Odds{{Price: d(q.lay), Size: d("100")}}
+		}
+		book.Runners = append(book.Runners, runner)
+	}
+	return Market{Catalogue: catalogue, Book: book}
+}
+
+func football() []Market {
+	return []Market{
+		market("1.1", MarketTypeMatchOdds, "",
+			quote{1, "Arsenal", "2", "2.02"},
+			quote{2, "Chelsea", "4", "4.1"},
+			quote{3, "The Draw", "3.5", "3.6"},
+		),
+		market("1.2", MarketTypeCorrectScore, "",
+			quote{10, "0 - 0", "7", "7.2"},
+			quote{11, "1 - 0", "5", "5.5"},
+			quote{12, "0 - 1", "8", "8.4"},
+			quote{13, "1 - 1", "7", "7.2"},
+			quote{14, anyOtherHomeWin, "5", "5.5"},
+			quote{15, anyOtherAwayWin, "8", "8.4"},
+			quote{16, anyOtherDraw, "100", "110"},
+		),
+		market("1.3", MarketTypeOverUnder25, "",
+			quote{20, "Under 2.5 Goals", "1.6", "1.62"},
+			quote{21, "Over 2.5 Goals", "2.5", "2.6"},
+		),
+	}
+}
+
+func TestScanner_Football(t *testing.T) {
+	s := NewScanner(nil)
+	found := s.Check("e1", football())
+	if len(found) != 2 {
+		t.Fatalf("Check() = %+v, want 2 mispricings", found)
+	}
+
+	home := found[0]
+	if home.Outcome != "Arsenal" || home.Back.MarketType != MarketTypeCorrectScore || home.Lay.MarketType != MarketTypeMatchOdds {
+		t.Errorf("found[0] = %+v, want correct score backed against match odds", home)
+	}
+	if !reflect.DeepEqual(home.Back.SelectionIds, []int{11, 14}) || home.Back.Price != d("2.5") || home.Lay.Price != d("2.02") {
+		t.Errorf("found[0] legs = %+v / %+v", home.Back, home.Lay)
+	}
+	if math.Abs(home.Edge-(2.425*0.95/2.02-1)) > 1e-9 {
+		t.Errorf("found[0].Edge = %v", home.Edge)
+	}
+
+	over := found[1]
+	if over.Outcome != "Over 2.5 Goals" || !reflect.DeepEqual(over.Back.SelectionIds, []int{14, 15, 16}) || over.Lay.MarketId != "1.3" {
+		t.Errorf("found[1] = %+v, want over 2.5 from the correct scores", over)
+	}
+
+	s.Threshold = 0.12
+	if found := s.Check("e1", football()); len(found) != 1 || found[0].Outcome != "Arsenal" {
+		t.Errorf("Check() above threshold = %+v", found)
+	}
+
+	s.Commission = 0.5
+	if found := s.Check("e1", football()); len(found) != 0 {
+		t.Errorf("Check() with high commission = %+v, want none", found)
+	}
+}
+
+func TestScanner_Racing(t *testing.T) {
+	api := &marketAPI{markets: []Market{
+		market("1.10", MarketTypeWin, "2020-06-01T14:00:00.000Z",
+			quote{100, "Horse A", "2.9", "3"},
+			quote{101, "Horse B", "3.9", "4"},
+		),
+		market("1.11", MarketTypePlace, "2020-06-01T14:00:00.000Z",
+			quote{100, "Horse A", "3.5", "3.6"},
+			quote{101, "Horse B", "1.5", "1.6"},
+		),
+		market("1.12", MarketTypePlace, "2020-06-01T14:30:00.000Z",
+			quote{101, "Horse B", "9", "9.5"},
+		),
+	}}
+	found, err := NewScanner(api).Scan("e2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("Scan() = %+v, want 1 mispricing", found)
+	}
+	if found[0].Outcome != "Horse A" || found[0].Back.MarketId != "1.11" || found[0].Lay.MarketId != "1.10" {
+		t.Errorf("Scan() = %+v, want place backed against win", found[0])
+	}
+	if want := 3.375*0.95/3 - 1; math.Abs(found[0].Edge-want) > 1e-9 {
+		t.Errorf("Edge = %v, want %v", found[0].Edge, want)
+	}
+}
+
+func TestScanner_LayCommission(t *testing.T) {
+	markets := func(lay string) []Market {
+		return []Market{
+			market("1.10", MarketTypeWin, "", quote{100, "Horse A", "", lay}),
+			market("1.11", MarketTypePlace, "", quote{100, "Horse A", "3", ""}),
+		}
+	}
+	tests := []struct {
+		name string
+		lay  string
+		want float64
+	}{
+		// Backing at 3 nets 2.9 after commission, 3.6% over a 2.8 lay, but
+		// commission on the lay winnings leaves a loss when the horse loses
+		{name: "lay commission wipes out the edge", lay: "2.8"},
+		{name: "edge survives both commissions", lay: "2.6", want: 2.9*0.95/2.6 - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := NewScanner(nil).Check("e3", markets(tt.lay))
+			if tt.want == 0 {
+				if len(found) != 0 {
+					t.Errorf("Check() = %+v, want none", found)
+				}
+				return
+			}
+			if len(found) != 1 || math.Abs(found[0].Edge-tt.want) > 1e-9 {
+				t.Errorf("Check() = %+v, want edge %v", found, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScore(t *testing.T) {
+	tests := []struct {
+		name          string
+		result, goals int
+		ok            bool
+	}{
+		{"2 - 1", 1, 3, true},
+		{"0 - 0", 0, 0, true},
+		{"1 - 3", -1, 4, true},
+		{anyOtherAwayWin, -1, 4, true},
+		{"Arsenal", 0, 0, false},
+	}
+	for _, tt := range tests {
+		result, goals, ok := parseScore(tt.name)
+		if result != tt.result || goals != tt.goals || ok != tt.ok {
+			t.Errorf("parseScore(%q) = %d, %d, %v", tt.name, result, goals, ok)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package backtest
+
+import (
+	"container/heap"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/historic"
+	"github.com/guysports/go-betfair-api/pkg/matching"
+	"github.com/guysports/go-betfair-api/pkg/stream"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+var (
+	DefaultCommissionRate = types.MustParseDecimal("0.05")
+)
+
+type (
+	// Strategy is driven with every market update in timestamp order. Orders
+	// are placed through the Context, which attributes them to the strategy
+	Strategy interface {
+		Name() string
+		OnMarketUpdate(ctx *Context, book *types.MarketBookWrapper)
+		OnMarketClosed(ctx *Context, book *types.MarketBookWrapper)
+	}
+
+	// OrderUpdateHandler is optionally implemented by strategies that want to
+	// hear about fills, lapses and cancellations
+	OrderUpdateHandler interface {
+		OnOrderUpdate(ctx *Context, order *matching.Order)
+	}
+
+	Context struct {
+		Time     time.Time
+		Strategy string
+		Cache    *stream.MarketCache
+		engine   *matching.Engine
+	}
+
+	Backtester struct {
+		Strategies []Strategy
+		// CommissionRate on net market winnings, used when the market
+		// definition does not carry a base rate
+		CommissionRate types.Decimal
+
+		cache  *stream.MarketCache
+		engine *matching.Engine
+		closed map[string]bool
+		report *Report
+	}
+
+	MarketResult struct {
+		MarketId   string
+		Strategy   string
+		Orders     int
+		Matched    types.Decimal
+		Gross      types.Decimal
+		Commission types.Decimal
+		Net        types.Decimal
+		Settled    bool
+	}
+
+	StrategyResult struct {
+		Strategy   string
+		Markets    int
+		Orders     int
+		Matched    types.Decimal
+		Gross      types.Decimal
+		Commission types.Decimal
+		Net        types.Decimal
+	}
+
+	Report struct {
+		Markets    []MarketResult
+		Strategies []StrategyResult
+	}
+
+	source struct {
+		reader *historic.Reader
+		next   *types.MarketChangeMessage
+	}
+
+	sourceHeap []*source
+)
+
+func NewBacktester(strategies ...Strategy) *Backtester {
+	return &Backtester{
+		Strategies:     strategies,
+		CommissionRate: DefaultCommissionRate,
+	}
+}
+
+// Place simulates placeOrders for the strategy at the context's time
+func (c *Context) Place(params *types.PlaceInstructionParams) *types.PlaceExecutionReport {
+	if params.CustomerStrategyRef == "" {
+		params.CustomerStrategyRef = c.Strategy
+	}
+	book := c.Cache.Book(params.MarketID)
+	if book == nil {
+		return &types.PlaceExecutionReport{
+			Status:    types.InstructionStatusFailure,
+			ErrorCode: "MARKET_NOT_OPEN_FOR_BETTING",
+			MarketID:  params.MarketID,
+		}
+	}
+	return c.engine.Place(params, book, c.Time)
+}
+
+func (c *Context) Cancel(betId string, sizeReduction types.Decimal) (types.Decimal, error) {
+	return c.engine.Cancel(betId, sizeReduction)
+}
+
+// Orders returns the strategy's own orders in a market
+func (c *Context) Orders(marketId string) []*matching.Order {
+	var orders []*matching.Order
+	for _, order := range c.engine.Orders(marketId) {
+		if order.CustomerStrategyRef == c.Strategy {
+			orders = append(orders, order)
+		}
+	}
+	return orders
+}
+
+// Run replays the readers merged into a single timestamp ordered sequence and
+// returns the P&L report. Markets that never close in the data are reported
+// unsettled with their matched volume but no P&L
+func (b *Backtester) Run(readers ...*historic.Reader) (*Report, error) {
+	b.cache = stream.NewMarketCache()
+	b.engine = matching.NewEngine()
+	b.closed = map[string]bool{}
+	b.report = &Report{}
+
+	sources := sourceHeap{}
+	for _, reader := range readers {
+		src := &source{reader: reader}
+		ok, err := src.advance()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			sources = append(sources, src)
+		}
+	}
+	heap.Init(&sources)
+
+	for sources.Len() > 0 {
+		src := sources[0]
+		msg := src.next
+		ok, err := src.advance()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			heap.Fix(&sources, 0)
+		} else {
+			heap.Pop(&sources)
+		}
+		b.apply(msg)
+	}
+
+	for _, marketId := range b.cache.MarketIds() {
+		if !b.closed[marketId] {
+			b.settle(b.cache.Book(marketId), false)
+		}
+	}
+	b.summarise()
+	return b.report, nil
+}
+
+func (b *Backtester) apply(msg *types.MarketChangeMessage) {
+	at := msg.Time()
+	for _, marketId := range b.cache.Apply(msg) {
+		if b.closed[marketId] {
+			continue
+		}
+		if def := b.cache.Definition(marketId); def != nil && def.PriceLadderDefinition != nil {
+			b.engine.SetLadder(marketId, types.LadderFor(&types.MarketDescription{
+				PriceLadderDescription: def.PriceLadderDefinition,
+				LineRangeInfo:          def.LineRangeInfo,
+			}))
+		}
+		book := b.cache.Book(marketId)
+		changed := b.engine.Update(book, at)
+		for _, strategy := range b.Strategies {
+			ctx := b.context(strategy, at)
+			if handler, ok := strategy.(OrderUpdateHandler); ok {
+				for _, order := range changed {
+					if order.CustomerStrategyRef == strategy.Name() {
+						handler.OnOrderUpdate(ctx, order)
+					}
+				}
+			}
+			if book.Status == types.MarketStatusClosed {
+				strategy.OnMarketClosed(ctx, book)
+			} else {
+				strategy.OnMarketUpdate(ctx, book)
+			}
+		}
+		if book.Status == types.MarketStatusClosed {
+			b.closed[marketId] = true
+			b.settle(book, true)
+		}
+	}
+}
+
+func (b *Backtester) context(strategy Strategy, at time.Time) *Context {
+	return &Context{
+		Time:     at,
+		Strategy: strategy.Name(),
+		Cache:    b.cache,
+		engine:   b.engine,
+	}
+}
+
+func (b *Backtester) settle(book *types.MarketBookWrapper, settled bool) {
+	results := map[string]*MarketResult{}
+	var strategies []string
+	for _, settlement := range b.engine.Settle(book) {
+		name := settlement.Order.CustomerStrategyRef
+		result, ok := results[name]
+		if !ok {
+			result = &MarketResult{MarketId: book.MarketId, Strategy: name, Settled: settled}
+			results[name] = result
+			strategies = append(strategies, name)
+		}
+		result.Orders++
+		result.Matched = result.Matched.Add(settlement.Order.SizeMatched)
+		if settled {
+			result.Gross = result.Gross.Add(settlement.Profit)
+		}
+	}
+
+	rate := b.commissionRate(book.MarketId)
+	for _, name := range strategies {
+		result := results[name]
+		if result.Gross > 0 {
+			result.Commission = result.Gross.Mul(rate).Round(types.MoneyPlaces)
+		}
+		result.Net = result.Gross.Sub(result.Commission)
+		b.report.Markets = append(b.report.Markets, *result)
+	}
+}
+
+func (b *Backtester) commissionRate(marketId string) types.Decimal {
+	if def := b.cache.Definition(marketId); def != nil && def.MarketBaseRate > 0 {
+		return def.MarketBaseRate.Div(types.NewDecimalFromInt(100))
+	}
+	return b.CommissionRate
+}
+
+func (b *Backtester) summarise() {
+	byStrategy := map[string]*StrategyResult{}
+	for _, market := range b.report.Markets {
+		result, ok := byStrategy[market.Strategy]
+		if !ok {
+			result = &StrategyResult{Strategy: market.Strategy}
+			byStrategy[market.Strategy] = result
+		}
+		result.Markets++
+		result.Orders += market.Orders
+		result.Matched = result.Matched.Add(market.Matched)
+		result.Gross = result.Gross.Add(market.Gross)
+		result.Commission = result.Commission.Add(market.Commission)
+		result.Net = result.Net.Add(market.Net)
+	}
+	for _, result := range byStrategy {
+		b.report.Strategies = append(b.report.Strategies, *result)
+	}
+	sort.Slice(b.report.Strategies, func(i, j int) bool {
+		return b.report.Strategies[i].Strategy < b.report.Strategies[j].Strategy
+	})
+}
+
+func (s *source) advance() (bool, error) {
+	msg, err := s.reader.Next()
+	if err == io.EOF {
+		s.next = nil
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	s.next = msg
+	return true, nil
+}
+
+func (h sourceHeap) Len() int            { return len(h) }
+func (h sourceHeap) Less(i, j int) bool  { return h[i].next.PublishTime < h[j].next.PublishTime }
+func (h sourceHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *sourceHeap) Push(x interface{}) { *h = append(*h, x.(*source)) }
+func (h *sourceHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,76 @@
+package backtest
+
+import (
+	"testing"
+
+	"github.com/guysports/go-betfair-api/pkg/historic"
+	"github.com/guysports/go-betfair-api/pkg/matching"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type backBoth struct {
+	placed bool
+	fills  int
+	closed bool
+}
+
+func (s *backBoth) Name() string { return "backboth" }
+
+func (s *backBoth) OnMarketUpdate(ctx *Context, book *types.MarketBookWrapper) {
+	if s.placed {
+		return
+	}
+	s.placed = true
+	ctx.Place(&types.PlaceInstructionParams{
+		MarketID: book.MarketId,
+		Instructions: []types.PlaceInstruction{
+			{OrderType: types.OrderTypeLimit, SelectionId: 11, Side: types.SideBack, LimitOrder: &types.LimitOrder{Price: types.MustParseDecimal("2.6"), Size: types.NewMoney(10)}},
+			{OrderType: types.OrderTypeLimit, SelectionId: 12, Side: types.SideBack, LimitOrder: &types.LimitOrder{Price: types.MustParseDecimal("3"), Size: types.NewMoney(5)}},
+		},
+	})
+}
+
+func (s *backBoth) OnMarketClosed(ctx *Context, book *types.MarketBookWrapper) {
+	s.closed = true
+}
+
+func (s *backBoth) OnOrderUpdate(ctx *Context, order *matching.Order) {
+	s.fills++
+}
+
+func TestBacktester_Run(t *testing.T) {
+	reader, err := historic.Open("testdata/1.200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	strategy := &backBoth{}
+	report, err := NewBacktester(strategy).Run(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strategy.closed || strategy.fills != 2 {
+		t.Errorf("strategy closed %v, order updates %d", strategy.closed, strategy.fills)
+	}
+	if len(report.Markets) != 1 {
+		t.Fatalf("report.Markets = %v", report.Markets)
+	}
+	market := report.Markets[0]
+	want := MarketResult{
+		MarketId:   "1.200",
+		Strategy:   "backboth",
+		Orders:     2,
+		Matched:    types.NewMoney(15),
+		Gross:      types.NewMoney(11),
+		Commission: types.MustParseDecimal("0.55"),
+		Net:        types.MustParseDecimal("10.45"),
+		Settled:    true,
+	}
+	if market != want {
+		t.Errorf("market result = %+v, want %+v", market, want)
+	}
+	if len(report.Strategies) != 1 || report.Strategies[0].Net != want.Net {
+		t.Errorf("strategy results = %+v", report.Strategies)
+	}
+}
@@ -0,0 +1,368 @@
+package betfairtest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	LoginPath     = "/api/certlogin"
+	BettingPath   = "/exchange/betting/json-rpc/v1"
+	AccountPath   = "/exchange/account/json-rpc/v1"
+	HeartbeatPath = "/exchange/heartbeat/json-rpc/v1"
+	ScoresPath    = "/exchange/scores/json-rpc/v1"
+
+	DefaultUser         = "betfairtest"
+	DefaultPassword     = "secret"
+	DefaultAppKey       = "test-app-key"
+	DefaultSessionToken = "test-session-token"
+
+	// JSON-RPC error codes returned by the exchange
+	ErrorCodeMethodNotFound = -32601
+	ErrorCodeAPINGException = -32099
+)
+
+type (
+	// Response is the reply to a JSON-RPC call. Result is returned as the
+	// result member unless Error is set. A Status other than 200 fails the
+	// HTTP request instead, with Body as the response body
+	Response struct {
+		Result interface{}
+		Error  *types.JsonError
+		Status int
+		Body   string
+	}
+
+	// Handler scripts a reply from the request
+	Handler func(req *Request) Response
+
+	// Request is a JSON-RPC call received by the server
+	Request struct {
+		Path         string
+		Method       string
+		Params       json.RawMessage
+		AppKey       string
+		SessionToken string
+	}
+
+	// Server emulates the identity SSO certificate login and the Sports,
+	// Accounts, Heartbeat and Scores JSON-RPC endpoints over TLS with a
+	// generated CA, server certificate and client certificate, so the real
+	// JsonRPCClient can be tested offline. Calls are answered from queued
+	// responses first, then by the method's handler
+	Server struct {
+		URL          string
+		CAPath       string
+		CertPath     string
+		KeyPath      string
+		User         string
+		Password     string
+		AppKey       string
+		SessionToken string
+
+		server   *httptest.Server
+		dir      string
+		mu       sync.Mutex
+		handlers map[string]Handler
+		queued   map[string][]Response
+		requests []Request
+	}
+)
+
+// NewServer starts a server with the default credentials. Close it to stop
+// the server and remove the generated certificates
+func NewServer() (*Server, error) {
+	dir, err := ioutil.TempDir("", "betfairtest")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		CAPath:       filepath.Join(dir, "ca.pem"),
+		CertPath:     filepath.Join(dir, "client.crt"),
+		KeyPath:      filepath.Join(dir, "client.key"),
+		User:         DefaultUser,
+		Password:     DefaultPassword,
+		AppKey:       DefaultAppKey,
+		SessionToken: DefaultSessionToken,
+		dir:          dir,
+		handlers:     map[string]Handler{},
+		queued:       map[string][]Response{},
+	}
+	serverCert, clientCAs, err := s.generateCertificates()
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(LoginPath, s.login)
+	for _, path := range []string{BettingPath, AccountPath, HeartbeatPath, ScoresPath} {
+		mux.HandleFunc(path, s.jsonRPC)
+	}
+	s.server = httptest.NewUnstartedServer(mux)
+	s.server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	}
+	s.server.StartTLS()
+	s.URL = s.server.URL
+	return s, nil
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+	_ = os.RemoveAll(s.dir)
+}
+
+// Config returns a client configuration pointing every endpoint at the server
+func (s *Server) Config() *types.Config {
+	return &types.Config{
+		RootCAPath:   s.CAPath,
+		CertPath:     s.CertPath,
+		KeyPath:      s.KeyPath,
+		User:         s.User,
+		Password:     s.Password,
+		AppKey:       s.AppKey,
+		IdentityURL:  s.URL + LoginPath,
+		BettingURL:   s.URL + BettingPath,
+		AccountURL:   s.URL + AccountPath,
+		HeartbeatURL: s.URL + HeartbeatPath,
+		ScoresURL:    s.URL + ScoresPath,
+	}
+}
+
+// Handle answers every call to method with the same response. Sports API
+// methods may be given without the SportsAPING/v1.0/ prefix
+func (s *Server) Handle(method string, response Response) {
+	s.HandleFunc(method, func(*Request) Response { return response })
+}
+
+func (s *Server) HandleFunc(method string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[qualify(method)] = handler
+}
+
+// Enqueue scripts the responses to the next calls to method, in order, ahead
+// of its handler
+func (s *Server) Enqueue(method string, responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	method = qualify(method)
+	s.queued[method] = append(s.queued[method], responses...)
+}
+
+// Requests returns the JSON-RPC calls received, or only those to method
+func (s *Server) Requests(method string) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var requests []Request
+	for _, req := range s.requests {
+		if method == "" || req.Method == qualify(method) {
+			requests = append(requests, req)
+		}
+	}
+	return requests
+}
+
+// Decode unmarshals the request's params
+func (r *Request) Decode(v interface{}) error {
+	return json.Unmarshal(r.Params, v)
+}
+
+// APINGError is the JSON-RPC error the exchange returns for an APINGException
+// such as INVALID_SESSION_INFORMATION
+func APINGError(errorCode string) Response {
+	return Response{Error: &types.JsonError{Code: ErrorCodeAPINGException, Message: errorCode}}
+}
+
+// HTTPError fails the request with an HTTP status
+func HTTPError(status int) Response {
+	return Response{Status: status, Body: http.StatusText(status)}
+}
+
+func qualify(method string) string {
+	if strings.Contains(method, "/") {
+		return method
+	}
+	return "SportsAPING/v1.0/" + method
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		writeJSON(w, &types.Authenticate{LoginStatus: "CERT_AUTH_REQUIRED"})
+		return
+	}
+	if r.Header.Get("X-Application") != s.AppKey {
+		writeJSON(w, &types.Authenticate{LoginStatus: "INVALID_APP_KEY"})
+		return
+	}
+	if r.PostFormValue("username") != s.User || r.PostFormValue("password") != s.Password {
+		writeJSON(w, &types.Authenticate{LoginStatus: "INVALID_USERNAME_OR_PASSWORD"})
+		return
+	}
+	writeJSON(w, &types.Authenticate{SessionToken: s.SessionToken, LoginStatus: "SUCCESS"})
+}
+
+func (s *Server) jsonRPC(w http.ResponseWriter, r *http.Request) {
+	var query struct {
+		JsonRPC string          `json:"jsonrpc"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params"`
+		ID      int             `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := Request{
+		Path:         r.URL.Path,
+		Method:       query.Method,
+		Params:       query.Params,
+		AppKey:       r.Header.Get("X-Application"),
+		SessionToken: r.Header.Get("X-Authentication"),
+	}
+	response := s.respond(&req)
+	if response.Status != 0 && response.Status != http.StatusOK {
+		http.Error(w, response.Body, response.Status)
+		return
+	}
+	reply := types.JsonRPCResponse{JsonRPC: "2.0", ID: query.ID, Result: response.Result, Error: response.Error}
+	writeJSON(w, &reply)
+}
+
+func (s *Server) respond(req *Request) Response {
+	s.mu.Lock()
+	s.requests = append(s.requests, *req)
+	switch {
+	case req.AppKey != s.AppKey:
+		s.mu.Unlock()
+		return APINGError("INVALID_APP_KEY")
+	case req.SessionToken != s.SessionToken:
+		s.mu.Unlock()
+		return APINGError("INVALID_SESSION_INFORMATION")
+	}
+	if queued := s.queued[req.Method]; len(queued) > 0 {
+		s.queued[req.Method] = queued[1:]
+		s.mu.Unlock()
+		return queued[0]
+	}
+	handler, ok := s.handlers[req.Method]
+	s.mu.Unlock()
+
+	if !ok {
+		return Response{Error: &types.JsonError{Code: ErrorCodeMethodNotFound, Message: "Method not found"}}
+	}
+	return handler(req)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// generateCertificates writes a CA and a client key pair signed by it to the
+// server's directory and returns the server's own certificate
+func (s *Server) generateCertificates() (tls.Certificate, *x509.CertPool, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "betfairtest CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	if err := writePEM(s.CAPath, "CERTIFICATE", caDER); err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	serverCert, _, _, err := issue(ca, caKey, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1"), net.IPv6loopback},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	_, clientDER, clientKey, err := issue(ca, caKey, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: s.User},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	if err := writePEM(s.CertPath, "CERTIFICATE", clientDER); err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	if err := writePEM(s.KeyPath, "EC PRIVATE KEY", clientKey); err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	return serverCert, pool, nil
+}
+
+func issue(ca *x509.Certificate, caKey *ecdsa.PrivateKey, template *x509.Certificate) (tls.Certificate, []byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, nil, nil, err
+	}
+	template.NotBefore = ca.NotBefore
+	template.NotAfter = ca.NotAfter
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return tls.Certificate{}, nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return tls.Certificate{}, nil, nil, err
+	}
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	return cert, der, keyDER, nil
+}
+
+func writePEM(path, blockType string, der []byte) error {
+	return ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)
+}
@@ -0,0 +1,154 @@
+package betfairtest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/transport"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+func newAPI(t *testing.T) (*Server, *transport.JsonRPCClient, *betting.API) {
+	t.Helper()
+	server, err := NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := transport.NewJsonRPCClient(context.Background(), server.Config())
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return server, client, betting.NewTransportAPI(client)
+}
+
+func TestServer_Login(t *testing.T) {
+	server, _, api := newAPI(t)
+	defer server.Close()
+
+	auth, err := api.Authenticate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.LoginStatus != "SUCCESS" || auth.SessionToken != DefaultSessionToken {
+		t.Errorf("login %+v", auth)
+	}
+
+	server.Password = "changed"
+	auth, err = api.Authenticate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.LoginStatus != "INVALID_USERNAME_OR_PASSWORD" {
+		t.Errorf("bad password login %+v", auth)
+	}
+}
+
+func TestServer_Responses(t *testing.T) {
+	server, _, api := newAPI(t)
+	defer server.Close()
+	if _, err := api.Authenticate(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.Handle("listEventTypes", Response{Result: []types.EventTypeWrapper{
+		{EventType: &types.Detail{ID: "1", Name: "Soccer"}, MarketCount: 10},
+	}})
+	server.Enqueue("listEventTypes", APINGError("TOO_MUCH_DATA"), HTTPError(http.StatusServiceUnavailable))
+
+	tests := []struct {
+		name    string
+		wantErr string
+		want    int
+	}{
+		{name: "scripted JSON-RPC error", wantErr: "TOO_MUCH_DATA"},
+		{name: "scripted HTTP failure", wantErr: "503"},
+		{name: "canned result", want: 1},
+		{name: "canned result repeats", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := api.ListEventTypes(&types.MarketFilter{TextQuery: "Soccer"})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.want || got[0].EventType.Name != "Soccer" {
+				t.Errorf("got %+v", got)
+			}
+		})
+	}
+
+	requests := server.Requests("listEventTypes")
+	if len(requests) != 4 {
+		t.Fatalf("%d requests", len(requests))
+	}
+	var params types.Params
+	if err := requests[0].Decode(&params); err != nil {
+		t.Fatal(err)
+	}
+	if params.Filter == nil || params.Filter.TextQuery != "Soccer" || requests[0].Path != BettingPath {
+		t.Errorf("request %s %+v", requests[0].Path, params)
+	}
+}
+
+func TestServer_ScriptedHandler(t *testing.T) {
+	server, client, api := newAPI(t)
+	defer server.Close()
+	if _, err := client.Authenticate(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.HandleFunc("placeOrders", func(req *Request) Response {
+		var params struct {
+			MarketId     string                   `json:"marketId"`
+			Instructions []types.PlaceInstruction `json:"instructions"`
+		}
+		if err := req.Decode(&params); err != nil {
+			return HTTPError(http.StatusBadRequest)
+		}
+		return Response{Result: &types.PlaceExecutionReport{
+			Status:   types.InstructionStatusSuccess,
+			MarketID: params.MarketId,
+			InstructionReports: []types.PlaceInstructionReport{{
+				Status:      types.InstructionStatusSuccess,
+				Instruction: params.Instructions[0],
+				BetId:       "42",
+				SizeMatched: params.Instructions[0].LimitOrder.Size,
+			}},
+		}}
+	})
+
+	report, err := api.PlaceOrders(&types.PlaceInstructionParams{
+		MarketID: "1.1",
+		Instructions: []types.PlaceInstruction{{
+			OrderType:   types.OrderTypeLimit,
+			SelectionId: 7,
+			Side:        types.SideBack,
+			LimitOrder:  &types.LimitOrder{Size: types.NewMoney(2), Price: types.NewPrice(3)},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.MarketID != "1.1" || report.InstructionReports[0].BetId != "42" || report.InstructionReports[0].SizeMatched != types.NewMoney(2) {
+		t.Errorf("report %+v", report)
+	}
+
+	// Unknown methods and missing sessions fail like the exchange does
+	if _, err := api.ListCountries(nil); err == nil || !strings.Contains(err.Error(), "-32601") {
+		t.Errorf("unhandled method error %v", err)
+	}
+	client.SetSessionKey("expired")
+	if _, err := api.PlaceOrders(&types.PlaceInstructionParams{MarketID: "1.1"}); err == nil || !strings.Contains(err.Error(), "INVALID_SESSION_INFORMATION") {
+		t.Errorf("expired session error %v", err)
+	}
+}
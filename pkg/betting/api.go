@@ -3,7 +3,7 @@ package betting
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"reflect"
 	"time"
 
@@ -15,9 +15,38 @@ const (
 	betfairId = 1
 )
 
+// ErrNoReport is returned when placeOrders answers without an execution
+// report, so whether the orders were placed is unknown
+var ErrNoReport = errors.New("placeOrders returned no execution report")
+
 type (
+	// API is the Sports API. The transport is kept private so that every
+	// order goes through the guard the API was built with
 	API struct {
-		Client types.TransportInterface
+		client types.TransportInterface
+		guard  Guard
+	}
+
+	// Option configures an API as it is built
+	Option func(*API)
+
+	// Guard vets orders before they reach the exchange and is told the
+	// outcome, so limits hold however a caller reaches the API
+	Guard interface {
+		CheckPlace(params *types.PlaceInstructionParams) error
+		CheckReplace(params *types.ReplaceInstructionParams) error
+		Placed(params *types.PlaceInstructionParams, report *types.PlaceExecutionReport)
+		// Failed is told when placeOrders returns an error. Rejected tells
+		// whether the orders definitely were not placed
+		Failed(params *types.PlaceInstructionParams, err error)
+		Cancelled(report *types.CancelExecutionReport)
+		// CancelFailed is told when cancelOrders returns an error, after
+		// which the orders may or may not have been cancelled
+		CancelFailed(params *types.CancelInstructionParams, err error)
+		Replaced(params *types.ReplaceInstructionParams, report *types.ReplaceExecutionReport)
+		// ReplaceFailed is told when replaceOrders returns an error, after
+		// which the orders may or may not have moved
+		ReplaceFailed(params *types.ReplaceInstructionParams, err error)
 	}
 
 	APIInterface interface {
@@ -33,24 +62,62 @@ type (
 		ListRunnerBook(marketId string, selectionId int, priceProjection *types.PriceProjection, orderProjection string, matchProjection string) ([]types.MarketBookWrapper, error)
 		ListCurrentOrders() (*types.CurrentOrdersWrapper, error)
 		PlaceOrders(params *types.PlaceInstructionParams) (*types.PlaceExecutionReport, error)
+		CancelOrders(params *types.CancelInstructionParams) (*types.CancelExecutionReport, error)
+		ReplaceOrders(params *types.ReplaceInstructionParams) (*types.ReplaceExecutionReport, error)
+		ListClearedOrders(params *types.ClearedOrdersParams) (*types.ClearedOrderSummaryReport, error)
 	}
 )
 
-func NewAPI(ctx context.Context, config *types.Config) (*API, error) {
+func NewAPI(ctx context.Context, config *types.Config, opts ...Option) (*API, error) {
 	client, err := transport.NewJsonRPCClient(ctx, config)
 	if err != nil {
 		return nil, err
 	}
 
-	return &API{
-		Client: client,
-	}, nil
+	return NewTransportAPI(client, opts...), nil
+}
+
+// NewTransportAPI builds the API on an existing transport, such as a
+// SimulatedTransport or one already logged in
+func NewTransportAPI(client types.TransportInterface, opts ...Option) *API {
+	a := &API{
+		client: client,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// WithGuard passes every place, cancel and replace through guard. It can
+// only be given as the API is built, so it cannot be swapped out later
+func WithGuard(guard Guard) Option {
+	return func(a *API) {
+		a.guard = guard
+	}
+}
+
+// Rejected reports whether err is the exchange refusing a request, so it was
+// definitely not carried out. Other errors, such as a timeout, leave the
+// outcome unknown
+func Rejected(err error) bool {
+	var rpcErr *transport.RPCError
+	return errors.As(err, &rpcErr)
+}
+
+// Guarded reports whether the API was built with a guard
+func (a *API) Guarded() bool {
+	return a.guard != nil
+}
+
+// Authenticate logs in, or logs in again once the session has expired
+func (a *API) Authenticate() (*types.Authenticate, error) {
+	return a.client.Authenticate()
 }
 
 func (a *API) ListEventTypes(filter *types.MarketFilter) ([]types.EventTypeWrapper, error) {
-	buf, err := a.Client.Do(betfairId, "listEventTypes", filter, nil)
+	buf, err := a.client.Do(betfairId, "listEventTypes", filter, nil)
 	if err != nil {
-		fmt.Printf("Error is %s\n", err.Error())
 		return nil, err
 	}
 
@@ -61,7 +128,7 @@ func (a *API) ListEventTypes(filter *types.MarketFilter) ([]types.EventTypeWrapp
 }
 
 func (a *API) ListCompetitions(filter *types.MarketFilter) ([]types.CompetitionWrapper, error) {
-	buf, err := a.Client.Do(betfairId, "listCompetitions", filter, nil)
+	buf, err := a.client.Do(betfairId, "listCompetitions", filter, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +155,7 @@ func (a *API) ListTimeRanges(from, to *time.Time, filter *types.MarketFilter, gr
 		filter.MarketStartTime = &marketRange
 	}
 
-	buf, err := a.Client.Do(betfairId, "listTimeRanges", filter, &mfParams)
+	buf, err := a.client.Do(betfairId, "listTimeRanges", filter, &mfParams)
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +167,7 @@ func (a *API) ListTimeRanges(from, to *time.Time, filter *types.MarketFilter, gr
 }
 
 func (a *API) ListEvents(filter *types.MarketFilter) ([]types.EventWrapper, error) {
-	buf, err := a.Client.Do(betfairId, "listEvents", filter, nil)
+	buf, err := a.client.Do(betfairId, "listEvents", filter, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +179,7 @@ func (a *API) ListEvents(filter *types.MarketFilter) ([]types.EventWrapper, erro
 }
 
 func (a *API) ListMarketTypes(filter *types.MarketFilter) ([]types.MarketTypeWrapper, error) {
-	buf, err := a.Client.Do(betfairId, "listMarketTypes", filter, nil)
+	buf, err := a.client.Do(betfairId, "listMarketTypes", filter, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +191,7 @@ func (a *API) ListMarketTypes(filter *types.MarketFilter) ([]types.MarketTypeWra
 }
 
 func (a *API) ListCountries(filter *types.MarketFilter) ([]types.CountryWrapper, error) {
-	buf, err := a.Client.Do(betfairId, "listCountries", filter, nil)
+	buf, err := a.client.Do(betfairId, "listCountries", filter, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -136,7 +203,7 @@ func (a *API) ListCountries(filter *types.MarketFilter) ([]types.CountryWrapper,
 }
 
 func (a *API) ListVenues(filter *types.MarketFilter) ([]types.VenueWrapper, error) {
-	buf, err := a.Client.Do(betfairId, "listVenues", filter, nil)
+	buf, err := a.client.Do(betfairId, "listVenues", filter, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +222,7 @@ func (a *API) ListMarketCatalogue(filter *types.MarketFilter, maxResults int, ma
 		mfParams.MarketProjection = marketProjection
 	}
 
-	buf, err := a.Client.Do(betfairId, "listMarketCatalogue", filter, &mfParams)
+	buf, err := a.client.Do(betfairId, "listMarketCatalogue", filter, &mfParams)
 	if err != nil {
 		return nil, err
 	}
@@ -173,7 +240,7 @@ func (a *API) ListMarketBook(marketIds []string, priceProjection *types.PricePro
 		MatchProjection: matchProjection,
 	}
 
-	buf, err := a.Client.Do(betfairId, "listMarketBook", nil, &params)
+	buf, err := a.client.Do(betfairId, "listMarketBook", nil, &params)
 	if err != nil {
 		return nil, err
 	}
@@ -191,7 +258,7 @@ func (a *API) ListRunnerBook(marketId string, selectionId int, priceProjection *
 		MatchProjection: matchProjection,
 	}
 
-	buf, err := a.Client.Do(betfairId, "listRunnerBook", nil, &params)
+	buf, err := a.client.Do(betfairId, "listRunnerBook", nil, &params)
 	if err != nil {
 		return nil, err
 	}
@@ -201,7 +268,7 @@ func (a *API) ListRunnerBook(marketId string, selectionId int, priceProjection *
 }
 
 func (a *API) ListCurrentOrders() (*types.CurrentOrdersWrapper, error) {
-	buf, err := a.Client.Do(betfairId, "listCurrentOrders", nil, &types.MarketFilterParams{
+	buf, err := a.client.Do(betfairId, "listCurrentOrders", nil, &types.MarketFilterParams{
 		DateRange: &types.TimeRange{},
 	})
 	if err != nil {
@@ -213,11 +280,90 @@ func (a *API) ListCurrentOrders() (*types.CurrentOrdersWrapper, error) {
 }
 
 func (a *API) PlaceOrders(params *types.PlaceInstructionParams) (*types.PlaceExecutionReport, error) {
-	buf, err := a.Client.Do(betfairId, "placeOrders", nil, params)
+	if a.guard != nil {
+		if err := a.guard.CheckPlace(params); err != nil {
+			return nil, err
+		}
+	}
+	buf, err := a.client.Do(betfairId, "placeOrders", nil, params)
 	if err != nil {
+		if a.guard != nil {
+			a.guard.Failed(params, err)
+		}
 		return nil, err
 	}
 	var result *types.PlaceExecutionReport
-	_ = json.Unmarshal(buf, result)
+	if err := json.Unmarshal(buf, &result); err != nil || result == nil {
+		if err == nil {
+			err = ErrNoReport
+		}
+		if a.guard != nil {
+			a.guard.Failed(params, err)
+		}
+		return nil, err
+	}
+	if a.guard != nil {
+		a.guard.Placed(params, result)
+	}
+	return result, nil
+}
+
+// CancelOrders cancels all or part of unmatched bets. Omitting the
+// instructions cancels every unmatched bet on the market
+func (a *API) CancelOrders(params *types.CancelInstructionParams) (*types.CancelExecutionReport, error) {
+	buf, err := a.client.Do(betfairId, "cancelOrders", nil, params)
+	if err != nil {
+		if a.guard != nil {
+			a.guard.CancelFailed(params, err)
+		}
+		return nil, err
+	}
+	var result *types.CancelExecutionReport
+	_ = json.Unmarshal(buf, &result)
+	if a.guard != nil && result != nil {
+		a.guard.Cancelled(result)
+	}
+	return result, nil
+}
+
+// ReplaceOrders cancels unmatched bets and places their remaining size at a new price
+func (a *API) ReplaceOrders(params *types.ReplaceInstructionParams) (*types.ReplaceExecutionReport, error) {
+	if a.guard != nil {
+		if err := a.guard.CheckReplace(params); err != nil {
+			return nil, err
+		}
+	}
+	buf, err := a.client.Do(betfairId, "replaceOrders", nil, params)
+	if err != nil {
+		if a.guard != nil {
+			a.guard.ReplaceFailed(params, err)
+		}
+		return nil, err
+	}
+	var result *types.ReplaceExecutionReport
+	_ = json.Unmarshal(buf, &result)
+	if a.guard != nil && result != nil {
+		a.guard.Replaced(params, result)
+	}
+	return result, nil
+}
+
+// ListClearedOrders lists settled, voided, lapsed or cancelled orders. The
+// exchange requires a bet status, so SETTLED orders are listed when none is given
+func (a *API) ListClearedOrders(params *types.ClearedOrdersParams) (*types.ClearedOrderSummaryReport, error) {
+	if params == nil || params.BetStatus == "" {
+		defaulted := types.ClearedOrdersParams{}
+		if params != nil {
+			defaulted = *params
+		}
+		defaulted.BetStatus = types.BetStatusSettled
+		params = &defaulted
+	}
+	buf, err := a.client.Do(betfairId, "listClearedOrders", nil, params)
+	if err != nil {
+		return nil, err
+	}
+	var result *types.ClearedOrderSummaryReport
+	_ = json.Unmarshal(buf, &result)
 	return result, nil
 }
@@ -0,0 +1,97 @@
+package betting
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type recordingTransport struct {
+	types.TransportInterface
+	params []interface{}
+}
+
+func (r *recordingTransport) Do(id int, method string, filter *types.MarketFilter, additionalParams interface{}) ([]byte, error) {
+	r.params = append(r.params, additionalParams)
+	return []byte(`{"clearedOrders":[]}`), nil
+}
+
+func TestAPI_ListClearedOrdersBetStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		params *types.ClearedOrdersParams
+		want   string
+	}{
+		{name: "no params", want: types.BetStatusSettled},
+		{name: "no bet status", params: &types.ClearedOrdersParams{MarketIds: []string{"1.1"}}, want: types.BetStatusSettled},
+		{name: "bet status", params: &types.ClearedOrdersParams{BetStatus: types.BetStatusLapsed}, want: types.BetStatusLapsed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			api := NewTransportAPI(transport)
+			if _, err := api.ListClearedOrders(tt.params); err != nil {
+				t.Fatal(err)
+			}
+			sent := transport.params[0].(*types.ClearedOrdersParams)
+			if sent.BetStatus != tt.want {
+				t.Errorf("bet status %q, want %q", sent.BetStatus, tt.want)
+			}
+			if tt.params != nil && tt.params.BetStatus == "" && sent == tt.params {
+				t.Error("caller's params were modified")
+			}
+		})
+	}
+}
+
+type (
+	failingTransport struct {
+		types.TransportInterface
+		err error
+	}
+
+	// recordingGuard passes everything and records the failures it is told of
+	recordingGuard struct {
+		cancelFailed  []error
+		replaceFailed []error
+	}
+)
+
+func (f *failingTransport) Do(id int, method string, filter *types.MarketFilter, additionalParams interface{}) ([]byte, error) {
+	return nil, f.err
+}
+
+func (g *recordingGuard) CheckPlace(*types.PlaceInstructionParams) error                          { return nil }
+func (g *recordingGuard) CheckReplace(*types.ReplaceInstructionParams) error                      { return nil }
+func (g *recordingGuard) Placed(*types.PlaceInstructionParams, *types.PlaceExecutionReport)       {}
+func (g *recordingGuard) Failed(*types.PlaceInstructionParams, error)                             {}
+func (g *recordingGuard) Cancelled(*types.CancelExecutionReport)                                  {}
+func (g *recordingGuard) Replaced(*types.ReplaceInstructionParams, *types.ReplaceExecutionReport) {}
+
+func (g *recordingGuard) CancelFailed(params *types.CancelInstructionParams, err error) {
+	g.cancelFailed = append(g.cancelFailed, err)
+}
+
+func (g *recordingGuard) ReplaceFailed(params *types.ReplaceInstructionParams, err error) {
+	g.replaceFailed = append(g.replaceFailed, err)
+}
+
+func TestAPI_GuardToldOfFailures(t *testing.T) {
+	guard := &recordingGuard{}
+	failing := &failingTransport{err: errors.New("context deadline exceeded")}
+	api := NewTransportAPI(failing, WithGuard(guard))
+	if !api.Guarded() || NewTransportAPI(failing).Guarded() {
+		t.Fatal("Guarded() does not reflect WithGuard")
+	}
+
+	if _, err := api.CancelOrders(&types.CancelInstructionParams{MarketID: "1.1"}); err != failing.err {
+		t.Errorf("CancelOrders() error = %v", err)
+	}
+	if _, err := api.ReplaceOrders(&types.ReplaceInstructionParams{MarketID: "1.1"}); err != failing.err {
+		t.Errorf("ReplaceOrders() error = %v", err)
+	}
+	if len(guard.cancelFailed) != 1 || len(guard.replaceFailed) != 1 {
+		t.Errorf("guard told of %d cancel and %d replace failures, want 1 each", len(guard.cancelFailed), len(guard.replaceFailed))
+	}
+}
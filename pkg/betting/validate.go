@@ -0,0 +1,214 @@
+package betting
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	MaxCustomerRefLength         = 32
+	MaxCustomerOrderRefLength    = 32
+	MaxCustomerStrategyRefLength = 15
+)
+
+var (
+	ErrMarketMismatch             = errors.New("instructions are for a different market")
+	ErrMarketNotOpen              = errors.New("market is not open")
+	ErrCustomerRefTooLong         = fmt.Errorf("customerRef exceeds %d characters", MaxCustomerRefLength)
+	ErrCustomerStrategyRefTooLong = fmt.Errorf("customerStrategyRef exceeds %d characters", MaxCustomerStrategyRefLength)
+	ErrCustomerOrderRefTooLong    = fmt.Errorf("customerOrderRef exceeds %d characters", MaxCustomerOrderRefLength)
+	ErrUnknownSelection           = errors.New("selection is not in the market catalogue")
+	ErrRunnerNotActive            = errors.New("runner is not active")
+	ErrInvalidSide                = errors.New("side must be BACK or LAY")
+	ErrInvalidOrderType           = errors.New("order type does not match the order supplied")
+	ErrPriceNotOnLadder           = errors.New("price is not on the market's price ladder")
+	ErrInvalidStakePrecision      = errors.New("stake has more than two decimal places")
+	ErrBelowMinimumStake          = errors.New("stake is below the minimum bet size and payout")
+	ErrBelowMinimumLiability      = errors.New("liability is below the minimum BSP liability")
+	ErrBelowMinimumBSPStake       = errors.New("stake is below the minimum BSP back stake")
+)
+
+type (
+	// OrderValidator checks PlaceOrders instructions locally so that bad orders
+	// never cost a round trip or count against the transaction allowance.
+	// Catalogue and Book are optional; checks needing them are skipped when nil
+	OrderValidator struct {
+		Currency  string
+		Catalogue *types.MarketCatalogueWrapper
+		Book      *types.MarketBookWrapper
+	}
+
+	InstructionError struct {
+		Index       int
+		Instruction types.PlaceInstruction
+		Errors      []error
+	}
+
+	// ValidationError collects everything wrong with a request. Errors that
+	// apply to the whole request rather than one instruction are in Request
+	ValidationError struct {
+		Request      []error
+		Instructions []InstructionError
+	}
+)
+
+func (e *InstructionError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("instruction %d (selection %d): %s", e.Index, e.Instruction.SelectionId, strings.Join(msgs, ", "))
+}
+
+func (e *InstructionError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *ValidationError) Error() string {
+	msgs := []string{}
+	for _, err := range e.Request {
+		msgs = append(msgs, err.Error())
+	}
+	for i := range e.Instructions {
+		msgs = append(msgs, e.Instructions[i].Error())
+	}
+	return fmt.Sprintf("order validation failed: %s", strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Is(target error) bool {
+	for _, err := range e.Request {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	for i := range e.Instructions {
+		if e.Instructions[i].Is(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns a *ValidationError describing every failed check, or nil
+func (v *OrderValidator) Validate(params *types.PlaceInstructionParams) error {
+	verr := ValidationError{}
+
+	if len(params.CustomerRef) > MaxCustomerRefLength {
+		verr.Request = append(verr.Request, ErrCustomerRefTooLong)
+	}
+	if len(params.CustomerStrategyRef) > MaxCustomerStrategyRefLength {
+		verr.Request = append(verr.Request, ErrCustomerStrategyRefTooLong)
+	}
+	if (v.Catalogue != nil && params.MarketID != v.Catalogue.MarketId) || (v.Book != nil && params.MarketID != v.Book.MarketId) {
+		verr.Request = append(verr.Request, ErrMarketMismatch)
+	} else if v.Book != nil && v.Book.Status != types.MarketStatusOpen {
+		verr.Request = append(verr.Request, fmt.Errorf("%w: status %s", ErrMarketNotOpen, v.Book.Status))
+	}
+
+	for i, instruction := range params.Instructions {
+		if errs := v.validateInstruction(&instruction); len(errs) > 0 {
+			verr.Instructions = append(verr.Instructions, InstructionError{
+				Index:       i,
+				Instruction: instruction,
+				Errors:      errs,
+			})
+		}
+	}
+
+	if len(verr.Request) == 0 && len(verr.Instructions) == 0 {
+		return nil
+	}
+	return &verr
+}
+
+func (v *OrderValidator) validateInstruction(instruction *types.PlaceInstruction) []error {
+	var errs []error
+	rules := types.RulesForCurrency(v.Currency)
+	ladder := types.ClassicLadder
+	if v.Catalogue != nil {
+		ladder = types.LadderFor(v.Catalogue.Description)
+	}
+
+	if len(instruction.CustomerOrderRef) > MaxCustomerOrderRefLength {
+		errs = append(errs, ErrCustomerOrderRefTooLong)
+	}
+	if instruction.Side != types.SideBack && instruction.Side != types.SideLay {
+		errs = append(errs, ErrInvalidSide)
+	}
+	if v.Catalogue != nil && !hasSelection(v.Catalogue.Selections, instruction.SelectionId, instruction.Handicap) {
+		errs = append(errs, ErrUnknownSelection)
+	}
+	if v.Book != nil {
+		for _, runner := range v.Book.Runners {
+			if runner.SelectionID == instruction.SelectionId && runner.Handicap == instruction.Handicap && runner.Status != types.RunnerStatusActive {
+				errs = append(errs, fmt.Errorf("%w: status %s", ErrRunnerNotActive, runner.Status))
+			}
+		}
+	}
+
+	switch instruction.OrderType {
+	case types.OrderTypeLimit:
+		if instruction.LimitOrder == nil {
+			return append(errs, ErrInvalidOrderType)
+		}
+		order := instruction.LimitOrder
+		if !ladder.IsValid(order.Price) {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrPriceNotOnLadder, order.Price))
+		}
+		if order.Size != order.Size.Truncate(types.MoneyPlaces) {
+			errs = append(errs, ErrInvalidStakePrecision)
+		}
+		if !rules.MeetsMinimumStake(order.Size, order.Price) {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrBelowMinimumStake, order.Size))
+		}
+	case types.OrderTypeLimitOnClose:
+		if instruction.LimitOnCloseOrder == nil {
+			return append(errs, ErrInvalidOrderType)
+		}
+		order := instruction.LimitOnCloseOrder
+		if !ladder.IsValid(order.Price) {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrPriceNotOnLadder, order.Price))
+		}
+		if err := checkBSPMinimum(rules, instruction.Side, order.Liability); err != nil {
+			errs = append(errs, err)
+		}
+	case types.OrderTypeMarketOnClose:
+		if instruction.MarketOnCloseOrder == nil {
+			return append(errs, ErrInvalidOrderType)
+		}
+		if err := checkBSPMinimum(rules, instruction.Side, instruction.MarketOnCloseOrder.Liability); err != nil {
+			errs = append(errs, err)
+		}
+	default:
+		errs = append(errs, ErrInvalidOrderType)
+	}
+
+	return errs
+}
+
+func checkBSPMinimum(rules types.CurrencyRules, side string, liability types.Decimal) error {
+	if liability >= rules.MinBSP(side) {
+		return nil
+	}
+	if side == types.SideLay {
+		return fmt.Errorf("%w: %s", ErrBelowMinimumLiability, liability)
+	}
+	return fmt.Errorf("%w: %s", ErrBelowMinimumBSPStake, liability)
+}
+
+func hasSelection(selections []types.Selection, id int, handicap types.Decimal) bool {
+	for _, selection := range selections {
+		if selection.SelectionId == id && selection.Handicap == handicap {
+			return true
+		}
+	}
+	return false
+}
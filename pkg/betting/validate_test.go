@@ -0,0 +1,172 @@
+package betting
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+func limitBack(selectionId int, price, size string) types.PlaceInstruction {
+	return types.PlaceInstruction{
+		OrderType:   types.OrderTypeLimit,
+		SelectionId: selectionId,
+		Side:        types.SideBack,
+		LimitOrder: &types.LimitOrder{
+			Price:           types.MustParseDecimal(price),
+			Size:            types.MustParseDecimal(size),
+			PersistanceType: types.PersistenceLapse,
+		},
+	}
+}
+
+func TestOrderValidator_Validate(t *testing.T) {
+	catalogue := &types.MarketCatalogueWrapper{
+		MarketId:   "1.234",
+		Selections: []types.Selection{{SelectionId: 1}, {SelectionId: 2}},
+	}
+	book := &types.MarketBookWrapper{
+		MarketId: "1.234",
+		Status:   types.MarketStatusOpen,
+		Runners: []types.Runner{
+			{SelectionID: 1, Status: types.RunnerStatusActive},
+			{SelectionID: 2, Status: types.RunnerStatusRemoved},
+		},
+	}
+	tests := []struct {
+		name   string
+		book   *types.MarketBookWrapper
+		params types.PlaceInstructionParams
+		want   []error
+	}{
+		{
+			name: "valid order",
+			params: types.PlaceInstructionParams{
+				MarketID:     "1.234",
+				Instructions: []types.PlaceInstruction{limitBack(1, "1.83", "2")},
+			},
+		},
+		{
+			name: "below minimum stake but meets payout",
+			params: types.PlaceInstructionParams{
+				MarketID:     "1.234",
+				Instructions: []types.PlaceInstruction{limitBack(1, "20", "0.5")},
+			},
+		},
+		{
+			name: "below minimum stake and payout",
+			params: types.PlaceInstructionParams{
+				MarketID:     "1.234",
+				Instructions: []types.PlaceInstruction{limitBack(1, "2", "0.5")},
+			},
+			want: []error{ErrBelowMinimumStake},
+		},
+		{
+			name: "off ladder price and unknown selection",
+			params: types.PlaceInstructionParams{
+				MarketID:     "1.234",
+				Instructions: []types.PlaceInstruction{limitBack(3, "2.01", "2")},
+			},
+			want: []error{ErrPriceNotOnLadder, ErrUnknownSelection},
+		},
+		{
+			name: "removed runner",
+			params: types.PlaceInstructionParams{
+				MarketID:     "1.234",
+				Instructions: []types.PlaceInstruction{limitBack(2, "3", "2")},
+			},
+			want: []error{ErrRunnerNotActive},
+		},
+		{
+			name: "suspended market and long strategy ref",
+			book: &types.MarketBookWrapper{MarketId: "1.234", Status: types.MarketStatusSuspended},
+			params: types.PlaceInstructionParams{
+				MarketID:            "1.234",
+				CustomerStrategyRef: strings.Repeat("s", 16),
+				Instructions:        []types.PlaceInstruction{limitBack(1, "3", "2")},
+			},
+			want: []error{ErrMarketNotOpen, ErrCustomerStrategyRefTooLong},
+		},
+		{
+			name: "BSP liability too small",
+			params: types.PlaceInstructionParams{
+				MarketID: "1.234",
+				Instructions: []types.PlaceInstruction{{
+					OrderType:          types.OrderTypeMarketOnClose,
+					SelectionId:        1,
+					Side:               types.SideLay,
+					MarketOnCloseOrder: &types.MarketOnCloseOrder{Liability: types.NewMoney(5)},
+				}},
+			},
+			want: []error{ErrBelowMinimumLiability},
+		},
+		{
+			name: "BSP back below the lay liability minimum",
+			params: types.PlaceInstructionParams{
+				MarketID: "1.234",
+				Instructions: []types.PlaceInstruction{{
+					OrderType:          types.OrderTypeMarketOnClose,
+					SelectionId:        1,
+					Side:               types.SideBack,
+					MarketOnCloseOrder: &types.MarketOnCloseOrder{Liability: types.NewMoney(2)},
+				}},
+			},
+		},
+		{
+			name: "BSP back stake too small",
+			params: types.PlaceInstructionParams{
+				MarketID: "1.234",
+				Instructions: []types.PlaceInstruction{{
+					OrderType:         types.OrderTypeLimitOnClose,
+					SelectionId:       1,
+					Side:              types.SideBack,
+					LimitOnCloseOrder: &types.LimitOnCloseOrder{Liability: types.MustParseDecimal("0.5"), Price: types.MustParseDecimal("3")},
+				}},
+			},
+			want: []error{ErrBelowMinimumBSPStake},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := OrderValidator{Catalogue: catalogue, Book: book}
+			if tt.book != nil {
+				v.Book = tt.book
+			}
+			err := v.Validate(&tt.params)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() = %v, want *ValidationError", err)
+			}
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("Validate() = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderValidator_MarketMismatchOnce(t *testing.T) {
+	v := OrderValidator{
+		Catalogue: &types.MarketCatalogueWrapper{MarketId: "1.234", Selections: []types.Selection{{SelectionId: 1}}},
+		Book:      &types.MarketBookWrapper{MarketId: "1.234", Status: types.MarketStatusOpen},
+	}
+	err := v.Validate(&types.PlaceInstructionParams{
+		MarketID:     "1.999",
+		Instructions: []types.PlaceInstruction{limitBack(1, "3", "2")},
+	})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() = %v, want *ValidationError", err)
+	}
+	if len(verr.Request) != 1 || verr.Request[0] != ErrMarketMismatch {
+		t.Errorf("Request errors = %v, want one %v", verr.Request, ErrMarketMismatch)
+	}
+}
@@ -0,0 +1,386 @@
+package cache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	MethodListEventTypes      = "listEventTypes"
+	MethodListCompetitions    = "listCompetitions"
+	MethodListCountries       = "listCountries"
+	MethodListVenues          = "listVenues"
+	MethodListMarketCatalogue = "listMarketCatalogue"
+)
+
+var (
+	// DefaultTTLs suit data that changes a few times a day at most
+	DefaultTTLs = map[string]time.Duration{
+		MethodListEventTypes:      24 * time.Hour,
+		MethodListCompetitions:    6 * time.Hour,
+		MethodListCountries:       24 * time.Hour,
+		MethodListVenues:          24 * time.Hour,
+		MethodListMarketCatalogue: time.Hour,
+	}
+)
+
+type (
+	// API caches the near-static reference data calls of the wrapped API and
+	// the market catalogue by market. Every other call passes straight through.
+	// Results are held as JSON, so callers are free to modify what they get
+	API struct {
+		betting.APIInterface
+		// TTLs per method. A method without a TTL is not cached
+		TTLs map[string]time.Duration
+		// Path persists the cache between runs when set
+		Path string
+		Now  func() time.Time
+
+		mu         sync.Mutex
+		entries    map[string]entry
+		catalogues map[string]catalogueEntry
+	}
+
+	entry struct {
+		Method  string          `json:"method"`
+		Expires time.Time       `json:"expires"`
+		Value   json.RawMessage `json:"value"`
+	}
+
+	// catalogueEntry is a market's catalogue and the projections it was fetched with
+	catalogueEntry struct {
+		Projection []string                     `json:"projection"`
+		Expires    time.Time                    `json:"expires"`
+		Catalogue  types.MarketCatalogueWrapper `json:"catalogue"`
+	}
+
+	file struct {
+		Entries    map[string]entry          `json:"entries"`
+		Catalogues map[string]catalogueEntry `json:"catalogues"`
+	}
+)
+
+func NewAPI(api betting.APIInterface) *API {
+	ttls := map[string]time.Duration{}
+	for method, ttl := range DefaultTTLs {
+		ttls[method] = ttl
+	}
+	return &API{
+		APIInterface: api,
+		TTLs:         ttls,
+		Now:          time.Now,
+		entries:      map[string]entry{},
+		catalogues:   map[string]catalogueEntry{},
+	}
+}
+
+// Open creates a cache persisted at path, loading anything still fresh from a previous run
+func Open(api betting.APIInterface, path string) (*API, error) {
+	a := NewAPI(api)
+	a.Path = path
+	if err := a.Load(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *API) ListEventTypes(filter *types.MarketFilter) ([]types.EventTypeWrapper, error) {
+	var result []types.EventTypeWrapper
+	err := a.cached(MethodListEventTypes, filter, &result, func() (interface{}, error) {
+		return a.APIInterface.ListEventTypes(filter)
+	})
+	return result, err
+}
+
+func (a *API) ListCompetitions(filter *types.MarketFilter) ([]types.CompetitionWrapper, error) {
+	var result []types.CompetitionWrapper
+	err := a.cached(MethodListCompetitions, filter, &result, func() (interface{}, error) {
+		return a.APIInterface.ListCompetitions(filter)
+	})
+	return result, err
+}
+
+func (a *API) ListCountries(filter *types.MarketFilter) ([]types.CountryWrapper, error) {
+	var result []types.CountryWrapper
+	err := a.cached(MethodListCountries, filter, &result, func() (interface{}, error) {
+		return a.APIInterface.ListCountries(filter)
+	})
+	return result, err
+}
+
+func (a *API) ListVenues(filter *types.MarketFilter) ([]types.VenueWrapper, error) {
+	var result []types.VenueWrapper
+	err := a.cached(MethodListVenues, filter, &result, func() (interface{}, error) {
+		return a.APIInterface.ListVenues(filter)
+	})
+	return result, err
+}
+
+// ListMarketCatalogue answers a filter on market IDs alone from the cache,
+// fetching only the markets it does not hold with the projections asked for.
+// Any other filter goes to the exchange, and the catalogues it returns are cached
+func (a *API) ListMarketCatalogue(filter *types.MarketFilter, maxResults int, marketProjection []string) ([]types.MarketCatalogueWrapper, error) {
+	ttl, cacheable := a.TTLs[MethodListMarketCatalogue]
+	if !cacheable || !marketIdsOnly(filter) {
+		result, err := a.APIInterface.ListMarketCatalogue(filter, maxResults, marketProjection)
+		if err == nil && cacheable {
+			a.storeCatalogues(result, marketProjection, ttl)
+		}
+		return result, err
+	}
+
+	cached, missing := a.lookupCatalogues(filter.MarketIds, marketProjection)
+	if len(missing) > 0 {
+		fetched, err := a.APIInterface.ListMarketCatalogue(&types.MarketFilter{MarketIds: missing}, len(missing), marketProjection)
+		if err != nil {
+			return nil, err
+		}
+		a.storeCatalogues(fetched, marketProjection, ttl)
+		for _, catalogue := range fetched {
+			cached[catalogue.MarketId] = catalogue
+		}
+	}
+
+	var result []types.MarketCatalogueWrapper
+	for _, marketId := range filter.MarketIds {
+		if catalogue, ok := cached[marketId]; ok && (maxResults <= 0 || len(result) < maxResults) {
+			result = append(result, catalogue)
+			delete(cached, marketId)
+		}
+	}
+	return result, nil
+}
+
+// Catalogue returns a market's cached catalogue, if it is still fresh
+func (a *API) Catalogue(marketId string) (*types.MarketCatalogueWrapper, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	cached, ok := a.catalogues[marketId]
+	if !ok || !a.Now().Before(cached.Expires) {
+		return nil, false
+	}
+	catalogue := cached.Catalogue
+	return &catalogue, true
+}
+
+// Invalidate drops the cached results of the methods given, or of every method
+func (a *API) Invalidate(methods ...string) error {
+	a.mu.Lock()
+	for key, cached := range a.entries {
+		if len(methods) == 0 || contains(methods, cached.Method) {
+			delete(a.entries, key)
+		}
+	}
+	if len(methods) == 0 || contains(methods, MethodListMarketCatalogue) {
+		a.catalogues = map[string]catalogueEntry{}
+	}
+	a.mu.Unlock()
+	return a.Save()
+}
+
+// InvalidateCatalogues drops the cached catalogues of some markets
+func (a *API) InvalidateCatalogues(marketIds ...string) error {
+	a.mu.Lock()
+	for _, marketId := range marketIds {
+		delete(a.catalogues, marketId)
+	}
+	a.mu.Unlock()
+	return a.Save()
+}
+
+// Load reads the cache from Path, skipping anything expired. A missing file is an empty cache
+func (a *API) Load() error {
+	if a.Path == "" {
+		return nil
+	}
+	buf, err := ioutil.ReadFile(a.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var stored file
+	if err := json.Unmarshal(buf, &stored); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.Now()
+	for key, cached := range stored.Entries {
+		if now.Before(cached.Expires) {
+			a.entries[key] = cached
+		}
+	}
+	for marketId, cached := range stored.Catalogues {
+		if now.Before(cached.Expires) {
+			a.catalogues[marketId] = cached
+		}
+	}
+	return nil
+}
+
+// Save writes the cache to Path, replacing the file atomically so an
+// interrupted run never leaves it truncated
+func (a *API) Save() error {
+	if a.Path == "" {
+		return nil
+	}
+	a.mu.Lock()
+	buf, err := json.Marshal(file{Entries: a.entries, Catalogues: a.catalogues})
+	a.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(a.Path), filepath.Base(a.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), a.Path)
+}
+
+// cached decodes a fresh cached result into result, or calls fetch and caches what it returns
+func (a *API) cached(method string, filter *types.MarketFilter, result interface{}, fetch func() (interface{}, error)) error {
+	ttl, cacheable := a.TTLs[method]
+	key := method + " " + Key(filter)
+
+	a.mu.Lock()
+	cached, ok := a.entries[key]
+	a.mu.Unlock()
+	if cacheable && ok && a.Now().Before(cached.Expires) {
+		return json.Unmarshal(cached.Value, result)
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return err
+	}
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if cacheable {
+		a.mu.Lock()
+		a.entries[key] = entry{Method: method, Expires: a.Now().Add(ttl), Value: buf}
+		a.mu.Unlock()
+		// A failed save only costs a refetch next run
+		_ = a.Save()
+	}
+	return json.Unmarshal(buf, result)
+}
+
+func (a *API) lookupCatalogues(marketIds, projection []string) (map[string]types.MarketCatalogueWrapper, []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.Now()
+	found := map[string]types.MarketCatalogueWrapper{}
+	var missing []string
+	for _, marketId := range marketIds {
+		cached, ok := a.catalogues[marketId]
+		if ok && now.Before(cached.Expires) && covers(cached.Projection, projection) {
+			found[marketId] = cached.Catalogue
+		} else if !contains(missing, marketId) {
+			missing = append(missing, marketId)
+		}
+	}
+	return found, missing
+}
+
+func (a *API) storeCatalogues(catalogues []types.MarketCatalogueWrapper, projection []string, ttl time.Duration) {
+	a.mu.Lock()
+	expires := a.Now().Add(ttl)
+	for _, catalogue := range catalogues {
+		a.catalogues[catalogue.MarketId] = catalogueEntry{
+			Projection: append([]string(nil), projection...),
+			Expires:    expires,
+			Catalogue:  catalogue,
+		}
+	}
+	a.mu.Unlock()
+	// A failed save only costs a refetch next run
+	_ = a.Save()
+}
+
+// Key canonicalises a filter so that filters differing only in the order of
+// their lists share a cache entry
+func Key(filter *types.MarketFilter) string {
+	if filter == nil {
+		filter = &types.MarketFilter{}
+	}
+	canonical := *filter
+	canonical.EventTypeIds = sorted(filter.EventTypeIds)
+	canonical.EventIds = sorted(filter.EventIds)
+	canonical.CompetitionIds = sorted(filter.CompetitionIds)
+	canonical.MarketIds = sorted(filter.MarketIds)
+	canonical.Venues = sorted(filter.Venues)
+	canonical.MarketTypeCodes = sorted(filter.MarketTypeCodes)
+	canonical.MarketCountries = sorted(filter.MarketCountries)
+	canonical.RaceTypes = sorted(filter.RaceTypes)
+	canonical.MarketBettingTypes = append([]types.MarketBettingType(nil), filter.MarketBettingTypes...)
+	sort.Slice(canonical.MarketBettingTypes, func(i, j int) bool {
+		return canonical.MarketBettingTypes[i] < canonical.MarketBettingTypes[j]
+	})
+	canonical.WithOrders = append([]types.OrderStatus(nil), filter.WithOrders...)
+	sort.Slice(canonical.WithOrders, func(i, j int) bool {
+		return canonical.WithOrders[i] < canonical.WithOrders[j]
+	})
+	buf, _ := json.Marshal(canonical)
+	return string(buf)
+}
+
+// marketIdsOnly reports whether a filter selects markets by ID and nothing else
+func marketIdsOnly(filter *types.MarketFilter) bool {
+	if filter == nil || len(filter.MarketIds) == 0 {
+		return false
+	}
+	rest := *filter
+	rest.MarketIds = nil
+	return Key(&rest) == Key(nil)
+}
+
+// covers reports whether a catalogue fetched with one projection has every
+// field another asks for
+func covers(have, want []string) bool {
+	for _, projection := range want {
+		if !contains(have, projection) {
+			return false
+		}
+	}
+	return true
+}
+
+func sorted(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	values = append([]string(nil), values...)
+	sort.Strings(values)
+	return values
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
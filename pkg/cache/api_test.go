@@ -0,0 +1,197 @@
+package cache
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type countingAPI struct {
+	betting.APIInterface
+	calls      map[string]int
+	catalogues [][]string
+	fail       bool
+}
+
+func newCountingAPI() *countingAPI {
+	return &countingAPI{calls: map[string]int{}}
+}
+
+func (c *countingAPI) ListEventTypes(filter *types.MarketFilter) ([]types.EventTypeWrapper, error) {
+	c.calls[MethodListEventTypes]++
+	if c.fail {
+		return nil, errors.New("unavailable")
+	}
+	return []types.EventTypeWrapper{{EventType: &types.Detail{ID: "1", Name: "Soccer"}, MarketCount: c.calls[MethodListEventTypes]}}, nil
+}
+
+func (c *countingAPI) ListVenues(filter *types.MarketFilter) ([]types.VenueWrapper, error) {
+	c.calls[MethodListVenues]++
+	return []types.VenueWrapper{{}}, nil
+}
+
+func (c *countingAPI) ListMarketCatalogue(filter *types.MarketFilter, maxResults int, marketProjection []string) ([]types.MarketCatalogueWrapper, error) {
+	c.calls[MethodListMarketCatalogue]++
+	c.catalogues = append(c.catalogues, filter.MarketIds)
+	ids := filter.MarketIds
+	if len(ids) == 0 {
+		ids = []string{"1.1", "1.2"}
+	}
+	var result []types.MarketCatalogueWrapper
+	for _, id := range ids {
+		result = append(result, types.MarketCatalogueWrapper{MarketId: id, MarketName: "Match Odds"})
+	}
+	return result, nil
+}
+
+func TestAPI_ReferenceData(t *testing.T) {
+	upstream := newCountingAPI()
+	api := NewAPI(upstream)
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	api.Now = func() time.Time { return now }
+
+	filter := &types.MarketFilter{EventTypeIds: []string{"1", "7"}}
+	first, err := api.ListEventTypes(filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first[0].EventType.Name = "changed by caller"
+
+	// The same filter with its lists in another order is a hit
+	second, err := api.ListEventTypes(&types.MarketFilter{EventTypeIds: []string{"7", "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upstream.calls[MethodListEventTypes] != 1 || second[0].EventType.Name != "Soccer" {
+		t.Fatalf("calls = %d, result %+v, want one call and an unmodified result", upstream.calls[MethodListEventTypes], second[0].EventType)
+	}
+
+	if _, err := api.ListEventTypes(&types.MarketFilter{EventTypeIds: []string{"1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if upstream.calls[MethodListEventTypes] != 2 {
+		t.Errorf("calls for a different filter = %d, want 2", upstream.calls[MethodListEventTypes])
+	}
+
+	now = now.Add(DefaultTTLs[MethodListEventTypes])
+	if result, _ := api.ListEventTypes(filter); upstream.calls[MethodListEventTypes] != 3 || result[0].MarketCount != 3 {
+		t.Errorf("calls after expiry = %d, want 3", upstream.calls[MethodListEventTypes])
+	}
+
+	if _, err := api.ListVenues(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.Invalidate(MethodListEventTypes); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = api.ListEventTypes(filter)
+	_, _ = api.ListVenues(nil)
+	if upstream.calls[MethodListEventTypes] != 4 || upstream.calls[MethodListVenues] != 1 {
+		t.Errorf("calls after invalidate = %v, want event types refetched and venues cached", upstream.calls)
+	}
+
+	upstream.fail = true
+	if err := api.Invalidate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.ListEventTypes(filter); err == nil {
+		t.Error("ListEventTypes() error = nil, want the upstream error")
+	}
+}
+
+func TestAPI_Catalogue(t *testing.T) {
+	upstream := newCountingAPI()
+	api := NewAPI(upstream)
+	projection := []string{"EVENT", "RUNNER_DESCRIPTION"}
+
+	if _, err := api.ListMarketCatalogue(&types.MarketFilter{EventIds: []string{"e1"}}, 10, projection); err != nil {
+		t.Fatal(err)
+	}
+	result, err := api.ListMarketCatalogue(&types.MarketFilter{MarketIds: []string{"1.2", "1.3", "1.1"}}, 10, []string{"EVENT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 3 || result[0].MarketId != "1.2" || result[1].MarketId != "1.3" || result[2].MarketId != "1.1" {
+		t.Fatalf("ListMarketCatalogue() = %+v, want markets in the order asked", result)
+	}
+	if got := upstream.catalogues[1]; len(got) != 1 || got[0] != "1.3" {
+		t.Errorf("fetched %v, want only the uncached market", got)
+	}
+
+	// A wider projection than was cached refetches
+	if _, err := api.ListMarketCatalogue(&types.MarketFilter{MarketIds: []string{"1.3"}}, 10, projection); err != nil {
+		t.Fatal(err)
+	}
+	if upstream.calls[MethodListMarketCatalogue] != 3 {
+		t.Errorf("calls = %d, want 3", upstream.calls[MethodListMarketCatalogue])
+	}
+
+	if _, ok := api.Catalogue("1.1"); !ok {
+		t.Error("Catalogue() missed a cached market")
+	}
+	if err := api.InvalidateCatalogues("1.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := api.Catalogue("1.1"); ok {
+		t.Error("Catalogue() found an invalidated market")
+	}
+}
+
+func TestAPI_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	upstream := newCountingAPI()
+	api, err := Open(upstream, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.ListEventTypes(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.ListMarketCatalogue(&types.MarketFilter{MarketIds: []string{"1.1"}}, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(upstream, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.ListEventTypes(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reopened.Catalogue("1.1"); !ok {
+		t.Error("Catalogue() not restored from disk")
+	}
+	if upstream.calls[MethodListEventTypes] != 1 {
+		t.Errorf("calls = %d, want the second run served from disk", upstream.calls[MethodListEventTypes])
+	}
+
+	// Expired entries are not restored
+	expired, err := Open(upstream, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired.Now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+	if _, err := expired.ListEventTypes(nil); err != nil {
+		t.Fatal(err)
+	}
+	if upstream.calls[MethodListEventTypes] != 2 {
+		t.Errorf("calls = %d, want the expired entry refetched", upstream.calls[MethodListEventTypes])
+	}
+}
+
+func TestAPI_UnwritablePath(t *testing.T) {
+	upstream := newCountingAPI()
+	api := NewAPI(upstream)
+	api.Path = filepath.Join(t.TempDir(), "missing", "cache.json")
+	result, err := api.ListEventTypes(nil)
+	if err != nil || len(result) != 1 {
+		t.Fatalf("ListEventTypes() = %v, %v, want the fetched result", result, err)
+	}
+	if _, err := api.ListEventTypes(nil); err != nil || upstream.calls[MethodListEventTypes] != 1 {
+		t.Errorf("ListEventTypes() error = %v after %d calls, want it served from memory", err, upstream.calls[MethodListEventTypes])
+	}
+}
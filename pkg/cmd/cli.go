@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/resolver"
+	"github.com/guysports/go-betfair-api/pkg/risk"
+	"github.com/guysports/go-betfair-api/pkg/transport"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type (
+	// CLI is the command tree. Every command logs in with the global flags
+	CLI struct {
+		Globals
+
+		Events       Events       `cmd:"" help:"List events"`
+		Competitions Competitions `cmd:"" help:"List competitions"`
+		Markets      Markets      `cmd:"" help:"List market catalogues"`
+		Book         Book         `cmd:"" help:"Show the prices of markets"`
+		Orders       Orders       `cmd:"" help:"List, place, cancel and replace orders"`
+		Cleared      Cleared      `cmd:"" help:"List settled, voided, lapsed or cancelled orders"`
+		Funds        Funds        `cmd:"" help:"Show the account balance"`
+	}
+
+	// Globals are the credentials and endpoints shared by every command. Flags
+	// and environment variables take precedence over the profile
+	Globals struct {
+		ConfigPath   string        `name:"config" help:"Config file of named profiles, betfair/config.yaml in the user config directory when empty" env:"BETFAIR_CONFIG" type:"path"`
+		Profile      string        `help:"Profile to use from the config file, its default profile when empty" env:"BETFAIR_PROFILE"`
+		AppKey       string        `help:"Application key for the Betfair API" env:"BETFAIR_APP_KEY"`
+		User         string        `help:"Username for the Betfair account" env:"BETFAIR_USER"`
+		PasswordFile string        `help:"File holding the password for the Betfair account. Without one the password is read from BETFAIR_PASSWORD or prompted for" env:"BETFAIR_PASSWORD_FILE" type:"path"`
+		RootCAPath   string        `help:"Path to the RootCA certificate that Betfair signs their certificate with" type:"path"`
+		CertPath     string        `help:"Path to the User certificate created and added in the Betfair account" type:"path"`
+		KeyPath      string        `help:"Path to the User private key for the Betfair account" type:"path"`
+		IdentityURL  string        `help:"Login endpoint, for another jurisdiction or a test server"`
+		BettingURL   string        `help:"Sports API endpoint"`
+		AccountURL   string        `help:"Accounts API endpoint"`
+		Timeout      time.Duration `help:"Time allowed for the command" default:"20s"`
+		Output       string        `help:"Output format: table, json, ndjson, csv or yaml" short:"o" enum:"table,json,ndjson,csv,yaml" default:"table"`
+		Columns      []string      `help:"Columns to show in table and csv output, such as market-id,price"`
+
+		Password string                              `kong:"-"`
+		Stdout   io.Writer                           `kong:"-"`
+		Prompt   func(prompt string) (string, error) `kong:"-"`
+	}
+
+	// FilterFlags build a MarketFilter. Event types, competitions and events
+	// may be given by name and are resolved to IDs
+	FilterFlags struct {
+		TextQuery          string                    `help:"Free text search of names" group:"filter"`
+		EventType          string                    `help:"Event type name, such as Soccer" group:"filter"`
+		EventTypeIds       []string                  `help:"Event type IDs" group:"filter"`
+		Competition        string                    `help:"Competition name, such as English Premier League" group:"filter"`
+		CompetitionIds     []string                  `help:"Competition IDs" group:"filter"`
+		Event              string                    `help:"Event name, such as Arsenal v Chelsea" group:"filter"`
+		EventIds           []string                  `help:"Event IDs" group:"filter"`
+		MarketIds          []string                  `help:"Market IDs" group:"filter"`
+		MarketTypeCodes    []string                  `help:"Market types, such as MATCH_ODDS" group:"filter"`
+		MarketCountries    []string                  `help:"Country codes" group:"filter"`
+		Venues             []string                  `help:"Racing venues" group:"filter"`
+		RaceTypes          []string                  `help:"Race types, such as Flat" group:"filter"`
+		MarketBettingTypes []types.MarketBettingType `help:"Betting types, such as ODDS" group:"filter"`
+		WithOrders         []types.OrderStatus       `help:"Only markets with orders of these statuses" group:"filter"`
+		BspOnly            bool                      `help:"Only markets with a starting price" group:"filter"`
+		TurnInPlayEnabled  bool                      `help:"Only markets that will turn in play" group:"filter"`
+		InPlayOnly         bool                      `help:"Only markets in play" group:"filter"`
+		From               time.Time                 `help:"Markets starting at or after this RFC3339 time" group:"filter"`
+		To                 time.Time                 `help:"Markets starting before this RFC3339 time" group:"filter"`
+	}
+)
+
+func (g *Globals) Config() *types.Config {
+	return &types.Config{
+		RootCAPath:  g.RootCAPath,
+		CertPath:    g.CertPath,
+		KeyPath:     g.KeyPath,
+		User:        g.User,
+		Password:    g.Password,
+		AppKey:      g.AppKey,
+		IdentityURL: g.IdentityURL,
+		BettingURL:  g.BettingURL,
+		AccountURL:  g.AccountURL,
+	}
+}
+
+// Connect logs in and returns the betting API. Call cancel once the command is done
+func (g *Globals) Connect() (*betting.API, context.CancelFunc, error) {
+	client, cancel, err := g.login()
+	if err != nil {
+		return nil, nil, err
+	}
+	return betting.NewTransportAPI(client), cancel, nil
+}
+
+// ConnectGuarded logs in and returns a betting API that checks every order
+// against the limits
+func (g *Globals) ConnectGuarded(limits risk.Limits) (*betting.API, context.CancelFunc, error) {
+	client, cancel, err := g.login()
+	if err != nil {
+		return nil, nil, err
+	}
+	api, m := risk.NewAPI(client, limits)
+	// A single command has placed nothing it needs to catch up on, so only
+	// the daily loss stop needs the day's settled orders
+	if limits.DailyLossLimit == 0 {
+		m.SyncInterval = 0
+	}
+	return api, cancel, nil
+}
+
+func (g *Globals) login() (types.TransportInterface, context.CancelFunc, error) {
+	if err := g.resolve(); err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), g.Timeout)
+	client, err := transport.NewJsonRPCClient(ctx, g.Config())
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	if _, err := client.Authenticate(); err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return client, cancel, nil
+}
+
+func (g *Globals) out() io.Writer {
+	if g.Stdout != nil {
+		return g.Stdout
+	}
+	return os.Stdout
+}
+
+// Filter builds the market filter, resolving any names to IDs
+func (f *FilterFlags) Filter(api betting.APIInterface) (*types.MarketFilter, error) {
+	filter := &types.MarketFilter{
+		TextQuery:          f.TextQuery,
+		EventTypeIds:       f.EventTypeIds,
+		CompetitionIds:     f.CompetitionIds,
+		EventIds:           f.EventIds,
+		MarketIds:          f.MarketIds,
+		MarketTypeCodes:    f.MarketTypeCodes,
+		MarketCountries:    f.MarketCountries,
+		Venues:             f.Venues,
+		RaceTypes:          f.RaceTypes,
+		MarketBettingTypes: f.MarketBettingTypes,
+		WithOrders:         f.WithOrders,
+		BspOnly:            f.BspOnly,
+		TurnInPlayEnabled:  f.TurnInPlayEnabled,
+		InPlayOnly:         f.InPlayOnly,
+	}
+	if !f.From.IsZero() || !f.To.IsZero() {
+		filter.MarketStartTime = &types.TimeRange{}
+		if !f.From.IsZero() {
+			filter.MarketStartTime.From = f.From.Format(time.RFC3339)
+		}
+		if !f.To.IsZero() {
+			filter.MarketStartTime.To = f.To.Format(time.RFC3339)
+		}
+	}
+
+	r := resolver.NewResolver(api)
+	if f.EventType != "" {
+		eventType, err := r.EventType(f.EventType)
+		if err != nil {
+			return nil, err
+		}
+		filter.EventTypeIds = append(filter.EventTypeIds, eventType.ID)
+	}
+	if f.Competition != "" {
+		competition, err := r.Competition(f.Competition, &resolver.Options{EventTypeIds: filter.EventTypeIds})
+		if err != nil {
+			return nil, err
+		}
+		filter.CompetitionIds = append(filter.CompetitionIds, competition.ID)
+	}
+	if f.Event != "" {
+		event, err := r.Event(f.Event, &resolver.Options{
+			EventTypeIds:   filter.EventTypeIds,
+			CompetitionIds: filter.CompetitionIds,
+			From:           f.From,
+			To:             f.To,
+		})
+		if err != nil {
+			return nil, err
+		}
+		filter.EventIds = append(filter.EventIds, event.ID)
+	}
+	return filter, nil
+}
+
+func selectionName(catalogue *types.MarketCatalogueWrapper, selectionId int) string {
+	if catalogue != nil {
+		for _, selection := range catalogue.Selections {
+			if selection.SelectionId == selectionId {
+				return selection.Name
+			}
+		}
+	}
+	return fmt.Sprint(selectionId)
+}
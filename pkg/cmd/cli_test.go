@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/guysports/go-betfair-api/pkg/betfairtest"
+	"github.com/guysports/go-betfair-api/pkg/types"
+	"gopkg.in/yaml.v2"
+)
+
+// run logs in to the server with a profile from a config file
+func run(t *testing.T, server *betfairtest.Server, args ...string) (string, error) {
+	t.Helper()
+	config := server.Config()
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "password"), []byte(config.Password+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	profiles := &ConfigFile{Profiles: map[string]Profile{"test": {
+		AppKey:       config.AppKey,
+		User:         config.User,
+		PasswordFile: "password",
+		RootCAPath:   config.RootCAPath,
+		CertPath:     config.CertPath,
+		KeyPath:      config.KeyPath,
+		IdentityURL:  config.IdentityURL,
+		BettingURL:   config.BettingURL,
+		AccountURL:   config.AccountURL,
+	}}}
+	buf, err := yaml.Marshal(profiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, buf, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var cli CLI
+	parser, err := kong.New(&cli, kong.Name("betfair"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := parser.Parse(append([]string{"--config", path, "--profile", "test"}, args...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	cli.Globals.Stdout = &out
+	err = ctx.Run(&cli.Globals)
+	return out.String(), err
+}
+
+func TestCLI_Events(t *testing.T) {
+	server, err := betfairtest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	server.Handle("listEventTypes", betfairtest.Response{Result: []types.EventTypeWrapper{
+		{EventType: &types.Detail{ID: "1", Name: "Soccer"}},
+	}})
+	server.Handle("listEvents", betfairtest.Response{Result: []types.EventWrapper{
+		{Event: &types.Detail{ID: "29001", Name: "Arsenal v Chelsea", CountryCode: "GB"}, MarketCount: 40},
+	}})
+
+	out, err := run(t, server, "events", "--event-type", "soccer", "--market-countries", "GB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Arsenal v Chelsea") || !strings.Contains(out, "29001") {
+		t.Errorf("output\n%s", out)
+	}
+
+	out, err = run(t, server, "events", "--event-type-ids", "1", "--output", "csv", "--columns", "name,id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Name,ID\nArsenal v Chelsea,29001\n"; out != want {
+		t.Errorf("csv output %q, want %q", out, want)
+	}
+
+	var params types.Params
+	requests := server.Requests("listEvents")
+	if len(requests) != 2 {
+		t.Fatalf("%d listEvents requests", len(requests))
+	}
+	if err := requests[0].Decode(&params); err != nil {
+		t.Fatal(err)
+	}
+	if params.Filter == nil || len(params.Filter.EventTypeIds) != 1 || params.Filter.EventTypeIds[0] != "1" ||
+		len(params.Filter.MarketCountries) != 1 {
+		t.Errorf("filter %+v", params.Filter)
+	}
+}
+
+func TestCLI_Orders(t *testing.T) {
+	server, err := betfairtest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	server.Handle("placeOrders", betfairtest.Response{Result: &types.PlaceExecutionReport{
+		Status: types.InstructionStatusSuccess,
+		InstructionReports: []types.PlaceInstructionReport{{
+			Status:      types.InstructionStatusSuccess,
+			BetId:       "42",
+			SizeMatched: types.NewMoney(2),
+		}},
+	}})
+	server.Handle("cancelOrders", betfairtest.Response{Result: &types.CancelExecutionReport{
+		Status:    types.InstructionStatusFailure,
+		ErrorCode: "BET_ACTION_ERROR",
+	}})
+	server.Handle("replaceOrders", betfairtest.Response{})
+	server.Handle("listCurrentOrders", betfairtest.Response{Result: &types.CurrentOrdersWrapper{}})
+	server.Handle("listClearedOrders", betfairtest.Response{Result: &types.ClearedOrderSummaryReport{
+		ClearedOrders: []types.ClearedOrderSummary{{
+			BetId: "41", MarketId: "1.2", BetOutcome: "LOST", Profit: types.NewMoney(-10),
+			SettledDate: time.Now().UTC().Format(time.RFC3339),
+		}},
+	}})
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{name: "place", args: []string{"orders", "place", "--market-id", "1.1", "--selection-id", "7", "--price", "3.5", "--size", "2"}, want: "42"},
+		{name: "place over the stake limit", args: []string{"orders", "place", "--market-id", "1.1", "--selection-id", "7", "--price", "3.5", "--size", "2", "--max-order-stake", "1"}, wantErr: "MAX_ORDER_STAKE"},
+		{name: "place after the daily loss", args: []string{"orders", "place", "--market-id", "1.1", "--selection-id", "7", "--price", "3.5", "--size", "2", "--daily-loss-limit", "10"}, wantErr: "DAILY_LOSS_STOP"},
+		{name: "replace after the daily loss", args: []string{"orders", "replace", "--market-id", "1.1", "--bet-id", "42", "--new-price", "4", "--daily-loss-limit", "10"}, wantErr: "DAILY_LOSS_STOP"},
+		{name: "cancel needs a scope", args: []string{"orders", "cancel"}, wantErr: ErrCancelScope.Error()},
+		{name: "cancel failure", args: []string{"orders", "cancel", "--market-id", "1.1"}, wantErr: "BET_ACTION_ERROR"},
+		{name: "replace without a report", args: []string{"orders", "replace", "--market-id", "1.1", "--bet-id", "42", "--new-price", "4"}, wantErr: ErrNoReport.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := run(t, server, tt.args...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output\n%s", out)
+			}
+		})
+	}
+
+	if n := len(server.Requests("placeOrders")); n != 1 {
+		t.Errorf("%d placeOrders requests, want the refused orders kept back", n)
+	}
+	var params types.PlaceInstructionParams
+	if err := server.Requests("placeOrders")[0].Decode(&params); err != nil {
+		t.Fatal(err)
+	}
+	order := params.Instructions[0]
+	if params.MarketID != "1.1" || order.SelectionId != 7 || order.Side != types.SideBack ||
+		order.LimitOrder.Price != types.NewPrice(3.5) || order.LimitOrder.Size != types.NewMoney(2) {
+		t.Errorf("placed %+v %+v", params, order.LimitOrder)
+	}
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	PasswordEnv = "BETFAIR_PASSWORD"
+)
+
+var (
+	ErrNoAppKey       = errors.New("an application key is required, set --app-key, BETFAIR_APP_KEY or app-key in the profile")
+	ErrUnknownProfile = errors.New("unknown profile")
+	ErrNoPassword     = errors.New("a password is required, set --password-file, BETFAIR_PASSWORD or password-file in the profile")
+)
+
+type (
+	// ConfigFile holds named profiles, for example
+	//
+	//   default: live
+	//   profiles:
+	//     live:
+	//       app-key: abc123
+	//       user: punter
+	//       password-file: ~/.config/betfair/password
+	//       cert-path: client-2048.crt
+	//       key-path: client-2048.key
+	//
+	// Relative paths are taken from the directory of the file
+	ConfigFile struct {
+		Default  string             `yaml:"default"`
+		Profiles map[string]Profile `yaml:"profiles"`
+	}
+
+	// Profile is one account and the endpoints it logs in to
+	Profile struct {
+		AppKey       string `yaml:"app-key"`
+		User         string `yaml:"user"`
+		PasswordFile string `yaml:"password-file"`
+		RootCAPath   string `yaml:"root-ca-path"`
+		CertPath     string `yaml:"cert-path"`
+		KeyPath      string `yaml:"key-path"`
+		IdentityURL  string `yaml:"identity-url"`
+		BettingURL   string `yaml:"betting-url"`
+		AccountURL   string `yaml:"account-url"`
+	}
+)
+
+// DefaultConfigPath is where the config file is read from when no path is
+// given: betfair/config.yaml in os.UserConfigDir, which is ~/.config on Linux,
+// ~/Library/Application Support on macOS and %AppData% on Windows
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "betfair", "config.yaml")
+}
+
+// LoadConfig reads the profiles in path, making their paths absolute
+func LoadConfig(path string) (*ConfigFile, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config ConfigFile
+	if err := yaml.UnmarshalStrict(buf, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	for name, profile := range config.Profiles {
+		for _, p := range []*string{&profile.PasswordFile, &profile.RootCAPath, &profile.CertPath, &profile.KeyPath} {
+			*p = resolvePath(dir, *p)
+		}
+		config.Profiles[name] = profile
+	}
+	return &config, nil
+}
+
+// Profile returns the named profile, or the default one when name is empty.
+// With neither there is no profile and nil is returned
+func (c *ConfigFile) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = c.Default
+	}
+	if name == "" {
+		return nil, nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProfile, name)
+	}
+	return &profile, nil
+}
+
+func resolvePath(dir, path string) string {
+	switch {
+	case path == "":
+		return ""
+	case path == "~" || strings.HasPrefix(path, "~/"):
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	case !filepath.IsAbs(path):
+		return filepath.Join(dir, path)
+	}
+	return path
+}
+
+// resolve fills the settings the flags and environment left empty from the
+// profile and finds the password, so nothing is needed until a command logs in
+func (g *Globals) resolve() error {
+	path := g.ConfigPath
+	if path == "" {
+		path = DefaultConfigPath()
+	}
+	if path != "" {
+		config, err := LoadConfig(path)
+		switch {
+		case err == nil:
+			profile, err := config.Profile(g.Profile)
+			if err != nil {
+				return err
+			}
+			g.apply(profile)
+		case g.ConfigPath != "" || !os.IsNotExist(err):
+			return err
+		case g.Profile != "":
+			return fmt.Errorf("%w: %s, no config file at %s", ErrUnknownProfile, g.Profile, path)
+		}
+	}
+
+	if g.AppKey == "" {
+		return ErrNoAppKey
+	}
+	if g.Password != "" {
+		return nil
+	}
+	return g.readPassword()
+}
+
+func (g *Globals) apply(profile *Profile) {
+	if profile == nil {
+		return
+	}
+	fill := func(setting *string, value string) {
+		if *setting == "" {
+			*setting = value
+		}
+	}
+	fill(&g.AppKey, profile.AppKey)
+	fill(&g.User, profile.User)
+	fill(&g.RootCAPath, profile.RootCAPath)
+	fill(&g.CertPath, profile.CertPath)
+	fill(&g.KeyPath, profile.KeyPath)
+	fill(&g.IdentityURL, profile.IdentityURL)
+	fill(&g.BettingURL, profile.BettingURL)
+	fill(&g.AccountURL, profile.AccountURL)
+	// A password in the environment outranks a file named by the profile
+	if os.Getenv(PasswordEnv) == "" {
+		fill(&g.PasswordFile, profile.PasswordFile)
+	}
+}
+
+// readPassword takes the password from the password file, the environment
+// or, failing both, a prompt
+func (g *Globals) readPassword() error {
+	if g.PasswordFile != "" {
+		buf, err := ioutil.ReadFile(g.PasswordFile)
+		if err != nil {
+			return err
+		}
+		g.Password = strings.TrimRight(string(buf), "\r\n")
+	} else if password := os.Getenv(PasswordEnv); password != "" {
+		g.Password = password
+	} else {
+		prompt := g.Prompt
+		if prompt == nil {
+			prompt = promptPassword
+		}
+		password, err := prompt(fmt.Sprintf("Password for %s: ", g.User))
+		if err != nil {
+			return err
+		}
+		g.Password = password
+	}
+	if g.Password == "" {
+		return ErrNoPassword
+	}
+	return nil
+}
+
+// promptPassword asks on stderr and reads a line from stdin, turning off the
+// echo when stdin is a terminal
+func promptPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		if stty(false) == nil {
+			defer func() {
+				_ = stty(true)
+				fmt.Fprintln(os.Stderr)
+			}()
+		}
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", ErrNoPassword
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func stty(echo bool) error {
+	mode := "-echo"
+	if echo {
+		mode = "echo"
+	}
+	cmd := exec.Command("stty", mode)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
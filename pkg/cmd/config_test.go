@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testConfig = `default: live
+profiles:
+  live:
+    app-key: live-key
+    user: punter
+    password-file: live-password
+    cert-path: certs/client.crt
+  test:
+    app-key: test-key
+    user: tester
+    identity-url: https://localhost:8443
+`
+
+func TestGlobals_Resolve(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.yaml")
+	files := map[string]string{
+		config:                              testConfig,
+		filepath.Join(dir, "live-password"): "live-secret\n",
+		filepath.Join(dir, "flag-password"): "flag-secret",
+	}
+	for path, content := range files {
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prompted := func(prompt string) (string, error) { return "prompt-secret", nil }
+
+	tests := []struct {
+		name     string
+		globals  Globals
+		env      string
+		want     Globals
+		wantErr  error
+		wantFail bool
+	}{
+		{
+			name:    "default profile",
+			globals: Globals{ConfigPath: config},
+			want:    Globals{AppKey: "live-key", User: "punter", Password: "live-secret", CertPath: filepath.Join(dir, "certs", "client.crt")},
+		},
+		{
+			name:    "flags outrank the profile",
+			globals: Globals{ConfigPath: config, AppKey: "flag-key", PasswordFile: filepath.Join(dir, "flag-password")},
+			env:     "env-secret",
+			want:    Globals{AppKey: "flag-key", User: "punter", Password: "flag-secret", CertPath: filepath.Join(dir, "certs", "client.crt")},
+		},
+		{
+			name:    "environment password outranks the profile",
+			globals: Globals{ConfigPath: config},
+			env:     "env-secret",
+			want:    Globals{AppKey: "live-key", User: "punter", Password: "env-secret", CertPath: filepath.Join(dir, "certs", "client.crt")},
+		},
+		{
+			name:    "named profile prompts for the password",
+			globals: Globals{ConfigPath: config, Profile: "test", Prompt: prompted},
+			want:    Globals{AppKey: "test-key", User: "tester", Password: "prompt-secret", IdentityURL: "https://localhost:8443"},
+		},
+		{
+			name:    "unknown profile",
+			globals: Globals{ConfigPath: config, Profile: "staging"},
+			wantErr: ErrUnknownProfile,
+		},
+		{
+			name:     "missing config file",
+			globals:  Globals{ConfigPath: filepath.Join(dir, "missing.yaml")},
+			wantFail: true,
+		},
+		{
+			name:    "empty prompt",
+			globals: Globals{ConfigPath: config, Profile: "test", Prompt: func(string) (string, error) { return "", nil }},
+			wantErr: ErrNoPassword,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv(PasswordEnv, tt.env)
+			defer os.Unsetenv(PasswordEnv)
+
+			globals := tt.globals
+			err := globals.resolve()
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			case tt.wantFail:
+				if err == nil {
+					t.Fatal("resolved without a config file")
+				}
+				return
+			case err != nil:
+				t.Fatal(err)
+			}
+			if globals.AppKey != tt.want.AppKey || globals.User != tt.want.User || globals.Password != tt.want.Password ||
+				globals.CertPath != tt.want.CertPath || globals.IdentityURL != tt.want.IdentityURL {
+				t.Errorf("resolved %+v, want %+v", globals, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlobals_ResolveWithoutConfig(t *testing.T) {
+	home := t.TempDir()
+	for _, key := range []string{"HOME", "XDG_CONFIG_HOME"} {
+		defer os.Setenv(key, os.Getenv(key))
+		os.Setenv(key, home)
+	}
+
+	globals := Globals{}
+	if err := globals.resolve(); !errors.Is(err, ErrNoAppKey) {
+		t.Errorf("error %v, want %v", err, ErrNoAppKey)
+	}
+	globals = Globals{Profile: "live"}
+	if err := globals.resolve(); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("error %v, want %v", err, ErrUnknownProfile)
+	}
+}
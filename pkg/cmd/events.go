@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type (
+	Events struct {
+		FilterFlags
+	}
+
+	Competitions struct {
+		FilterFlags
+	}
+)
+
+func (e *Events) Run(globals *Globals) error {
+	api, cancel, err := globals.Connect()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter, err := e.Filter(api)
+	if err != nil {
+		return err
+	}
+	events, err := api.ListEvents(filter)
+	if err != nil {
+		return err
+	}
+	var rows []table.Row
+	for _, event := range events {
+		if event.Event != nil {
+			rows = append(rows, table.Row{event.Event.ID, event.Event.Name, event.Event.CountryCode, event.Event.OpenDate, event.MarketCount})
+		}
+	}
+	return globals.print(&Result{Data: events, Header: []string{"ID", "Name", "Country", "Open Date", "Markets"}, Rows: rows})
+}
+
+func (c *Competitions) Run(globals *Globals) error {
+	api, cancel, err := globals.Connect()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter, err := c.Filter(api)
+	if err != nil {
+		return err
+	}
+	competitions, err := api.ListCompetitions(filter)
+	if err != nil {
+		return err
+	}
+	var rows []table.Row
+	for _, competition := range competitions {
+		if competition.Competition != nil {
+			rows = append(rows, table.Row{competition.Competition.ID, competition.Competition.Name, competition.Region, competition.MarketCount})
+		}
+	}
+	return globals.print(&Result{Data: competitions, Header: []string{"ID", "Name", "Region", "Markets"}, Rows: rows})
+}
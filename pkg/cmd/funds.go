@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/guysports/go-betfair-api/pkg/account"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type (
+	Funds struct {
+		Wallet string `help:"Wallet to show, the main wallet when empty"`
+	}
+)
+
+func (f *Funds) Run(globals *Globals) error {
+	client, cancel, err := globals.login()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	funds, err := account.NewAPI(client).GetAccountFunds(f.Wallet)
+	if err != nil {
+		return err
+	}
+	return globals.print(&Result{
+		Data:   funds,
+		Header: []string{"Wallet", "Available", "Exposure", "Exposure Limit", "Retained Commission", "Discount Rate", "Points"},
+		Rows: []table.Row{{
+			funds.Wallet, money(funds.AvailableToBetBalance), money(funds.Exposure), money(funds.ExposureLimit),
+			money(funds.RetainedCommission), funds.DiscountRate, funds.PointsBalance,
+		}},
+	})
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"github.com/guysports/go-betfair-api/pkg/types"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type (
+	Markets struct {
+		FilterFlags
+		MaxResults int      `help:"Most markets to list, up to 1000" default:"100"`
+		Projection []string `help:"Market projections to fetch" default:"EVENT,MARKET_START_TIME,RUNNER_DESCRIPTION"`
+	}
+
+	Book struct {
+		MarketIds       []string `arg:"" help:"Markets to show"`
+		PriceData       []string `help:"Price data to fetch" default:"EX_BEST_OFFERS"`
+		OrderProjection string   `help:"Include your orders: ALL, EXECUTABLE or EXECUTION_COMPLETE"`
+		MatchProjection string   `help:"Roll up your matches: NO_ROLLUP, ROLLED_UP_BY_PRICE or ROLLED_UP_BY_AVG_PRICE"`
+	}
+)
+
+func (m *Markets) Run(globals *Globals) error {
+	api, cancel, err := globals.Connect()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter, err := m.Filter(api)
+	if err != nil {
+		return err
+	}
+	catalogues, err := api.ListMarketCatalogue(filter, m.MaxResults, m.Projection)
+	if err != nil {
+		return err
+	}
+	var rows []table.Row
+	for _, catalogue := range catalogues {
+		event := ""
+		if catalogue.Event != nil {
+			event = catalogue.Event.Name
+		}
+		rows = append(rows, table.Row{catalogue.MarketId, catalogue.MarketName, event, catalogue.MarketStartTime, len(catalogue.Selections), catalogue.TotalMatched})
+	}
+	return globals.print(&Result{Data: catalogues, Header: []string{"Market ID", "Name", "Event", "Start Time", "Runners", "Matched"}, Rows: rows})
+}
+
+func (b *Book) Run(globals *Globals) error {
+	api, cancel, err := globals.Connect()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	// The catalogue supplies the runner names the book lacks
+	catalogues, err := api.ListMarketCatalogue(&types.MarketFilter{MarketIds: b.MarketIds}, len(b.MarketIds), []string{"RUNNER_DESCRIPTION"})
+	if err != nil {
+		return err
+	}
+	books, err := api.ListMarketBook(b.MarketIds, &types.PriceProjection{PriceData: b.PriceData}, b.OrderProjection, b.MatchProjection)
+	if err != nil {
+		return err
+	}
+
+	var rows []table.Row
+	for i := range books {
+		book := &books[i]
+		var catalogue *types.MarketCatalogueWrapper
+		for j := range catalogues {
+			if catalogues[j].MarketId == book.MarketId {
+				catalogue = &catalogues[j]
+			}
+		}
+		for j := range book.Runners {
+			runner := &book.Runners[j]
+			row := table.Row{book.MarketId, book.Status, selectionName(catalogue, runner.SelectionID), runner.Status}
+			row = append(row, odds(runner.BestBack())...)
+			row = append(row, odds(runner.BestLay())...)
+			rows = append(rows, append(row, runner.LastPriceTraded, runner.TotalMatched))
+		}
+	}
+	return globals.print(&Result{
+		Data:   books,
+		Header: []string{"Market ID", "Market Status", "Selection", "Status", "Back", "Back Size", "Lay", "Lay Size", "Last Traded", "Matched"},
+		Rows:   rows,
+	})
+}
+
+func odds(best types.Odds, ok bool) []interface{} {
+	if !ok {
+		return []interface{}{"", ""}
+	}
+	return []interface{}{best.Price, money(best.Size)}
+}
+
+func money(d types.Decimal) string {
+	return d.StringFixed(types.MoneyPlaces)
+}
@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/risk"
+	"github.com/guysports/go-betfair-api/pkg/types"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type (
+	Orders struct {
+		List    ListOrders    `cmd:"" help:"List current orders"`
+		Place   PlaceOrder    `cmd:"" help:"Place a limit order"`
+		Cancel  CancelOrders  `cmd:"" help:"Cancel unmatched orders"`
+		Replace ReplaceOrders `cmd:"" help:"Move an unmatched order to a new price"`
+	}
+
+	ListOrders struct {
+		MarketId string `help:"Only orders on this market"`
+		Strategy string `help:"Only orders with this customer strategy ref"`
+	}
+
+	PlaceOrder struct {
+		MarketId         string        `help:"Market to bet on" required:""`
+		SelectionId      int           `help:"Selection to bet on" required:""`
+		Handicap         types.Decimal `help:"Handicap of the selection"`
+		Side             string        `help:"BACK or LAY" enum:"BACK,LAY" default:"BACK"`
+		Price            types.Decimal `help:"Limit price" required:""`
+		Size             types.Decimal `help:"Stake" required:""`
+		Persistence      string        `help:"What happens to the unmatched order in play: LAPSE, PERSIST or MARKET_ON_CLOSE" enum:"LAPSE,PERSIST,MARKET_ON_CLOSE" default:"LAPSE"`
+		CustomerRef      string        `help:"Reference to de-duplicate the request"`
+		CustomerOrder    string        `name:"order-ref" help:"Customer order ref"`
+		CustomerStrategy string        `name:"strategy-ref" help:"Customer strategy ref"`
+		RiskFlags
+	}
+
+	CancelOrders struct {
+		MarketId      string        `help:"Market to cancel orders on"`
+		BetId         string        `help:"Bet to cancel, every bet on the market when empty"`
+		SizeReduction types.Decimal `help:"Cancel only this much of the bet"`
+		All           bool          `help:"Cancel every unmatched order on every market"`
+	}
+
+	ReplaceOrders struct {
+		MarketId string        `help:"Market of the bet" required:""`
+		BetId    string        `help:"Bet to move" required:""`
+		NewPrice types.Decimal `help:"Price to move the unmatched stake to" required:""`
+		RiskFlags
+	}
+
+	// RiskFlags are the limits orders are checked against before they are
+	// sent. Zero disables a limit
+	RiskFlags struct {
+		MaxOrderStake  types.Decimal `help:"Refuse an order staking more than this" env:"BETFAIR_MAX_ORDER_STAKE" group:"risk"`
+		DailyLossLimit types.Decimal `help:"Refuse orders once the day's settled loss reaches this" env:"BETFAIR_DAILY_LOSS_LIMIT" group:"risk"`
+	}
+
+	Cleared struct {
+		BetStatus    string    `help:"SETTLED, VOIDED, LAPSED or CANCELLED" enum:"SETTLED,VOIDED,LAPSED,CANCELLED" default:"SETTLED"`
+		EventTypeIds []string  `help:"Only these event types"`
+		EventIds     []string  `help:"Only these events"`
+		MarketIds    []string  `help:"Only these markets"`
+		BetIds       []string  `help:"Only these bets"`
+		Side         string    `help:"Only BACK or LAY bets" enum:"BACK,LAY," default:""`
+		From         time.Time `help:"Settled at or after this RFC3339 time"`
+		To           time.Time `help:"Settled before this RFC3339 time"`
+		GroupBy      string    `help:"Roll up to EVENT_TYPE, EVENT, MARKET, SIDE or BET"`
+		RecordCount  int       `help:"Most orders to list, up to 1000"`
+	}
+)
+
+var (
+	ErrCancelScope = errors.New("give a --market-id to cancel or --all to cancel every order")
+	// ErrNoReport is returned when the exchange answers without an execution
+	// report, so the outcome is unknown
+	ErrNoReport = errors.New("the exchange returned no execution report")
+)
+
+func (l *ListOrders) Run(globals *Globals) error {
+	api, cancel, err := globals.Connect()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	current, err := api.ListCurrentOrders()
+	if err != nil {
+		return err
+	}
+	listed := &types.CurrentOrdersWrapper{Orders: []types.CurrentOrder{}}
+	var rows []table.Row
+	if current != nil {
+		listed.MoreAvailable = current.MoreAvailable
+		for _, order := range current.Orders {
+			if (l.MarketId != "" && order.MarketId != l.MarketId) || (l.Strategy != "" && order.CustomerStrategyRef != l.Strategy) {
+				continue
+			}
+			listed.Orders = append(listed.Orders, order)
+			rows = append(rows, table.Row{
+				order.BetId, order.MarketId, order.SelectionId, order.Side, order.PriceSize.Price, money(order.PriceSize.Size),
+				money(order.SizeMatched), money(order.SizeRemaining), order.Status, order.CustomerStrategyRef,
+			})
+		}
+	}
+	return globals.print(&Result{
+		Data:    listed,
+		Header:  []string{"Bet ID", "Market ID", "Selection", "Side", "Price", "Size", "Matched", "Remaining", "Status", "Strategy"},
+		Rows:    rows,
+		Records: listed.Orders,
+	})
+}
+
+func (r *RiskFlags) Limits() risk.Limits {
+	return risk.Limits{
+		MaxOrderStake:  r.MaxOrderStake,
+		DailyLossLimit: r.DailyLossLimit,
+	}
+}
+
+func (p *PlaceOrder) Run(globals *Globals) error {
+	api, cancel, err := globals.ConnectGuarded(p.Limits())
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	report, err := api.PlaceOrders(&types.PlaceInstructionParams{
+		MarketID:            p.MarketId,
+		CustomerRef:         p.CustomerRef,
+		CustomerStrategyRef: p.CustomerStrategy,
+		Instructions: []types.PlaceInstruction{{
+			OrderType:        types.OrderTypeLimit,
+			SelectionId:      p.SelectionId,
+			Handicap:         p.Handicap,
+			Side:             p.Side,
+			CustomerOrderRef: p.CustomerOrder,
+			LimitOrder: &types.LimitOrder{
+				Price:           p.Price,
+				Size:            p.Size,
+				PersistanceType: p.Persistence,
+			},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	var rows []table.Row
+	for _, ir := range report.InstructionReports {
+		rows = append(rows, table.Row{ir.BetId, ir.Status, ir.OrderStatus, money(ir.SizeMatched), ir.AveragePriceMatched, ir.ErrorCode})
+	}
+	err = globals.print(&Result{
+		Data:    report,
+		Header:  []string{"Bet ID", "Status", "Order Status", "Matched", "Average Price", "Error"},
+		Rows:    rows,
+		Records: report.InstructionReports,
+	})
+	if err != nil {
+		return err
+	}
+	return reportError(report.Status, report.ErrorCode)
+}
+
+func (c *CancelOrders) Run(globals *Globals) error {
+	if c.MarketId == "" && !c.All {
+		return ErrCancelScope
+	}
+	params := &types.CancelInstructionParams{MarketID: c.MarketId}
+	if c.BetId != "" {
+		params.Instructions = []types.CancelInstruction{{BetId: c.BetId, SizeReduction: c.SizeReduction}}
+	}
+
+	api, cancel, err := globals.Connect()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	report, err := api.CancelOrders(params)
+	if err != nil {
+		return err
+	}
+	if report == nil {
+		return ErrNoReport
+	}
+	var rows []table.Row
+	for _, ir := range report.InstructionReports {
+		rows = append(rows, table.Row{ir.Instruction.BetId, ir.Status, money(ir.SizeCancelled), ir.ErrorCode})
+	}
+	err = globals.print(&Result{
+		Data:    report,
+		Header:  []string{"Bet ID", "Status", "Cancelled", "Error"},
+		Rows:    rows,
+		Records: report.InstructionReports,
+	})
+	if err != nil {
+		return err
+	}
+	return reportError(report.Status, report.ErrorCode)
+}
+
+func (r *ReplaceOrders) Run(globals *Globals) error {
+	api, cancel, err := globals.ConnectGuarded(r.Limits())
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	report, err := api.ReplaceOrders(&types.ReplaceInstructionParams{
+		MarketID:     r.MarketId,
+		Instructions: []types.ReplaceInstruction{{BetId: r.BetId, NewPrice: r.NewPrice}},
+	})
+	if err != nil {
+		return err
+	}
+	if report == nil {
+		return ErrNoReport
+	}
+	var rows []table.Row
+	for _, ir := range report.InstructionReports {
+		row := table.Row{r.BetId, ir.Status}
+		if ir.PlaceInstructionReport != nil {
+			row = append(row, ir.PlaceInstructionReport.BetId, money(ir.PlaceInstructionReport.SizeMatched))
+		} else {
+			row = append(row, "", "")
+		}
+		rows = append(rows, append(row, ir.ErrorCode))
+	}
+	err = globals.print(&Result{
+		Data:    report,
+		Header:  []string{"Bet ID", "Status", "New Bet ID", "Matched", "Error"},
+		Rows:    rows,
+		Records: report.InstructionReports,
+	})
+	if err != nil {
+		return err
+	}
+	return reportError(report.Status, report.ErrorCode)
+}
+
+func (c *Cleared) Run(globals *Globals) error {
+	api, cancel, err := globals.Connect()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	params := &types.ClearedOrdersParams{
+		BetStatus:    c.BetStatus,
+		EventTypeIds: c.EventTypeIds,
+		EventIds:     c.EventIds,
+		MarketIds:    c.MarketIds,
+		BetIds:       c.BetIds,
+		Side:         c.Side,
+		GroupBy:      c.GroupBy,
+		RecordCount:  c.RecordCount,
+	}
+	if !c.From.IsZero() || !c.To.IsZero() {
+		params.SettledDateRange = &types.TimeRange{}
+		if !c.From.IsZero() {
+			params.SettledDateRange.From = c.From.Format(time.RFC3339)
+		}
+		if !c.To.IsZero() {
+			params.SettledDateRange.To = c.To.Format(time.RFC3339)
+		}
+	}
+	report, err := api.ListClearedOrders(params)
+	if err != nil {
+		return err
+	}
+	if report == nil {
+		report = &types.ClearedOrderSummaryReport{}
+	}
+	var rows []table.Row
+	for _, order := range report.ClearedOrders {
+		rows = append(rows, table.Row{
+			order.BetId, order.MarketId, order.SelectionId, order.Side, order.BetOutcome,
+			order.PriceMatched, money(order.SizeSettled), money(order.Profit), order.SettledDate,
+		})
+	}
+	return globals.print(&Result{
+		Data:    report,
+		Header:  []string{"Bet ID", "Market ID", "Selection", "Side", "Outcome", "Price", "Settled", "Profit", "Settled Date"},
+		Rows:    rows,
+		Records: report.ClearedOrders,
+	})
+}
+
+// reportError turns a failed execution report into an error, so the command exits non-zero
+func reportError(status, errorCode string) error {
+	if status == types.InstructionStatusSuccess || status == "" {
+		return nil
+	}
+	if errorCode == "" {
+		return errors.New(status)
+	}
+	return fmt.Errorf("%s: %s", status, errorCode)
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	OutputTable  = "table"
+	OutputJSON   = "json"
+	OutputNDJSON = "ndjson"
+	OutputCSV    = "csv"
+	OutputYAML   = "yaml"
+)
+
+var (
+	ErrUnknownColumn = errors.New("unknown column")
+	ErrUnknownOutput = errors.New("unknown output format")
+)
+
+type (
+	// Result is what a command prints. Data is the typed API result, written
+	// whole as json or yaml. Header and Rows flatten it for table and csv
+	Result struct {
+		Data   interface{}
+		Header []string
+		Rows   []table.Row
+		// Records are the items ndjson writes one per line. When nil a slice
+		// Data is split and anything else is written as a single line
+		Records interface{}
+	}
+)
+
+// print writes the result in the chosen output format
+func (g *Globals) print(result *Result) error {
+	switch g.Output {
+	case OutputTable, "":
+		header, rows, err := result.columns(g.Columns)
+		if err != nil {
+			return err
+		}
+		tw := table.NewWriter()
+		tw.SetOutputMirror(g.out())
+		tw.AppendHeader(header)
+		tw.AppendRows(rows)
+		tw.Render()
+		return nil
+	case OutputCSV:
+		header, rows, err := result.columns(g.Columns)
+		if err != nil {
+			return err
+		}
+		w := csv.NewWriter(g.out())
+		record := make([]string, len(header))
+		for i, name := range header {
+			record[i] = fmt.Sprint(name)
+		}
+		_ = w.Write(record)
+		for _, row := range rows {
+			for i, value := range row {
+				record[i] = fmt.Sprint(value)
+			}
+			_ = w.Write(record)
+		}
+		w.Flush()
+		return w.Error()
+	case OutputJSON:
+		enc := json.NewEncoder(g.out())
+		enc.SetIndent("", "  ")
+		return enc.Encode(result.Data)
+	case OutputNDJSON:
+		enc := json.NewEncoder(g.out())
+		records := result.Records
+		if records == nil {
+			records = result.Data
+		}
+		value := reflect.ValueOf(records)
+		if value.Kind() != reflect.Slice {
+			return enc.Encode(records)
+		}
+		for i := 0; i < value.Len(); i++ {
+			if err := enc.Encode(value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputYAML:
+		buf, err := toYAML(result.Data)
+		if err != nil {
+			return err
+		}
+		_, err = g.out().Write(buf)
+		return err
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownOutput, g.Output)
+}
+
+// columns picks the named columns, in the order given, from the header and rows
+func (r *Result) columns(names []string) (table.Row, []table.Row, error) {
+	indexes := make([]int, 0, len(r.Header))
+	if len(names) == 0 {
+		for i := range r.Header {
+			indexes = append(indexes, i)
+		}
+	}
+	for _, name := range names {
+		index := -1
+		for i, heading := range r.Header {
+			if columnName(heading) == columnName(name) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			available := make([]string, len(r.Header))
+			for i, heading := range r.Header {
+				available[i] = columnName(heading)
+			}
+			return nil, nil, fmt.Errorf("%w: %s, choose from %s", ErrUnknownColumn, name, strings.Join(available, ", "))
+		}
+		indexes = append(indexes, index)
+	}
+
+	header := make(table.Row, len(indexes))
+	for i, index := range indexes {
+		header[i] = r.Header[index]
+	}
+	rows := make([]table.Row, len(r.Rows))
+	for i, row := range r.Rows {
+		rows[i] = make(table.Row, len(indexes))
+		for j, index := range indexes {
+			if index < len(row) {
+				rows[i][j] = row[index]
+			}
+		}
+	}
+	return header, rows, nil
+}
+
+// columnName is the form columns are selected by, "Market ID" is market-id
+func columnName(heading string) string {
+	return strings.ToLower(strings.Join(strings.FieldsFunc(heading, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "-"))
+}
+
+// toYAML goes through JSON so fields keep their JSON names and order and
+// types such as Decimal keep their JSON form
+func toYAML(data interface{}) ([]byte, error) {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	buf = bytes.TrimSpace(buf)
+	var value interface{}
+	switch {
+	case bytes.HasPrefix(buf, []byte("[")):
+		value = &[]yaml.MapSlice{}
+	case bytes.HasPrefix(buf, []byte("{")):
+		value = &yaml.MapSlice{}
+	default:
+		value = new(interface{})
+	}
+	if err := yaml.Unmarshal(buf, value); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(value)
+}
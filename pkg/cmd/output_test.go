@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+func TestGlobals_Print(t *testing.T) {
+	orders := []types.CurrentOrder{
+		{BetId: "1", MarketId: "1.10", PriceSize: types.Price{Price: types.NewPrice(2.5), Size: types.NewMoney(10)}},
+		{BetId: "2", MarketId: "1.10", PriceSize: types.Price{Price: types.NewPrice(3), Size: types.NewMoney(4)}},
+	}
+	result := &Result{
+		Data:    &types.CurrentOrdersWrapper{Orders: orders},
+		Header:  []string{"Bet ID", "Market ID", "Price"},
+		Rows:    []table.Row{{"1", "1.10", orders[0].PriceSize.Price}, {"2", "1.10", orders[1].PriceSize.Price}},
+		Records: orders,
+	}
+
+	tests := []struct {
+		name    string
+		output  string
+		columns []string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "csv",
+			output: OutputCSV,
+			want:   "Bet ID,Market ID,Price\n1,1.10,2.5\n2,1.10,3\n",
+		},
+		{
+			name:    "csv columns",
+			output:  OutputCSV,
+			columns: []string{"price", "BET_ID"},
+			want:    "Price,Bet ID\n2.5,1\n3,2\n",
+		},
+		{
+			name:    "table columns",
+			output:  OutputTable,
+			columns: []string{"bet-id"},
+			want:    "+--------+\n| BET ID |\n+--------+\n| 1      |\n| 2      |\n+--------+\n",
+		},
+		{
+			name:    "unknown column",
+			output:  OutputTable,
+			columns: []string{"odds"},
+			wantErr: ErrUnknownColumn,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			globals := &Globals{Output: tt.output, Columns: tt.columns, Stdout: &out}
+			err := globals.print(result)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGlobals_PrintNDJSON(t *testing.T) {
+	orders := []types.CurrentOrder{{BetId: "1"}, {BetId: "2"}}
+	tests := []struct {
+		name   string
+		result *Result
+		want   []string
+	}{
+		{name: "records", result: &Result{Data: &types.CurrentOrdersWrapper{Orders: orders}, Records: orders}, want: []string{"1", "2"}},
+		{name: "slice data", result: &Result{Data: orders}, want: []string{"1", "2"}},
+		{name: "single value", result: &Result{Data: &orders[0]}, want: []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			globals := &Globals{Output: OutputNDJSON, Stdout: &out}
+			if err := globals.print(tt.result); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			dec := json.NewDecoder(&out)
+			for dec.More() {
+				var order types.CurrentOrder
+				if err := dec.Decode(&order); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, order.BetId)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bet ids %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToYAML(t *testing.T) {
+	got, err := toYAML([]types.Odds{{Price: types.NewPrice(1.5), Size: types.NewMoney(2)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "- price: 1.5\n  size: 2\n"; string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	got, err = toYAML(&types.Detail{ID: "1.10", Name: "Match Odds"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "id: \"1.10\"\nname: Match Odds\n"; string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,43 @@
+package heartbeat
+
+import (
+	"encoding/json"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	heartbeatId = 1
+
+	// Betfair clamps the preferred timeout to this range; zero turns the
+	// heartbeat off altogether
+	MinTimeoutSeconds = 10
+	MaxTimeoutSeconds = 300
+)
+
+type (
+	API struct {
+		Client types.TransportInterface
+	}
+)
+
+func NewAPI(client types.TransportInterface) *API {
+	return &API{
+		Client: client,
+	}
+}
+
+func (a *API) Heartbeat(preferredTimeoutSeconds int) (*types.HeartbeatReport, error) {
+	buf, err := a.Client.Call(heartbeatId, "HeartbeatAPING/v1.0/heartbeat", &types.HeartbeatParams{
+		PreferredTimeoutSeconds: preferredTimeoutSeconds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result types.HeartbeatReport
+	if err := json.Unmarshal(buf, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
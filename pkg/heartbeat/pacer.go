@@ -0,0 +1,143 @@
+package heartbeat
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type (
+	// Pacer keeps the exchange's dead-man's switch alive by heartbeating in the
+	// background. If the process dies, or Stop is called deliberately, the
+	// heartbeats cease and the exchange cancels all unmatched orders once the
+	// timeout elapses
+	Pacer struct {
+		API                     *API
+		PreferredTimeoutSeconds int
+		// Interval between heartbeats, half the timeout when zero
+		Interval time.Duration
+		// OnReport is called with every successful report, e.g. to notice ALL_BETS_CANCELLED
+		OnReport func(report *types.HeartbeatReport)
+		OnError  func(err error)
+
+		mu      sync.Mutex
+		last    *types.HeartbeatReport
+		lastErr error
+		cancel  context.CancelFunc
+		done    chan struct{}
+	}
+)
+
+func NewPacer(api *API, preferredTimeoutSeconds int) *Pacer {
+	if preferredTimeoutSeconds < MinTimeoutSeconds {
+		preferredTimeoutSeconds = MinTimeoutSeconds
+	}
+	if preferredTimeoutSeconds > MaxTimeoutSeconds {
+		preferredTimeoutSeconds = MaxTimeoutSeconds
+	}
+	return &Pacer{
+		API:                     api,
+		PreferredTimeoutSeconds: preferredTimeoutSeconds,
+	}
+}
+
+// Start sends the first heartbeat synchronously so that a failure to arm the
+// switch is reported to the caller, then carries on in the background until
+// ctx is done or Stop is called
+func (p *Pacer) Start(ctx context.Context) error {
+	// Claim the running state before beating so a concurrent Start returns
+	p.mu.Lock()
+	if p.cancel != nil {
+		p.mu.Unlock()
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	p.cancel = cancel
+	p.done = done
+	p.mu.Unlock()
+
+	if err := p.beat(); err != nil {
+		p.mu.Lock()
+		if p.done == done {
+			p.cancel, p.done = nil, nil
+		}
+		p.mu.Unlock()
+		cancel()
+		close(done)
+		return err
+	}
+
+	interval := p.Interval
+	if interval <= 0 {
+		interval = time.Duration(p.PreferredTimeoutSeconds) * time.Second / 2
+	}
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = p.beat()
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop ceases heartbeating without telling the exchange, so it will cancel
+// all unmatched orders when the current timeout expires
+func (p *Pacer) Stop() {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.cancel, p.done = nil, nil
+	p.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Disable stops heartbeating and turns the switch off at the exchange so that
+// open orders are left alone
+func (p *Pacer) Disable() (*types.HeartbeatReport, error) {
+	p.Stop()
+	return p.API.Heartbeat(0)
+}
+
+// LastReport returns the most recent heartbeat report and any error from the
+// most recent attempt
+func (p *Pacer) LastReport() (*types.HeartbeatReport, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.last, p.lastErr
+}
+
+func (p *Pacer) beat() error {
+	report, err := p.API.Heartbeat(p.PreferredTimeoutSeconds)
+
+	p.mu.Lock()
+	p.lastErr = err
+	if err == nil {
+		p.last = report
+	}
+	p.mu.Unlock()
+
+	if err != nil {
+		if p.OnError != nil {
+			p.OnError(err)
+		}
+		return err
+	}
+	if p.OnReport != nil {
+		p.OnReport(report)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package heartbeat
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type fakeTransport struct {
+	mu       sync.Mutex
+	timeouts []int
+	action   string
+	// delay holds up every heartbeat
+	delay time.Duration
+}
+
+func (f *fakeTransport) Authenticate() (*types.Authenticate, error) {
+	return &types.Authenticate{}, nil
+}
+func (f *fakeTransport) SetSessionKey(key string) {}
+func (f *fakeTransport) Do(id int, method string, filter *types.MarketFilter, additionalParams interface{}) ([]byte, error) {
+	return nil, nil
+}
+func (f *fakeTransport) Call(id int, method string, params interface{}) ([]byte, error) {
+	time.Sleep(f.delay)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	timeout := params.(*types.HeartbeatParams).PreferredTimeoutSeconds
+	f.timeouts = append(f.timeouts, timeout)
+	return json.Marshal(types.HeartbeatReport{ActionPerformed: f.action, ActualTimeoutSeconds: timeout})
+}
+
+func (f *fakeTransport) calls() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int(nil), f.timeouts...)
+}
+
+func TestPacer(t *testing.T) {
+	transport := &fakeTransport{action: types.HeartbeatActionNone}
+	pacer := NewPacer(NewAPI(transport), 5)
+	if pacer.PreferredTimeoutSeconds != MinTimeoutSeconds {
+		t.Fatalf("PreferredTimeoutSeconds = %d, want %d", pacer.PreferredTimeoutSeconds, MinTimeoutSeconds)
+	}
+	pacer.Interval = 5 * time.Millisecond
+
+	reports := make(chan string, 100)
+	pacer.OnReport = func(report *types.HeartbeatReport) {
+		reports <- report.ActionPerformed
+	}
+	if err := pacer.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	pacer.Stop()
+
+	sent := len(transport.calls())
+	if sent < 2 {
+		t.Fatalf("heartbeats sent = %d, want at least 2", sent)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if len(transport.calls()) != sent {
+		t.Errorf("heartbeats continued after Stop")
+	}
+
+	transport.action = types.HeartbeatActionAllBetsCancelled
+	report, err := pacer.Disable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ActionPerformed != types.HeartbeatActionAllBetsCancelled {
+		t.Errorf("ActionPerformed = %s", report.ActionPerformed)
+	}
+	calls := transport.calls()
+	if calls[len(calls)-1] != 0 {
+		t.Errorf("Disable sent timeout %d, want 0", calls[len(calls)-1])
+	}
+	if last, _ := pacer.LastReport(); last == nil || last.ActualTimeoutSeconds != MinTimeoutSeconds {
+		t.Errorf("LastReport() = %v", last)
+	}
+}
+
+func TestPacer_ConcurrentStart(t *testing.T) {
+	transport := &fakeTransport{action: types.HeartbeatActionNone, delay: 5 * time.Millisecond}
+	pacer := NewPacer(NewAPI(transport), MinTimeoutSeconds)
+	pacer.Interval = 2 * time.Millisecond
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := pacer.Start(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	pacer.Stop()
+
+	sent := len(transport.calls())
+	time.Sleep(30 * time.Millisecond)
+	if len(transport.calls()) != sent {
+		t.Errorf("heartbeats continued after Stop")
+	}
+}
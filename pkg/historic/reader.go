@@ -0,0 +1,190 @@
+package historic
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/stream"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type (
+	// Reader iterates the market change messages in a historic data file. Plain,
+	// .bz2 and .gz files are read directly; tar archives are read entry by
+	// entry with each entry decompressed according to its own extension
+	Reader struct {
+		closer  io.Closer
+		tar     *tar.Reader
+		lines   *bufio.Reader
+		source  string
+		line    int
+		pending *types.MarketChangeMessage
+	}
+)
+
+// Open opens a historic data file or archive
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(path)
+	r := &Reader{closer: f, source: path}
+	if isTar(name) {
+		archive, err := decompress(f, name)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		r.tar = tar.NewReader(archive)
+		return r, nil
+	}
+
+	lines, err := decompress(f, name)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	r.lines = bufio.NewReader(lines)
+	return r, nil
+}
+
+// NewReader reads uncompressed newline delimited messages
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		lines:  bufio.NewReader(r),
+		source: "stream",
+	}
+}
+
+func isTar(name string) bool {
+	for _, ext := range []string{".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tbz2"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func decompress(r io.Reader, name string) (io.Reader, error) {
+	switch {
+	case strings.HasSuffix(name, ".bz2") || strings.HasSuffix(name, ".tbz2"):
+		return bzip2.NewReader(r), nil
+	case strings.HasSuffix(name, ".gz") || strings.HasSuffix(name, ".tgz"):
+		return gzip.NewReader(r)
+	}
+	return r, nil
+}
+
+// Next returns the next message, or io.EOF once every file has been read
+func (r *Reader) Next() (*types.MarketChangeMessage, error) {
+	if r.pending != nil {
+		msg := r.pending
+		r.pending = nil
+		return msg, nil
+	}
+
+	for {
+		if r.lines == nil {
+			if err := r.nextEntry(); err != nil {
+				return nil, err
+			}
+		}
+
+		buf, err := r.lines.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("%s: %w", r.source, err)
+		}
+		if len(bytes.TrimSpace(buf)) > 0 {
+			r.line++
+			var msg types.MarketChangeMessage
+			if jerr := json.Unmarshal(buf, &msg); jerr != nil {
+				return nil, fmt.Errorf("%s line %d: %w", r.source, r.line, jerr)
+			}
+			return &msg, nil
+		}
+		if err == io.EOF {
+			if r.tar == nil {
+				return nil, io.EOF
+			}
+			r.lines = nil
+		}
+	}
+}
+
+func (r *Reader) nextEntry() error {
+	for {
+		header, err := r.tar.Next()
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		entry, err := decompress(r.tar, strings.ToLower(header.Name))
+		if err != nil {
+			return fmt.Errorf("%s: %w", header.Name, err)
+		}
+		r.lines = bufio.NewReader(entry)
+		r.source = filepath.Base(header.Name)
+		r.line = 0
+		return nil
+	}
+}
+
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// ReplayUntil applies every message published at or before until to the
+// cache, leaving the first later message to be returned by the next call to
+// Next. Pass the zero time to replay to the end
+func (r *Reader) ReplayUntil(cache *stream.MarketCache, until time.Time) error {
+	for {
+		msg, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !until.IsZero() && msg.Time().After(until) {
+			r.pending = msg
+			return nil
+		}
+		cache.Apply(msg)
+	}
+}
+
+// SnapshotAt reconstructs a market's book as it stood at the given time
+func SnapshotAt(path string, marketId string, at time.Time) (*types.MarketBookWrapper, error) {
+	r, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	cache := stream.NewMarketCache()
+	if err := r.ReplayUntil(cache, at); err != nil {
+		return nil, err
+	}
+	book := cache.Book(marketId)
+	if book == nil {
+		return nil, fmt.Errorf("market %s not found in %s before %s", marketId, path, at.Format(time.RFC3339))
+	}
+	return book, nil
+}
@@ -0,0 +1,151 @@
+package historic
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	fixture = "testdata/1.100.bz2"
+	// tarFixture holds fixture as PRO/2020/Sep/13/300/1.100.bz2
+	tarFixture = "testdata/1.100.tar.bz2"
+)
+
+func fixtureLines(t *testing.T) []byte {
+	f, err := os.Open(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	buf, err := ioutil.ReadAll(bzip2.NewReader(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func countMessages(t *testing.T, path string) int {
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	count := 0
+	for {
+		_, err := r.Next()
+		if err == io.EOF {
+			return count
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+}
+
+func TestReader_Formats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "historic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	lines := fixtureLines(t)
+
+	plain := filepath.Join(dir, "1.100")
+	if err := ioutil.WriteFile(plain, lines, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	gz := filepath.Join(dir, "1.100.gz")
+	f, _ := os.Create(gz)
+	zw := gzip.NewWriter(f)
+	_, _ = zw.Write(lines)
+	_ = zw.Close()
+	_ = f.Close()
+
+	bz, _ := ioutil.ReadFile(fixture)
+	var tarred bytes.Buffer
+	tw := tar.NewWriter(&tarred)
+	_ = tw.WriteHeader(&tar.Header{Name: "PRO/2020/Sep/13/300/", Typeflag: tar.TypeDir, Mode: 0755})
+	_ = tw.WriteHeader(&tar.Header{Name: "PRO/2020/Sep/13/300/1.100.bz2", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(bz))})
+	_, _ = tw.Write(bz)
+	_ = tw.WriteHeader(&tar.Header{Name: "PRO/2020/Sep/13/300/1.100", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(lines))})
+	_, _ = tw.Write(lines)
+	_ = tw.Close()
+	archive := filepath.Join(dir, "data.tar")
+	if err := ioutil.WriteFile(archive, tarred.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tgz := filepath.Join(dir, "data.tar.gz")
+	f, _ = os.Create(tgz)
+	zw = gzip.NewWriter(f)
+	_, _ = zw.Write(tarred.Bytes())
+	_ = zw.Close()
+	_ = f.Close()
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{name: "bz2", path: fixture, want: 4},
+		{name: "plain", path: plain, want: 4},
+		{name: "gzip", path: gz, want: 4},
+		{name: "tar", path: archive, want: 8},
+		{name: "tar.gz", path: tgz, want: 8},
+		{name: "tar.bz2", path: tarFixture, want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countMessages(t, tt.path); got != tt.want {
+				t.Errorf("messages = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotAt(t *testing.T) {
+	book, err := SnapshotAt(fixture, "1.100", time.Unix(1600000090, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.Status != types.MarketStatusOpen || book.TotalMatched.String() != "30" {
+		t.Errorf("book status %s, matched %s", book.Status, book.TotalMatched)
+	}
+	runner := book.Runners[0]
+	if runner.SelectionID != 11 || runner.LastPriceTraded.String() != "2.52" {
+		t.Fatalf("runner = %+v", runner)
+	}
+	if got := runner.Exchange.AvailableToBack; len(got) != 2 || got[0].Price.String() != "2.5" {
+		t.Errorf("AvailableToBack = %v", got)
+	}
+	if got := runner.Exchange.AvailableToLay; len(got) != 1 || got[0].Price.String() != "2.54" || got[0].Size.String() != "12" {
+		t.Errorf("AvailableToLay = %v", got)
+	}
+
+	book, err = SnapshotAt(fixture, "1.100", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.Status != types.MarketStatusSuspended || !book.Inplay {
+		t.Errorf("final status %s, inplay %v", book.Status, book.Inplay)
+	}
+	if sp := book.Runners[1].StartingPrices; sp == nil || sp.ActualSP.String() != "2.9" || sp.NearPrice != 0 || sp.FarPrice.String() != "2.8" {
+		t.Errorf("StartingPrices = %+v", sp)
+	}
+	// "Infinity" reads as no projection
+	if sp := book.Runners[0].StartingPrices; sp == nil || sp.NearPrice.String() != "2.6" || sp.FarPrice != 0 {
+		t.Errorf("StartingPrices = %+v", sp)
+	}
+}
@@ -0,0 +1,196 @@
+package historic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/guysports/go-betfair-api/pkg/transport"
+	"github.com/guysports/go-betfair-api/pkg/types"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+const (
+	DefaultServiceURL = "https://historicdata.betfair.com/api/"
+
+	partialSuffix = ".part"
+)
+
+type (
+	// Service is a client for the historic data service that lists and
+	// downloads purchased data
+	Service struct {
+		BaseURL      string
+		SessionToken string
+		Client       *retryablehttp.Client
+	}
+)
+
+func NewService(sessionToken string) *Service {
+	client := retryablehttp.NewClient()
+	client.Logger = nil
+	return &Service{
+		BaseURL:      DefaultServiceURL,
+		SessionToken: sessionToken,
+		Client:       client,
+	}
+}
+
+// NewServiceFromClient reuses the session token of an authenticated JSON-RPC client
+func NewServiceFromClient(client *transport.JsonRPCClient) *Service {
+	return NewService(client.AuthData.SessionToken)
+}
+
+// ListDataPackages returns the packages purchased by the account
+func (s *Service) ListDataPackages() ([]types.DataPackage, error) {
+	var result []types.DataPackage
+	if err := s.do(http.MethodPost, "GetMyData", struct{}{}, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Service) GetCollectionOptions(filter *types.HistoricFilter) (*types.CollectionOptions, error) {
+	var result types.CollectionOptions
+	if err := s.do(http.MethodPost, "GetCollectionOptions", filter, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *Service) GetAdvBasketDataSize(filter *types.HistoricFilter) (*types.BasketDataSize, error) {
+	var result types.BasketDataSize
+	if err := s.do(http.MethodPost, "GetAdvBasketDataSize", filter, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DownloadListOfFiles returns the service paths of the files matching filter
+func (s *Service) DownloadListOfFiles(filter *types.HistoricFilter) ([]string, error) {
+	var result []string
+	if err := s.do(http.MethodPost, "DownloadListOfFiles", filter, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DownloadFile saves a file to dest. The download is written to dest.part and
+// renamed when complete, so an interrupted download resumes from where it
+// stopped on the next call and a completed one is not fetched again
+func (s *Service) DownloadFile(filePath, dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	partial := dest + partialSuffix
+	var offset int64
+	if info, err := os.Stat(partial); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := retryablehttp.NewRequest(http.MethodGet, s.endpoint("DownloadFile")+"?filePath="+url.QueryEscape(filePath), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("ssoid", s.SessionToken)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The server ignored the range, start again
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Everything was already downloaded
+		return os.Rename(partial, dest)
+	default:
+		return fmt.Errorf("unable to download %s with error %s [%d]", filePath, resp.Status, resp.StatusCode)
+	}
+
+	f, err := os.OpenFile(partial, flags, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(partial, dest)
+}
+
+// DownloadFiles downloads every file matching filter beneath dir, keeping the
+// service's directory layout, and returns the local paths
+func (s *Service) DownloadFiles(filter *types.HistoricFilter, dir string) ([]string, error) {
+	files, err := s.DownloadListOfFiles(filter)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		dest := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(file, "/")))
+		if err := s.DownloadFile(file, dest); err != nil {
+			return paths, err
+		}
+		paths = append(paths, dest)
+	}
+	return paths, nil
+}
+
+func (s *Service) endpoint(name string) string {
+	return strings.TrimSuffix(s.BaseURL, "/") + "/" + name
+}
+
+func (s *Service) do(method, name string, body interface{}, result interface{}) error {
+	var payload []byte
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = buf
+	}
+	req, err := retryablehttp.NewRequest(method, s.endpoint(name), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("ssoid", s.SessionToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	buf, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s failed with error %s [%d]: %s", name, resp.Status, resp.StatusCode, strings.TrimSpace(string(buf)))
+	}
+	return json.Unmarshal(buf, result)
+}
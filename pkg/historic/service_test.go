@@ -0,0 +1,100 @@
+package historic
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+func TestService(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("ssoid") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/DownloadFile" && r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/GetMyData":
+			_, _ = w.Write([]byte(`[{"sport":"Horse Racing","plan":"Pro Plan","forDate":"2020-09-01T00:00:00","purchaseItemId":7}]`))
+		case "/api/DownloadListOfFiles":
+			var filter types.HistoricFilter
+			_ = json.NewDecoder(r.Body).Decode(&filter)
+			if filter.Plan != "Pro Plan" || filter.FromYear != 2020 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`["/xds_nfs/edp_processed/PRO/2020/Sep/13/300/1.100.bz2"]`))
+		case "/api/DownloadFile":
+			ranges = append(ranges, r.Header.Get("Range"))
+			http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	service := NewService("token")
+	service.BaseURL = server.URL + "/api/"
+
+	packages, err := service.ListDataPackages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packages) != 1 || packages[0].PurchaseItemId != 7 {
+		t.Errorf("ListDataPackages() = %v", packages)
+	}
+
+	dir, err := ioutil.TempDir("", "historic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Leave a partial download behind as if the last run was interrupted
+	dest := filepath.Join(dir, "xds_nfs", "edp_processed", "PRO", "2020", "Sep", "13", "300", "1.100.bz2")
+	_ = os.MkdirAll(filepath.Dir(dest), 0755)
+	_ = ioutil.WriteFile(dest+partialSuffix, content[:400], 0644)
+
+	filter := types.HistoricFilter{Sport: "Horse Racing", Plan: "Pro Plan", FromYear: 2020, ToYear: 2020}
+	paths, err := service.DownloadFiles(&filter, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(paths, []string{dest}) {
+		t.Errorf("DownloadFiles() = %v", paths)
+	}
+	got, _ := ioutil.ReadFile(dest)
+	if !bytes.Equal(got, content) {
+		t.Errorf("downloaded %d bytes, want %d", len(got), len(content))
+	}
+	if !reflect.DeepEqual(ranges, []string{"bytes=400-"}) {
+		t.Errorf("Range headers = %v", ranges)
+	}
+
+	// A completed file is not fetched again
+	if _, err := service.DownloadFiles(&filter, dir); err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 1 {
+		t.Errorf("completed file was downloaded again")
+	}
+
+	service.SessionToken = "expired"
+	if _, err := service.ListDataPackages(); err == nil {
+		t.Errorf("expected an error for a bad session token")
+	}
+}
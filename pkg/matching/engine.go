@@ -0,0 +1,673 @@
+package matching
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+var (
+	ErrMarketNotOpen   = errors.New("market is not open")
+	ErrRunnerNotActive = errors.New("runner is not active")
+	ErrUnknownOrder    = errors.New("unknown bet id")
+	ErrOrderComplete   = errors.New("order is already complete")
+	ErrInvalidSide     = errors.New("side must be BACK or LAY")
+	ErrInvalidOrder    = errors.New("order does not match the order type")
+	ErrInvalidOdds     = errors.New("price is not on the market's price ladder")
+	ErrInvalidBetSize  = errors.New("stake is below the minimum or has more than two decimal places")
+)
+
+type (
+	Match struct {
+		Price types.Decimal
+		Size  types.Decimal
+		At    time.Time
+	}
+
+	// Order is a simulated bet. For LIMIT orders Size is the stake; for
+	// MARKET_ON_CLOSE and LIMIT_ON_CLOSE orders it is the liability until the
+	// starting price is known
+	Order struct {
+		BetId               string
+		MarketId            string
+		SelectionId         int
+		Handicap            types.Decimal
+		Side                string
+		OrderType           string
+		PersistenceType     string
+		Price               types.Decimal
+		Size                types.Decimal
+		CustomerOrderRef    string
+		CustomerStrategyRef string
+		Status              string
+		PlacedAt            time.Time
+		ActiveAt            time.Time
+		SizeMatched         types.Decimal
+		AveragePriceMatched types.Decimal
+		SizeRemaining       types.Decimal
+		SizeLapsed          types.Decimal
+		SizeCancelled       types.Decimal
+		SizeVoided          types.Decimal
+		Matches             []Match
+
+		// QueueAhead is the volume at our price that must trade before we do
+		QueueAhead types.Decimal
+		// AwaitingSP is set once an order can only be matched at the starting price
+		AwaitingSP bool
+	}
+
+	Settlement struct {
+		Order  *Order
+		Profit types.Decimal
+	}
+
+	// Engine matches simulated orders against a sequence of market books. It
+	// takes liquidity from the opposite side of the book on placement, queues
+	// the remainder behind the volume already waiting at the same price and
+	// fills it from subsequent traded volume. Liquidity once taken is not
+	// offered again, since historic books never show it gone. In-play orders wait out the bet
+	// delay, LAPSE orders lapse when the market is suspended or turns in-play
+	// and BSP orders are matched when the starting price is reconciled
+	Engine struct {
+		// Currency sets the minimum stakes orders must meet, GBP when empty
+		Currency string
+
+		mu       sync.Mutex
+		nextId   int64
+		orders   map[string]*Order
+		byMarket map[string][]*Order
+		markets  map[string]*marketState
+		ladders  map[string]types.PriceLadder
+	}
+
+	marketState struct {
+		book   *types.MarketBookWrapper
+		traded map[runnerKey]map[types.Decimal]types.Decimal
+		// consumed is the liquidity our orders have taken from each level.
+		// Historic books never show it gone, so it is carried across updates
+		// and only given back as the level shrinks
+		consumed   map[liquidityKey]types.Decimal
+		levelSizes map[liquidityKey]types.Decimal
+		inPlay     bool
+	}
+
+	runnerKey struct {
+		selectionId int
+		handicap    types.Decimal
+	}
+
+	liquidityKey struct {
+		runner runnerKey
+		side   string
+		price  types.Decimal
+	}
+)
+
+func NewEngine() *Engine {
+	return &Engine{
+		nextId:   1,
+		orders:   map[string]*Order{},
+		byMarket: map[string][]*Order{},
+		markets:  map[string]*marketState{},
+		ladders:  map[string]types.PriceLadder{},
+	}
+}
+
+// SetLadder sets the price ladder orders on a market are checked against.
+// Markets default to the CLASSIC ladder
+func (e *Engine) SetLadder(marketId string, ladder types.PriceLadder) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ladders[marketId] = ladder
+}
+
+// Place simulates placeOrders against the latest book for the market
+func (e *Engine) Place(params *types.PlaceInstructionParams, book *types.MarketBookWrapper, at time.Time) *types.PlaceExecutionReport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	report := &types.PlaceExecutionReport{
+		Status:      types.InstructionStatusSuccess,
+		CustomerRef: params.CustomerRef,
+		MarketID:    params.MarketID,
+	}
+	market := e.market(book)
+	for _, instruction := range params.Instructions {
+		ir := types.PlaceInstructionReport{
+			Instruction:      instruction,
+			CustomerOrderRef: instruction.CustomerOrderRef,
+			OrderType:        instruction.OrderType,
+			Side:             instruction.Side,
+		}
+		order, err := e.place(market, params, &instruction, at)
+		if err != nil {
+			ir.Status = types.InstructionStatusFailure
+			ir.ErrorCode = errorCode(err)
+			report.Status = types.InstructionStatusFailure
+			report.ErrorCode = "BET_ACTION_ERROR"
+		} else {
+			ir.Status = types.InstructionStatusSuccess
+			ir.BetId = order.BetId
+			ir.PlacedDate = at.UTC().Format(time.RFC3339Nano)
+			ir.OrderStatus = order.Status
+			ir.SizeMatched = order.SizeMatched
+			ir.AveragePriceMatched = order.AveragePriceMatched
+		}
+		report.InstructionReports = append(report.InstructionReports, ir)
+	}
+	if len(report.InstructionReports) == 1 {
+		ir := report.InstructionReports[0]
+		report.BetId = ir.BetId
+		report.PlacedDate = ir.PlacedDate
+		report.OrderStatus = ir.OrderStatus
+		report.SizeMatched = ir.SizeMatched
+		report.AveragePriceMatched = ir.AveragePriceMatched
+	}
+	return report
+}
+
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrMarketNotOpen):
+		return "MARKET_NOT_OPEN_FOR_BETTING"
+	case errors.Is(err, ErrRunnerNotActive):
+		return "RUNNER_REMOVED"
+	case errors.Is(err, ErrInvalidOdds):
+		return "INVALID_ODDS"
+	case errors.Is(err, ErrInvalidBetSize):
+		return "INVALID_BET_SIZE"
+	case errors.Is(err, ErrInvalidSide):
+		return "INVALID_BID_TYPE"
+	case errors.Is(err, ErrInvalidOrder):
+		return "INVALID_ORDER_TYPE"
+	}
+	return "ERROR_IN_ORDER"
+}
+
+// check applies the exchange's price ladder and minimum stake rules, so the
+// simulation refuses the orders the exchange would
+func (e *Engine) check(marketId string, instruction *types.PlaceInstruction) error {
+	if instruction.Side != types.SideBack && instruction.Side != types.SideLay {
+		return fmt.Errorf("%w: %q", ErrInvalidSide, instruction.Side)
+	}
+	rules := types.RulesForCurrency(e.Currency)
+	ladder, ok := e.ladders[marketId]
+	if !ok {
+		ladder = types.ClassicLadder
+	}
+	switch instruction.OrderType {
+	case types.OrderTypeLimit:
+		order := instruction.LimitOrder
+		if order == nil {
+			return ErrInvalidOrder
+		}
+		if !ladder.IsValid(order.Price) {
+			return fmt.Errorf("%w: %s", ErrInvalidOdds, order.Price)
+		}
+		if order.Size != order.Size.Truncate(types.MoneyPlaces) || !rules.MeetsMinimumStake(order.Size, order.Price) {
+			return fmt.Errorf("%w: %s", ErrInvalidBetSize, order.Size)
+		}
+	case types.OrderTypeLimitOnClose:
+		order := instruction.LimitOnCloseOrder
+		if order == nil {
+			return ErrInvalidOrder
+		}
+		if !ladder.IsValid(order.Price) {
+			return fmt.Errorf("%w: %s", ErrInvalidOdds, order.Price)
+		}
+		if order.Liability < rules.MinBSP(instruction.Side) {
+			return fmt.Errorf("%w: %s", ErrInvalidBetSize, order.Liability)
+		}
+	case types.OrderTypeMarketOnClose:
+		order := instruction.MarketOnCloseOrder
+		if order == nil {
+			return ErrInvalidOrder
+		}
+		if order.Liability < rules.MinBSP(instruction.Side) {
+			return fmt.Errorf("%w: %s", ErrInvalidBetSize, order.Liability)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidOrder, instruction.OrderType)
+	}
+	return nil
+}
+
+func (e *Engine) place(market *marketState, params *types.PlaceInstructionParams, instruction *types.PlaceInstruction, at time.Time) (*Order, error) {
+	book := market.book
+	if book.Status != types.MarketStatusOpen {
+		return nil, ErrMarketNotOpen
+	}
+	key := runnerKey{selectionId: instruction.SelectionId, handicap: instruction.Handicap}
+	runner := findRunner(book, key)
+	if runner == nil || runner.Status != types.RunnerStatusActive {
+		return nil, ErrRunnerNotActive
+	}
+	if err := e.check(book.MarketId, instruction); err != nil {
+		return nil, err
+	}
+
+	order := &Order{
+		BetId:               strconv.FormatInt(e.nextId, 10),
+		MarketId:            book.MarketId,
+		SelectionId:         instruction.SelectionId,
+		Handicap:            instruction.Handicap,
+		Side:                instruction.Side,
+		OrderType:           instruction.OrderType,
+		CustomerOrderRef:    instruction.CustomerOrderRef,
+		CustomerStrategyRef: params.CustomerStrategyRef,
+		Status:              types.OrderStatusExecutable,
+		PlacedAt:            at,
+		ActiveAt:            at,
+	}
+	switch instruction.OrderType {
+	case types.OrderTypeLimit:
+		order.Price = instruction.LimitOrder.Price
+		order.Size = instruction.LimitOrder.Size
+		order.PersistenceType = instruction.LimitOrder.PersistanceType
+	case types.OrderTypeLimitOnClose:
+		order.Price = instruction.LimitOnCloseOrder.Price
+		order.Size = instruction.LimitOnCloseOrder.Liability
+		order.AwaitingSP = true
+	case types.OrderTypeMarketOnClose:
+		order.Size = instruction.MarketOnCloseOrder.Liability
+		order.AwaitingSP = true
+	}
+	order.SizeRemaining = order.Size
+	e.nextId++
+	e.orders[order.BetId] = order
+	e.byMarket[order.MarketId] = append(e.byMarket[order.MarketId], order)
+
+	if book.Inplay && book.BetDelay > 0 {
+		order.ActiveAt = at.Add(time.Duration(book.BetDelay) * time.Second)
+		order.Status = types.OrderStatusPending
+		return order, nil
+	}
+	if !order.AwaitingSP {
+		e.take(market, order, at)
+		order.QueueAhead = restingSize(runner, order)
+	}
+	return order, nil
+}
+
+// Update advances the simulation to a new book for a market and returns the
+// orders whose state changed
+func (e *Engine) Update(book *types.MarketBookWrapper, at time.Time) []*Order {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	market := e.market(book)
+	previousTraded := market.traded
+	market.traded = tradedVolumes(book)
+	turnedInPlay := book.Inplay && !market.inPlay
+	market.inPlay = book.Inplay
+
+	var changed []*Order
+	for _, order := range e.byMarket[book.MarketId] {
+		if order.Status == types.OrderStatusExecutionComplete {
+			continue
+		}
+		before := *order
+		e.advance(market, order, previousTraded, turnedInPlay, at)
+		if order.Status != before.Status || order.SizeMatched != before.SizeMatched || order.SizeLapsed != before.SizeLapsed || order.AwaitingSP != before.AwaitingSP {
+			changed = append(changed, order)
+		}
+	}
+	return changed
+}
+
+func (e *Engine) advance(market *marketState, order *Order, previousTraded map[runnerKey]map[types.Decimal]types.Decimal, turnedInPlay bool, at time.Time) {
+	book := market.book
+	key := runnerKey{selectionId: order.SelectionId, handicap: order.Handicap}
+	runner := findRunner(book, key)
+
+	if runner == nil || runner.Status == types.RunnerStatusRemoved {
+		order.SizeVoided = order.SizeVoided.Add(order.SizeRemaining)
+		order.SizeRemaining = 0
+		order.Status = types.OrderStatusExecutionComplete
+		return
+	}
+
+	if order.AwaitingSP {
+		e.matchAtSP(order, runner, book, at)
+		return
+	}
+
+	if order.Status == types.OrderStatusPending {
+		if at.Before(order.ActiveAt) {
+			return
+		}
+		// The bet delay has passed; a suspension in the meantime lapses the bet
+		if book.Status != types.MarketStatusOpen {
+			e.lapse(order)
+			return
+		}
+		order.Status = types.OrderStatusExecutable
+		e.take(market, order, at)
+		order.QueueAhead = restingSize(runner, order)
+		return
+	}
+
+	if book.Status == types.MarketStatusSuspended || book.Status == types.MarketStatusClosed || turnedInPlay {
+		switch order.PersistenceType {
+		case types.PersistencePersist:
+			if book.Status == types.MarketStatusClosed {
+				e.lapse(order)
+			}
+			return
+		case types.PersistenceMarketOnClose:
+			if turnedInPlay || book.Status == types.MarketStatusClosed {
+				order.AwaitingSP = true
+				e.matchAtSP(order, runner, book, at)
+			}
+			return
+		}
+		e.lapse(order)
+		return
+	}
+	if book.Status != types.MarketStatusOpen {
+		return
+	}
+
+	// Volume traded at our price works through the queue ahead before us
+	if previous, ok := previousTraded[key]; ok {
+		traded := market.traded[key][order.Price].Sub(previous[order.Price])
+		if traded > 0 {
+			if traded <= order.QueueAhead {
+				order.QueueAhead = order.QueueAhead.Sub(traded)
+			} else {
+				fill := traded.Sub(order.QueueAhead)
+				order.QueueAhead = 0
+				e.fill(order, order.Price, fill, at)
+			}
+		}
+	}
+	if order.SizeRemaining > 0 {
+		e.take(market, order, at)
+	}
+	// Anything cancelled ahead of us moves us up the queue
+	if resting := restingSize(runner, order); resting < order.QueueAhead {
+		order.QueueAhead = resting
+	}
+}
+
+// take matches an order against the opposite side of the book at our price or better
+func (e *Engine) take(market *marketState, order *Order, at time.Time) {
+	runner := findRunner(market.book, runnerKey{selectionId: order.SelectionId, handicap: order.Handicap})
+	if runner == nil {
+		return
+	}
+	levels := runner.Exchange.AvailableToBack
+	crosses := func(price types.Decimal) bool { return price >= order.Price }
+	if order.Side == types.SideLay {
+		levels = runner.Exchange.AvailableToLay
+		crosses = func(price types.Decimal) bool { return price <= order.Price }
+	}
+	for _, level := range levels {
+		if order.SizeRemaining <= 0 || !crosses(level.Price) {
+			break
+		}
+		key := liquidityKey{runner: runnerKey{order.SelectionId, order.Handicap}, side: order.Side, price: level.Price}
+		available := level.Size.Sub(market.consumed[key])
+		if available <= 0 {
+			continue
+		}
+		size := available
+		if order.SizeRemaining < size {
+			size = order.SizeRemaining
+		}
+		market.consumed[key] = market.consumed[key].Add(size)
+		market.levelSizes[key] = level.Size
+		e.fill(order, level.Price, size, at)
+	}
+}
+
+func (e *Engine) matchAtSP(order *Order, runner *types.Runner, book *types.MarketBookWrapper, at time.Time) {
+	if runner.StartingPrices == nil || runner.StartingPrices.ActualSP <= 0 {
+		if book.Status == types.MarketStatusClosed {
+			e.lapse(order)
+		}
+		return
+	}
+	sp := runner.StartingPrices.ActualSP
+	one := types.NewDecimalFromInt(1)
+
+	// A limit price on a BSP order is the worst SP the customer accepts
+	if order.OrderType == types.OrderTypeLimitOnClose {
+		if (order.Side == types.SideBack && sp < order.Price) || (order.Side == types.SideLay && sp > order.Price) {
+			e.lapse(order)
+			return
+		}
+	}
+
+	stake := order.SizeRemaining
+	if order.OrderType != types.OrderTypeLimit && order.Side == types.SideLay && sp > one {
+		stake = order.SizeRemaining.Div(sp.Sub(one)).Round(types.MoneyPlaces)
+	}
+	order.SizeRemaining = 0
+	order.Status = types.OrderStatusExecutionComplete
+	order.AwaitingSP = false
+	order.Matches = append(order.Matches, Match{Price: sp, Size: stake, At: at})
+	order.SizeMatched = order.SizeMatched.Add(stake)
+	order.AveragePriceMatched = averagePrice(order.Matches)
+}
+
+func (e *Engine) fill(order *Order, price, size types.Decimal, at time.Time) {
+	if size > order.SizeRemaining {
+		size = order.SizeRemaining
+	}
+	if size <= 0 {
+		return
+	}
+	order.Matches = append(order.Matches, Match{Price: price, Size: size, At: at})
+	order.SizeMatched = order.SizeMatched.Add(size)
+	order.SizeRemaining = order.SizeRemaining.Sub(size)
+	order.AveragePriceMatched = averagePrice(order.Matches)
+	if order.SizeRemaining == 0 {
+		order.Status = types.OrderStatusExecutionComplete
+	}
+}
+
+func (e *Engine) lapse(order *Order) {
+	order.SizeLapsed = order.SizeLapsed.Add(order.SizeRemaining)
+	order.SizeRemaining = 0
+	order.AwaitingSP = false
+	order.Status = types.OrderStatusExecutionComplete
+}
+
+// Cancel reduces an unmatched order by sizeReduction, or cancels all of the
+// unmatched remainder when sizeReduction is zero. It returns the size cancelled
+func (e *Engine) Cancel(betId string, sizeReduction types.Decimal) (types.Decimal, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	order, ok := e.orders[betId]
+	if !ok {
+		return 0, ErrUnknownOrder
+	}
+	if order.Status == types.OrderStatusExecutionComplete {
+		return 0, ErrOrderComplete
+	}
+	cancelled := order.SizeRemaining
+	if sizeReduction > 0 && sizeReduction < cancelled {
+		cancelled = sizeReduction
+	}
+	order.SizeRemaining = order.SizeRemaining.Sub(cancelled)
+	order.SizeCancelled = order.SizeCancelled.Add(cancelled)
+	if order.SizeRemaining == 0 {
+		order.Status = types.OrderStatusExecutionComplete
+		order.AwaitingSP = false
+	}
+	return cancelled, nil
+}
+
+// Settle returns the profit or loss of every order in a closed market from
+// the runner statuses in its final book
+func (e *Engine) Settle(book *types.MarketBookWrapper) []Settlement {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var settlements []Settlement
+	for _, order := range e.byMarket[book.MarketId] {
+		if order.SizeRemaining > 0 {
+			e.lapse(order)
+		}
+		runner := findRunner(book, runnerKey{selectionId: order.SelectionId, handicap: order.Handicap})
+		settlements = append(settlements, Settlement{Order: order, Profit: OrderProfit(order, runner)})
+	}
+	return settlements
+}
+
+// OrderProfit is the profit or loss of an order's matched bets given the
+// runner's final status. Removed runners void the bet
+func OrderProfit(order *Order, runner *types.Runner) types.Decimal {
+	if runner == nil || runner.Status == types.RunnerStatusRemoved {
+		return 0
+	}
+	won := runner.Status == types.RunnerStatusWinner || runner.Status == types.RunnerStatusPlaced
+	one := types.NewDecimalFromInt(1)
+	var profit types.Decimal
+	for _, match := range order.Matches {
+		winnings := match.Size.Mul(match.Price.Sub(one))
+		switch {
+		case order.Side == types.SideBack && won:
+			profit = profit.Add(winnings)
+		case order.Side == types.SideBack:
+			profit = profit.Sub(match.Size)
+		case won:
+			profit = profit.Sub(winnings)
+		default:
+			profit = profit.Add(match.Size)
+		}
+	}
+	return profit.Round(types.MoneyPlaces)
+}
+
+func (e *Engine) Order(betId string) *Order {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.orders[betId]
+}
+
+// Orders returns the orders for a market, or every order when marketId is empty
+func (e *Engine) Orders(marketId string) []*Order {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if marketId != "" {
+		return append([]*Order(nil), e.byMarket[marketId]...)
+	}
+	orders := make([]*Order, 0, len(e.orders))
+	for _, order := range e.orders {
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		a, _ := strconv.ParseInt(orders[i].BetId, 10, 64)
+		b, _ := strconv.ParseInt(orders[j].BetId, 10, 64)
+		return a < b
+	})
+	return orders
+}
+
+func (e *Engine) market(book *types.MarketBookWrapper) *marketState {
+	market, ok := e.markets[book.MarketId]
+	if !ok {
+		market = &marketState{
+			traded:     tradedVolumes(book),
+			consumed:   map[liquidityKey]types.Decimal{},
+			levelSizes: map[liquidityKey]types.Decimal{},
+			inPlay:     book.Inplay,
+		}
+		e.markets[book.MarketId] = market
+	}
+	market.book = book
+	market.releaseConsumed()
+	return market
+}
+
+// releaseConsumed gives back the liquidity we took from levels that have
+// shrunk since, as others have taken the same offers, and forgets levels
+// that are gone
+func (m *marketState) releaseConsumed() {
+	for key, consumed := range m.consumed {
+		size := levelSize(m.book, key)
+		if shrunk := m.levelSizes[key].Sub(size); shrunk > 0 {
+			consumed = consumed.Sub(shrunk)
+		}
+		if consumed <= 0 || size <= 0 {
+			delete(m.consumed, key)
+			delete(m.levelSizes, key)
+			continue
+		}
+		m.consumed[key] = consumed
+		m.levelSizes[key] = size
+	}
+}
+
+// levelSize is the size offered at a price on the side an order takes from
+func levelSize(book *types.MarketBookWrapper, key liquidityKey) types.Decimal {
+	runner := findRunner(book, key.runner)
+	if runner == nil {
+		return 0
+	}
+	levels := runner.Exchange.AvailableToBack
+	if key.side == types.SideLay {
+		levels = runner.Exchange.AvailableToLay
+	}
+	for _, level := range levels {
+		if level.Price == key.price {
+			return level.Size
+		}
+	}
+	return 0
+}
+
+func tradedVolumes(book *types.MarketBookWrapper) map[runnerKey]map[types.Decimal]types.Decimal {
+	traded := map[runnerKey]map[types.Decimal]types.Decimal{}
+	for _, runner := range book.Runners {
+		volumes := map[types.Decimal]types.Decimal{}
+		for _, level := range runner.Exchange.TradedVolume {
+			volumes[level.Price] = level.Size
+		}
+		traded[runnerKey{selectionId: runner.SelectionID, handicap: runner.Handicap}] = volumes
+	}
+	return traded
+}
+
+// restingSize is the volume already waiting at the order's price on its own side
+func restingSize(runner *types.Runner, order *Order) types.Decimal {
+	levels := runner.Exchange.AvailableToLay
+	if order.Side == types.SideLay {
+		levels = runner.Exchange.AvailableToBack
+	}
+	for _, level := range levels {
+		if level.Price == order.Price {
+			return level.Size
+		}
+	}
+	return 0
+}
+
+func findRunner(book *types.MarketBookWrapper, key runnerKey) *types.Runner {
+	for i := range book.Runners {
+		if book.Runners[i].SelectionID == key.selectionId && book.Runners[i].Handicap == key.handicap {
+			return &book.Runners[i]
+		}
+	}
+	return nil
+}
+
+func averagePrice(matches []Match) types.Decimal {
+	var size, weighted types.Decimal
+	for _, match := range matches {
+		size = size.Add(match.Size)
+		weighted = weighted.Add(match.Price.Mul(match.Size))
+	}
+	if size == 0 {
+		return 0
+	}
+	return weighted.Div(size).Round(types.PricePlaces)
+}
@@ -0,0 +1,212 @@
+package matching
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+var start = time.Unix(1600000000, 0)
+
+func d(s string) types.Decimal { return types.MustParseDecimal(s) }
+
+func book(status string, inPlay bool, betDelay int, runner types.Runner) *types.MarketBookWrapper {
+	return &types.MarketBookWrapper{
+		MarketId: "1.1",
+		Status:   status,
+		Inplay:   inPlay,
+		BetDelay: betDelay,
+		Runners:  []types.Runner{runner},
+	}
+}
+
+func runner(back, lay, traded []types.Odds) types.Runner {
+	return types.Runner{
+		SelectionID: 1,
+		Status:      types.RunnerStatusActive,
+		Exchange:    types.ExchangePrices{AvailableToBack: back, AvailableToLay: lay, TradedVolume: traded},
+	}
+}
+
+func limit(side, price, size, persistence string) *types.PlaceInstructionParams {
+	return &types.PlaceInstructionParams{
+		MarketID: "1.1",
+		Instructions: []types.PlaceInstruction{{
+			OrderType:   types.OrderTypeLimit,
+			SelectionId: 1,
+			Side:        side,
+			LimitOrder:  &types.LimitOrder{Price: d(price), Size: d(size), PersistanceType: persistence},
+		}},
+	}
+}
+
+func TestEngine_QueuePosition(t *testing.T) {
+	e := NewEngine()
+	// 20 is already waiting to back at 3, so our 10 queues behind it
+	b := book(types.MarketStatusOpen, false, 0, runner([]types.Odds{{Price: d("2.98"), Size: d("50")}}, []types.Odds{{Price: d("3"), Size: d("20")}}, nil))
+	report := e.Place(limit(types.SideBack, "3", "10", types.PersistenceLapse), b, start)
+	order := e.Order(report.BetId)
+	if order.SizeMatched != 0 || order.QueueAhead != d("20") {
+		t.Fatalf("matched %s, queue ahead %s", order.SizeMatched, order.QueueAhead)
+	}
+
+	e.Update(book(types.MarketStatusOpen, false, 0, runner(nil, []types.Odds{{Price: d("3"), Size: d("25")}}, []types.Odds{{Price: d("3"), Size: d("15")}})), start.Add(time.Second))
+	if order.SizeMatched != 0 || order.QueueAhead != d("5") {
+		t.Fatalf("after 15 traded: matched %s, queue ahead %s", order.SizeMatched, order.QueueAhead)
+	}
+
+	e.Update(book(types.MarketStatusOpen, false, 0, runner(nil, []types.Odds{{Price: d("3"), Size: d("5")}}, []types.Odds{{Price: d("3"), Size: d("23")}})), start.Add(2*time.Second))
+	if order.SizeMatched != d("3") || order.Status != types.OrderStatusExecutable {
+		t.Fatalf("after 8 more traded: matched %s, status %s", order.SizeMatched, order.Status)
+	}
+
+	// A layer arrives at 3.05, which is better than our price
+	e.Update(book(types.MarketStatusOpen, false, 0, runner([]types.Odds{{Price: d("3.05"), Size: d("100")}}, nil, []types.Odds{{Price: d("3"), Size: d("23")}})), start.Add(3*time.Second))
+	if order.SizeMatched != d("10") || order.Status != types.OrderStatusExecutionComplete || order.AveragePriceMatched != d("3.04") {
+		t.Errorf("after crossing: matched %s at %s, status %s", order.SizeMatched, order.AveragePriceMatched, order.Status)
+	}
+}
+
+func TestEngine_BetDelayAndLapse(t *testing.T) {
+	e := NewEngine()
+	r := runner([]types.Odds{{Price: d("2"), Size: d("5")}}, []types.Odds{{Price: d("2.02"), Size: d("5")}}, nil)
+	report := e.Place(limit(types.SideBack, "2", "10", types.PersistenceLapse), book(types.MarketStatusOpen, true, 5, r), start)
+	order := e.Order(report.BetId)
+	if order.Status != types.OrderStatusPending || order.SizeMatched != 0 {
+		t.Fatalf("status %s, matched %s", order.Status, order.SizeMatched)
+	}
+
+	e.Update(book(types.MarketStatusOpen, true, 5, r), start.Add(2*time.Second))
+	if order.Status != types.OrderStatusPending {
+		t.Fatalf("order active before the bet delay: %s", order.Status)
+	}
+	e.Update(book(types.MarketStatusOpen, true, 5, r), start.Add(5*time.Second))
+	if order.SizeMatched != d("5") || order.SizeRemaining != d("5") {
+		t.Fatalf("after delay: matched %s, remaining %s", order.SizeMatched, order.SizeRemaining)
+	}
+
+	e.Update(book(types.MarketStatusSuspended, true, 5, r), start.Add(6*time.Second))
+	if order.SizeLapsed != d("5") || order.Status != types.OrderStatusExecutionComplete {
+		t.Errorf("after suspend: lapsed %s, status %s", order.SizeLapsed, order.Status)
+	}
+}
+
+func TestEngine_BSP(t *testing.T) {
+	e := NewEngine()
+	r := runner(nil, nil, nil)
+	params := &types.PlaceInstructionParams{
+		MarketID: "1.1",
+		Instructions: []types.PlaceInstruction{
+			{OrderType: types.OrderTypeMarketOnClose, SelectionId: 1, Side: types.SideLay, MarketOnCloseOrder: &types.MarketOnCloseOrder{Liability: d("20")}},
+			{OrderType: types.OrderTypeLimitOnClose, SelectionId: 1, Side: types.SideBack, LimitOnCloseOrder: &types.LimitOnCloseOrder{Liability: d("10"), Price: d("6")}},
+		},
+	}
+	report := e.Place(params, book(types.MarketStatusOpen, false, 0, r), start)
+	lay, back := e.Order(report.InstructionReports[0].BetId), e.Order(report.InstructionReports[1].BetId)
+
+	r.StartingPrices = &types.StartingPrices{ActualSP: d("5")}
+	r.Status = types.RunnerStatusWinner
+	final := book(types.MarketStatusClosed, true, 0, r)
+	e.Update(final, start.Add(time.Minute))
+	if lay.SizeMatched != d("5") || lay.AveragePriceMatched != d("5") {
+		t.Errorf("MOC lay matched %s at %s", lay.SizeMatched, lay.AveragePriceMatched)
+	}
+	if back.SizeLapsed != d("10") {
+		t.Errorf("LOC back below its limit should lapse, lapsed %s", back.SizeLapsed)
+	}
+
+	settlements := e.Settle(final)
+	if settlements[0].Profit != d("-20") || settlements[1].Profit != 0 {
+		t.Errorf("profits %s, %s", settlements[0].Profit, settlements[1].Profit)
+	}
+}
+
+func TestEngine_RefusesInvalidOrders(t *testing.T) {
+	r := runner(nil, nil, nil)
+	tests := []struct {
+		name        string
+		currency    string
+		ladder      *types.PriceLadder
+		instruction types.PlaceInstruction
+		want        string
+	}{
+		{name: "on the ladder", instruction: limit(types.SideBack, "2.52", "2", types.PersistenceLapse).Instructions[0]},
+		{name: "off the ladder", instruction: limit(types.SideBack, "2.53", "2", types.PersistenceLapse).Instructions[0], want: "INVALID_ODDS"},
+		{name: "above the ladder", instruction: limit(types.SideLay, "1001", "2", types.PersistenceLapse).Instructions[0], want: "INVALID_ODDS"},
+		{name: "finest ladder", ladder: &types.FinestLadder, instruction: limit(types.SideBack, "2.53", "2", types.PersistenceLapse).Instructions[0]},
+		{name: "below minimum stake", instruction: limit(types.SideBack, "2", "0.5", types.PersistenceLapse).Instructions[0], want: "INVALID_BET_SIZE"},
+		{name: "minimum payout", instruction: limit(types.SideBack, "20", "0.5", types.PersistenceLapse).Instructions[0]},
+		{name: "currency minimum", currency: "USD", instruction: limit(types.SideBack, "2", "2", types.PersistenceLapse).Instructions[0], want: "INVALID_BET_SIZE"},
+		{name: "stake precision", instruction: limit(types.SideBack, "2", "2.005", types.PersistenceLapse).Instructions[0], want: "INVALID_BET_SIZE"},
+		{name: "unknown side", instruction: limit("BUY", "2", "2", types.PersistenceLapse).Instructions[0], want: "INVALID_BID_TYPE"},
+		{
+			name:        "BSP liability below minimum",
+			instruction: types.PlaceInstruction{OrderType: types.OrderTypeMarketOnClose, SelectionId: 1, Side: types.SideLay, MarketOnCloseOrder: &types.MarketOnCloseOrder{Liability: d("5")}},
+			want:        "INVALID_BET_SIZE",
+		},
+		{
+			name:        "BSP limit off the ladder",
+			instruction: types.PlaceInstruction{OrderType: types.OrderTypeLimitOnClose, SelectionId: 1, Side: types.SideBack, LimitOnCloseOrder: &types.LimitOnCloseOrder{Liability: d("10"), Price: d("6.1")}},
+			want:        "INVALID_ODDS",
+		},
+		{
+			name:        "missing order",
+			instruction: types.PlaceInstruction{OrderType: types.OrderTypeLimit, SelectionId: 1, Side: types.SideBack},
+			want:        "INVALID_ORDER_TYPE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine()
+			e.Currency = tt.currency
+			if tt.ladder != nil {
+				e.SetLadder("1.1", *tt.ladder)
+			}
+			params := &types.PlaceInstructionParams{MarketID: "1.1", Instructions: []types.PlaceInstruction{tt.instruction}}
+			report := e.Place(params, book(types.MarketStatusOpen, false, 0, r), start)
+			if got := report.InstructionReports[0].ErrorCode; got != tt.want {
+				t.Errorf("ErrorCode = %q, want %q", got, tt.want)
+			}
+			if placed := len(e.Orders("1.1")) == 1; placed != (tt.want == "") {
+				t.Errorf("order placed = %v", placed)
+			}
+		})
+	}
+}
+
+func TestEngine_TakesLiquidityOnce(t *testing.T) {
+	e := NewEngine()
+	offered := func(size string) *types.MarketBookWrapper {
+		return book(types.MarketStatusOpen, false, 0, runner([]types.Odds{{Price: d("3"), Size: d(size)}}, nil, nil))
+	}
+	report := e.Place(limit(types.SideBack, "3", "100", types.PersistenceLapse), offered("40"), start)
+	order := e.Order(report.BetId)
+
+	tests := []struct {
+		name    string
+		offered string
+		want    string
+	}{
+		{name: "placed", offered: "", want: "40"},
+		{name: "same book", offered: "40", want: "40"},
+		{name: "same book again", offered: "40", want: "40"},
+		// Others took 10 of the 40 we already had
+		{name: "level shrinks", offered: "30", want: "40"},
+		{name: "new liquidity", offered: "60", want: "70"},
+	}
+	for i, tt := range tests {
+		if tt.offered != "" {
+			e.Update(offered(tt.offered), start.Add(time.Duration(i)*time.Second))
+		}
+		if order.SizeMatched != d(tt.want) {
+			t.Errorf("%s: matched %s, want %s", tt.name, order.SizeMatched, tt.want)
+		}
+	}
+
+	// A second order sees only what the first has not taken
+	second := e.Order(e.Place(limit(types.SideBack, "3", "100", types.PersistenceLapse), offered("60"), start.Add(time.Minute)).BetId)
+	if second.SizeMatched != 0 {
+		t.Errorf("second order matched %s from taken liquidity", second.SizeMatched)
+	}
+}
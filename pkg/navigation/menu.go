@@ -0,0 +1,170 @@
+package navigation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	MenuURL = "https://api.betfair.com/exchange/betting/rest/v1/en/navigation/menu.json"
+)
+
+var (
+	// SkipChildren can be returned from a WalkFunc to skip a node's children
+	SkipChildren = errors.New("skip children")
+
+	errFound = errors.New("found")
+)
+
+type (
+	// Getter is satisfied by transport.JsonRPCClient
+	Getter interface {
+		Get(url string) ([]byte, error)
+	}
+
+	Client struct {
+		Getter Getter
+		URL    string
+	}
+
+	// WalkFunc is called for every node with the chain of ancestors from the root
+	WalkFunc func(node *types.NavigationNode, ancestors []*types.NavigationNode) error
+)
+
+func NewClient(getter Getter) *Client {
+	return &Client{
+		Getter: getter,
+		URL:    MenuURL,
+	}
+}
+
+// Fetch downloads the whole exchange navigation menu
+func (c *Client) Fetch() (*types.NavigationNode, error) {
+	buf, err := c.Getter.Get(c.URL)
+	if err != nil {
+		return nil, err
+	}
+	var root types.NavigationNode
+	if err := json.Unmarshal(buf, &root); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
+
+func Parse(r io.Reader) (*types.NavigationNode, error) {
+	var root types.NavigationNode
+	if err := json.NewDecoder(r).Decode(&root); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
+
+// Load reads a menu previously saved to disk
+func Load(path string) (*types.NavigationNode, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Save writes a fetched menu to disk so later runs can Load it
+func Save(path string, root *types.NavigationNode) error {
+	buf, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf, 0644)
+}
+
+// Walk visits node and its descendants depth first
+func Walk(node *types.NavigationNode, fn WalkFunc) error {
+	err := walk(node, nil, fn)
+	if err == SkipChildren {
+		return nil
+	}
+	return err
+}
+
+func walk(node *types.NavigationNode, ancestors []*types.NavigationNode, fn WalkFunc) error {
+	if err := fn(node, ancestors); err != nil {
+		return err
+	}
+	path := append(ancestors[:len(ancestors):len(ancestors)], node)
+	for _, child := range node.Children {
+		if err := walk(child, path, fn); err != nil && err != SkipChildren {
+			return err
+		}
+	}
+	return nil
+}
+
+// Markets returns every MARKET node beneath node
+func Markets(node *types.NavigationNode) []*types.NavigationNode {
+	return Filter(node, func(n *types.NavigationNode) bool {
+		return n.Type == types.NavigationMarket
+	})
+}
+
+func Filter(node *types.NavigationNode, match func(*types.NavigationNode) bool) []*types.NavigationNode {
+	var found []*types.NavigationNode
+	_ = Walk(node, func(n *types.NavigationNode, _ []*types.NavigationNode) error {
+		if match(n) {
+			found = append(found, n)
+		}
+		return nil
+	})
+	return found
+}
+
+// FindByName returns nodes whose name matches case-insensitively, restricted
+// to the given node types when any are supplied
+func FindByName(node *types.NavigationNode, name string, nodeTypes ...string) []*types.NavigationNode {
+	return Filter(node, func(n *types.NavigationNode) bool {
+		return strings.EqualFold(n.Name, name) && isType(n, nodeTypes)
+	})
+}
+
+// FindByID returns the first node with the given ID and type
+func FindByID(node *types.NavigationNode, id string, nodeType string) *types.NavigationNode {
+	found := Filter(node, func(n *types.NavigationNode) bool {
+		return string(n.ID) == id && n.Type == nodeType
+	})
+	if len(found) == 0 {
+		return nil
+	}
+	return found[0]
+}
+
+// Path returns the chain of nodes from root down to target, or nil if target
+// is not in the tree
+func Path(root, target *types.NavigationNode) []*types.NavigationNode {
+	var path []*types.NavigationNode
+	_ = Walk(root, func(n *types.NavigationNode, ancestors []*types.NavigationNode) error {
+		if n == target {
+			path = append(append(path, ancestors...), n)
+			return errFound
+		}
+		return nil
+	})
+	return path
+}
+
+func isType(node *types.NavigationNode, nodeTypes []string) bool {
+	if len(nodeTypes) == 0 {
+		return true
+	}
+	for _, t := range nodeTypes {
+		if node.Type == t {
+			return true
+		}
+	}
+	return false
+}
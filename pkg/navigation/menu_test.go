@@ -0,0 +1,71 @@
+package navigation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+func names(nodes []*types.NavigationNode) []string {
+	var result []string
+	for _, node := range nodes {
+		result = append(result, node.Name)
+	}
+	return result
+}
+
+func TestMenu(t *testing.T) {
+	root, err := Load("testdata/menu.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := names(Markets(root)); !reflect.DeepEqual(got, []string{"Match Odds", "Over/Under 2.5 Goals", "Win", "To Be Placed"}) {
+		t.Errorf("Markets(root) = %v", got)
+	}
+
+	racing := FindByName(root, "horse racing", types.NavigationEventType)
+	if len(racing) != 1 {
+		t.Fatalf("FindByName() = %v", names(racing))
+	}
+	if got := names(Markets(racing[0])); !reflect.DeepEqual(got, []string{"Win", "To Be Placed"}) {
+		t.Errorf("Markets(racing) = %v", got)
+	}
+
+	market := FindByID(root, "1.300002", types.NavigationMarket)
+	if market == nil || market.NumberOfWinners != "3" {
+		t.Fatalf("FindByID() = %v", market)
+	}
+	if got := names(Path(root, market)); !reflect.DeepEqual(got, []string{"ROOT", "Horse Racing", "GB", "Ascot 24th Oct", "1m Hcap", "To Be Placed"}) {
+		t.Errorf("Path() = %v", got)
+	}
+
+	var visited []string
+	_ = Walk(root, func(node *types.NavigationNode, _ []*types.NavigationNode) error {
+		visited = append(visited, node.Name)
+		if node.Type == types.NavigationEventType {
+			return SkipChildren
+		}
+		return nil
+	})
+	if !reflect.DeepEqual(visited, []string{"ROOT", "Soccer", "Horse Racing"}) {
+		t.Errorf("Walk() with SkipChildren visited %v", visited)
+	}
+}
+
+type fileGetter string
+
+func (f fileGetter) Get(url string) ([]byte, error) {
+	return []byte(f), nil
+}
+
+func TestClient_Fetch(t *testing.T) {
+	root, err := NewClient(fileGetter(`{"type":"GROUP","id":0,"name":"ROOT","children":[{"type":"EVENT_TYPE","id":"1","name":"Soccer"}]}`)).Fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(root.Children) != 1 || root.Children[0].ID != "1" {
+		t.Errorf("Fetch() = %+v", root)
+	}
+}
@@ -0,0 +1,599 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/risk"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+// Lifecycle statuses beyond the exchange's own PENDING, EXECUTABLE and
+// EXECUTION_COMPLETE. An order that completes without matching ends as
+// CANCELLED, LAPSED or VOIDED; one the exchange rejects ends as FAILED
+const (
+	StatusCancelled = "CANCELLED"
+	StatusLapsed    = "LAPSED"
+	StatusVoided    = "VOIDED"
+	StatusFailed    = "FAILED"
+
+	// DefaultPendingGrace is how long a PENDING order whose placement outcome
+	// is unknown may take to appear in the exchange's order listing
+	DefaultPendingGrace = time.Minute
+)
+
+type (
+	Order struct {
+		CustomerOrderRef    string
+		CustomerStrategyRef string
+		BetId               string
+		MarketId            string
+		SelectionId         int
+		Handicap            types.Decimal
+		Side                string
+		OrderType           string
+		PersistenceType     string
+		Price               types.Decimal
+		Size                types.Decimal
+		Status              string
+		ErrorCode           string
+		PlacedDate          string
+		SizeMatched         types.Decimal
+		AveragePriceMatched types.Decimal
+		SizeRemaining       types.Decimal
+		SizeCancelled       types.Decimal
+		SizeLapsed          types.Decimal
+		SizeVoided          types.Decimal
+		// ReplacedBy and Replaces link the two halves of a replaceOrders
+		ReplacedBy string
+		Replaces   string
+		// Reconciled is set on orders adopted from listCurrentOrders that
+		// were not placed through this manager, e.g. before a restart
+		Reconciled bool
+		UpdatedAt  time.Time
+	}
+
+	// Query selects orders. Zero fields match everything
+	Query struct {
+		MarketId    string
+		SelectionId int
+		Strategy    string
+		OpenOnly    bool
+	}
+
+	// Position is the matched exposure on a runner. IfWin and IfLose are the
+	// profit or loss should the runner win or lose
+	Position struct {
+		MarketId    string
+		SelectionId int
+		Handicap    types.Decimal
+		BackMatched types.Decimal
+		LayMatched  types.Decimal
+		IfWin       types.Decimal
+		IfLose      types.Decimal
+	}
+
+	// Manager places orders through the betting API, assigning each a
+	// customerOrderRef, and follows them through their lifecycle from the
+	// execution reports and listCurrentOrders
+	Manager struct {
+		API betting.APIInterface
+		// Prefix of the generated customerOrderRefs, unique per manager so refs
+		// do not repeat across restarts
+		Prefix string
+		// OnUpdate is called with a copy of every order that changes
+		OnUpdate func(order Order)
+		Now      func() time.Time
+		// PendingGrace is how long a PENDING order may be missing from a
+		// complete snapshot before it is marked FAILED
+		PendingGrace time.Duration
+
+		mu      sync.Mutex
+		next    int
+		orders  []*Order
+		byBetId map[string]*Order
+		byRef   map[string]*Order
+	}
+)
+
+func NewManager(api betting.APIInterface) *Manager {
+	return &Manager{
+		API:          api,
+		Prefix:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		Now:          time.Now,
+		PendingGrace: DefaultPendingGrace,
+		byBetId:      map[string]*Order{},
+		byRef:        map[string]*Order{},
+	}
+}
+
+// IsOpen reports whether the order may still be matched
+func (o *Order) IsOpen() bool {
+	return o.Status == types.OrderStatusPending || o.Status == types.OrderStatusExecutable
+}
+
+// Place assigns customerOrderRefs to instructions without one, records the
+// orders as PENDING and places them. Orders the call definitely did not
+// place, because the guard, validation or the exchange refused them, are
+// FAILED. When the outcome is unknown the orders stay PENDING until
+// Reconcile finds them by ref or PendingGrace passes without them appearing
+func (m *Manager) Place(params *types.PlaceInstructionParams) (*types.PlaceExecutionReport, error) {
+	m.mu.Lock()
+	var placed []*Order
+	for i := range params.Instructions {
+		instruction := &params.Instructions[i]
+		if instruction.CustomerOrderRef == "" {
+			m.next++
+			instruction.CustomerOrderRef = fmt.Sprintf("%s-%d", m.Prefix, m.next)
+		}
+		order := &Order{
+			CustomerOrderRef:    instruction.CustomerOrderRef,
+			CustomerStrategyRef: params.CustomerStrategyRef,
+			MarketId:            params.MarketID,
+			SelectionId:         instruction.SelectionId,
+			Handicap:            instruction.Handicap,
+			Side:                instruction.Side,
+			OrderType:           instruction.OrderType,
+			Status:              types.OrderStatusPending,
+			UpdatedAt:           m.Now(),
+		}
+		switch {
+		case instruction.LimitOrder != nil:
+			order.Price = instruction.LimitOrder.Price
+			order.Size = instruction.LimitOrder.Size
+			order.PersistenceType = instruction.LimitOrder.PersistanceType
+		case instruction.LimitOnCloseOrder != nil:
+			order.Price = instruction.LimitOnCloseOrder.Price
+			order.Size = instruction.LimitOnCloseOrder.Liability
+		case instruction.MarketOnCloseOrder != nil:
+			order.Size = instruction.MarketOnCloseOrder.Liability
+		}
+		order.SizeRemaining = order.Size
+		m.track(order)
+		placed = append(placed, order)
+	}
+	m.mu.Unlock()
+	m.notify(placed...)
+
+	report, err := m.API.PlaceOrders(params)
+	if err != nil || report == nil {
+		if neverPlaced(err) {
+			m.fail(placed, "")
+		}
+		return report, err
+	}
+
+	m.mu.Lock()
+	var changed []*Order
+	reported := map[*Order]bool{}
+	for i, ir := range report.InstructionReports {
+		// Reports come back in instruction order should the ref not be echoed
+		order := m.byRef[ir.Instruction.CustomerOrderRef]
+		if order == nil && i < len(placed) {
+			order = placed[i]
+		}
+		if order == nil {
+			continue
+		}
+		m.applyPlaceReport(order, &ir)
+		reported[order] = true
+		changed = append(changed, order)
+	}
+	m.mu.Unlock()
+	m.notify(changed...)
+
+	// Without an instruction report an order was only placed if the whole
+	// request timed out
+	if report.Status != types.InstructionStatusTimeout {
+		var unreported []*Order
+		for _, order := range placed {
+			if !reported[order] {
+				unreported = append(unreported, order)
+			}
+		}
+		m.fail(unreported, report.ErrorCode)
+	}
+	return report, nil
+}
+
+// neverPlaced reports whether a PlaceOrders error means the orders cannot
+// have reached the book
+func neverPlaced(err error) bool {
+	var validationErr *betting.ValidationError
+	return betting.Rejected(err) ||
+		errors.As(err, &validationErr) ||
+		errors.Is(err, risk.ErrLimitExceeded) ||
+		errors.Is(err, risk.ErrKillSwitch)
+}
+
+// fail marks PENDING orders FAILED
+func (m *Manager) fail(orders []*Order, errorCode string) {
+	m.mu.Lock()
+	var changed []*Order
+	for _, order := range orders {
+		if order.Status != types.OrderStatusPending || order.BetId != "" {
+			continue
+		}
+		order.Status = StatusFailed
+		order.ErrorCode = errorCode
+		order.SizeRemaining = 0
+		order.UpdatedAt = m.Now()
+		changed = append(changed, order)
+	}
+	m.mu.Unlock()
+	m.notify(changed...)
+}
+
+func (m *Manager) applyPlaceReport(order *Order, ir *types.PlaceInstructionReport) {
+	order.UpdatedAt = m.Now()
+	if ir.Status == types.InstructionStatusTimeout {
+		// The order may yet appear, so it stays PENDING for Reconcile
+		return
+	}
+	if ir.Status != types.InstructionStatusSuccess {
+		order.Status = StatusFailed
+		order.ErrorCode = ir.ErrorCode
+		order.SizeRemaining = 0
+		return
+	}
+	order.BetId = ir.BetId
+	order.PlacedDate = ir.PlacedDate
+	order.SizeMatched = ir.SizeMatched
+	order.AveragePriceMatched = ir.AveragePriceMatched
+	if order.OrderType == types.OrderTypeLimit {
+		order.SizeRemaining = order.Size.Sub(ir.SizeMatched)
+	}
+	order.Status = ir.OrderStatus
+	if order.Status == "" {
+		order.Status = types.OrderStatusExecutable
+	}
+	if order.Status == types.OrderStatusExecutionComplete {
+		order.SizeRemaining = 0
+	}
+	if order.BetId != "" {
+		m.byBetId[order.BetId] = order
+	}
+}
+
+func (m *Manager) Cancel(params *types.CancelInstructionParams) (*types.CancelExecutionReport, error) {
+	report, err := m.API.CancelOrders(params)
+	if err != nil || report == nil {
+		return report, err
+	}
+
+	m.mu.Lock()
+	var changed []*Order
+	for _, ir := range report.InstructionReports {
+		order := m.byBetId[ir.Instruction.BetId]
+		if order == nil || ir.Status != types.InstructionStatusSuccess {
+			continue
+		}
+		m.applyCancel(order, ir.SizeCancelled)
+		changed = append(changed, order)
+	}
+	m.mu.Unlock()
+	m.notify(changed...)
+	return report, nil
+}
+
+func (m *Manager) applyCancel(order *Order, cancelled types.Decimal) {
+	order.SizeCancelled = order.SizeCancelled.Add(cancelled)
+	order.SizeRemaining = order.SizeRemaining.Sub(cancelled)
+	if order.SizeRemaining <= 0 {
+		order.SizeRemaining = 0
+		order.Status = completedStatus(order)
+	}
+	order.UpdatedAt = m.Now()
+}
+
+// Replace cancels orders and tracks the replacements placed at the new price
+func (m *Manager) Replace(params *types.ReplaceInstructionParams) (*types.ReplaceExecutionReport, error) {
+	report, err := m.API.ReplaceOrders(params)
+	if err != nil || report == nil {
+		return report, err
+	}
+
+	m.mu.Lock()
+	var changed []*Order
+	for i, ir := range report.InstructionReports {
+		if i >= len(params.Instructions) {
+			break
+		}
+		original := m.byBetId[params.Instructions[i].BetId]
+		if original == nil {
+			continue
+		}
+		if cancel := ir.CancelInstructionReport; cancel != nil && cancel.Status == types.InstructionStatusSuccess {
+			m.applyCancel(original, cancel.SizeCancelled)
+			changed = append(changed, original)
+
+			if place := ir.PlaceInstructionReport; place != nil {
+				replacement := &Order{
+					CustomerStrategyRef: original.CustomerStrategyRef,
+					MarketId:            original.MarketId,
+					SelectionId:         original.SelectionId,
+					Handicap:            original.Handicap,
+					Side:                original.Side,
+					OrderType:           original.OrderType,
+					PersistenceType:     original.PersistenceType,
+					Price:               params.Instructions[i].NewPrice,
+					Size:                cancel.SizeCancelled,
+					SizeRemaining:       cancel.SizeCancelled,
+					Replaces:            original.BetId,
+				}
+				m.track(replacement)
+				m.applyPlaceReport(replacement, place)
+				original.ReplacedBy = replacement.BetId
+				changed = append(changed, replacement)
+			}
+		}
+	}
+	m.mu.Unlock()
+	m.notify(changed...)
+	return report, nil
+}
+
+// Reconcile brings the tracked orders up to date with listCurrentOrders.
+// Orders unknown to the manager, such as those placed before a restart, are
+// adopted, and PENDING orders whose placement failed in transit are matched
+// up by customerOrderRef. A complete listing also finishes the open orders
+// missing from it, as ApplySnapshot does
+func (m *Manager) Reconcile() error {
+	asOf := m.Now()
+	current, err := m.API.ListCurrentOrders()
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return nil
+	}
+	if !current.MoreAvailable {
+		m.ApplySnapshot("", current.Orders, asOf)
+		return nil
+	}
+	for i := range current.Orders {
+		m.ApplyCurrentOrder(&current.Orders[i])
+	}
+	return nil
+}
+
+// ApplySnapshot applies a complete set of current orders, for one market or
+// for the account when marketId is empty, such as a full image from the order
+// stream. Open orders last updated before asOf that the snapshot lacks are
+// finished. Those with a bet ID have completed, any remainder lapsing, and
+// PENDING ones without are FAILED once PendingGrace has passed
+func (m *Manager) ApplySnapshot(marketId string, orders []types.CurrentOrder, asOf time.Time) {
+	listed := map[string]bool{}
+	for i := range orders {
+		m.ApplyCurrentOrder(&orders[i])
+		listed[orders[i].BetId] = true
+	}
+
+	m.mu.Lock()
+	var changed []*Order
+	for _, order := range m.orders {
+		if !order.IsOpen() || listed[order.BetId] || !order.UpdatedAt.Before(asOf) ||
+			(marketId != "" && order.MarketId != marketId) {
+			continue
+		}
+		switch {
+		case order.BetId != "":
+			order.SizeLapsed = order.SizeLapsed.Add(order.SizeRemaining)
+			order.SizeRemaining = 0
+			order.Status = completedStatus(order)
+		case asOf.Sub(order.UpdatedAt) >= m.PendingGrace:
+			order.Status = StatusFailed
+			order.SizeRemaining = 0
+		default:
+			continue
+		}
+		order.UpdatedAt = m.Now()
+		changed = append(changed, order)
+	}
+	m.mu.Unlock()
+	m.notify(changed...)
+}
+
+// ApplyCurrentOrder updates the manager from the exchange's view of an order,
+// as returned by listCurrentOrders or assembled from the order stream
+func (m *Manager) ApplyCurrentOrder(current *types.CurrentOrder) {
+	m.mu.Lock()
+	order := m.byBetId[current.BetId]
+	if order == nil && current.CustomerOrderRef != "" {
+		if pending := m.byRef[current.CustomerOrderRef]; pending != nil && pending.BetId == "" {
+			order = pending
+		}
+	}
+	if order == nil {
+		order = &Order{
+			CustomerOrderRef:    current.CustomerOrderRef,
+			CustomerStrategyRef: current.CustomerStrategyRef,
+			MarketId:            current.MarketId,
+			SelectionId:         current.SelectionId,
+			Handicap:            current.Handicap,
+			Side:                current.Side,
+			OrderType:           current.OrderType,
+			PersistenceType:     current.PersistanceType,
+			Price:               current.PriceSize.Price,
+			Size:                current.PriceSize.Size,
+			Reconciled:          true,
+		}
+		if order.OrderType != types.OrderTypeLimit {
+			order.Size = current.BspLiability
+		}
+		m.track(order)
+	}
+	previous := *order
+
+	order.BetId = current.BetId
+	m.byBetId[order.BetId] = order
+	order.PlacedDate = current.PlacedDate
+	order.SizeMatched = current.SizeMatched
+	order.AveragePriceMatched = current.AveragePriceMatched
+	order.SizeRemaining = current.SizeRemaining
+	order.SizeCancelled = current.SizeCancelled
+	order.SizeLapsed = current.SizeLapsed
+	order.SizeVoided = current.SizeVoided
+	order.Status = current.Status
+	if order.Status == types.OrderStatusExecutionComplete {
+		order.Status = completedStatus(order)
+	}
+	previous.UpdatedAt = order.UpdatedAt
+	changed := previous != *order
+	if changed {
+		order.UpdatedAt = m.Now()
+	}
+	m.mu.Unlock()
+	if changed {
+		m.notify(order)
+	}
+}
+
+// completedStatus distinguishes an order that matched from one that ended
+// without matching
+func completedStatus(order *Order) string {
+	switch {
+	case order.SizeMatched > 0:
+		return types.OrderStatusExecutionComplete
+	case order.SizeVoided > 0:
+		return StatusVoided
+	case order.SizeLapsed > 0:
+		return StatusLapsed
+	case order.SizeCancelled > 0:
+		return StatusCancelled
+	}
+	return types.OrderStatusExecutionComplete
+}
+
+func (m *Manager) track(order *Order) {
+	m.orders = append(m.orders, order)
+	if order.CustomerOrderRef != "" {
+		m.byRef[order.CustomerOrderRef] = order
+	}
+	if order.BetId != "" {
+		m.byBetId[order.BetId] = order
+	}
+}
+
+func (m *Manager) notify(orders ...*Order) {
+	if m.OnUpdate == nil {
+		return
+	}
+	for _, order := range orders {
+		m.mu.Lock()
+		snapshot := *order
+		m.mu.Unlock()
+		m.OnUpdate(snapshot)
+	}
+}
+
+// Order looks an order up by bet ID or customerOrderRef
+func (m *Manager) Order(id string) (Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	order := m.byBetId[id]
+	if order == nil {
+		order = m.byRef[id]
+	}
+	if order == nil {
+		return Order{}, false
+	}
+	return *order, true
+}
+
+// Orders returns copies of the orders matching the query in placement order
+func (m *Manager) Orders(query Query) []Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var orders []Order
+	for _, order := range m.orders {
+		if query.matches(order) {
+			orders = append(orders, *order)
+		}
+	}
+	return orders
+}
+
+func (m *Manager) OpenOrders(marketId string, selectionId int, strategy string) []Order {
+	return m.Orders(Query{MarketId: marketId, SelectionId: selectionId, Strategy: strategy, OpenOnly: true})
+}
+
+func (q *Query) matches(order *Order) bool {
+	return (q.MarketId == "" || order.MarketId == q.MarketId) &&
+		(q.SelectionId == 0 || order.SelectionId == q.SelectionId) &&
+		(q.Strategy == "" || order.CustomerStrategyRef == q.Strategy) &&
+		(!q.OpenOnly || order.IsOpen())
+}
+
+// Positions returns the matched position on each runner of a market with
+// matched orders, ordered by selection
+func (m *Manager) Positions(marketId string) []Position {
+	m.mu.Lock()
+	orders := make([]Order, 0, len(m.orders))
+	for _, order := range m.orders {
+		if order.MarketId == marketId {
+			orders = append(orders, *order)
+		}
+	}
+	m.mu.Unlock()
+	return MatchedPositions(marketId, orders)
+}
+
+// MatchedPositions returns the position on each runner of a market from the
+// matched part of orders, ordered by selection
+func MatchedPositions(marketId string, orders []Order) []Position {
+	one := types.NewDecimalFromInt(1)
+	byRunner := map[string]*Position{}
+	var positions []*Position
+	for _, order := range orders {
+		if order.MarketId != marketId || order.SizeMatched == 0 {
+			continue
+		}
+		key := fmt.Sprintf("%d/%s", order.SelectionId, order.Handicap)
+		position, ok := byRunner[key]
+		if !ok {
+			position = &Position{MarketId: marketId, SelectionId: order.SelectionId, Handicap: order.Handicap}
+			byRunner[key] = position
+			positions = append(positions, position)
+		}
+		winnings := order.SizeMatched.Mul(order.AveragePriceMatched.Sub(one))
+		if order.Side == types.SideBack {
+			position.BackMatched = position.BackMatched.Add(order.SizeMatched)
+			position.IfWin = position.IfWin.Add(winnings)
+			position.IfLose = position.IfLose.Sub(order.SizeMatched)
+		} else {
+			position.LayMatched = position.LayMatched.Add(order.SizeMatched)
+			position.IfWin = position.IfWin.Sub(winnings)
+			position.IfLose = position.IfLose.Add(order.SizeMatched)
+		}
+	}
+
+	result := make([]Position, 0, len(positions))
+	for _, position := range positions {
+		position.IfWin = position.IfWin.Round(types.MoneyPlaces)
+		position.IfLose = position.IfLose.Round(types.MoneyPlaces)
+		result = append(result, *position)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].SelectionId != result[j].SelectionId {
+			return result[i].SelectionId < result[j].SelectionId
+		}
+		return result[i].Handicap < result[j].Handicap
+	})
+	return result
+}
+
+// Position returns the matched position on one runner
+func (m *Manager) Position(marketId string, selectionId int, handicap types.Decimal) Position {
+	for _, position := range m.Positions(marketId) {
+		if position.SelectionId == selectionId && position.Handicap == handicap {
+			return position
+		}
+	}
+	return Position{MarketId: marketId, SelectionId: selectionId, Handicap: handicap}
+}
@@ -0,0 +1,268 @@
+package orders
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/risk"
+	"github.com/guysports/go-betfair-api/pkg/transport"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type (
+	bookSource struct {
+		book types.MarketBookWrapper
+	}
+
+	// lostReplyAPI places orders but reports a failure, as when the response
+	// is lost in transit
+	lostReplyAPI struct {
+		betting.APIInterface
+	}
+
+	// stubAPI fails placements with err and lists current orders from listing
+	stubAPI struct {
+		betting.APIInterface
+		err     error
+		listing *types.CurrentOrdersWrapper
+	}
+)
+
+func (s *bookSource) MarketBooks(marketIds []string) ([]types.MarketBookWrapper, error) {
+	return []types.MarketBookWrapper{s.book}, nil
+}
+
+func (a *lostReplyAPI) PlaceOrders(params *types.PlaceInstructionParams) (*types.PlaceExecutionReport, error) {
+	_, _ = a.APIInterface.PlaceOrders(params)
+	return nil, errors.New("timeout")
+}
+
+func (a *stubAPI) PlaceOrders(params *types.PlaceInstructionParams) (*types.PlaceExecutionReport, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	return a.APIInterface.PlaceOrders(params)
+}
+
+func (a *stubAPI) ListCurrentOrders() (*types.CurrentOrdersWrapper, error) {
+	return a.listing, nil
+}
+
+func d(s string) types.Decimal { return types.MustParseDecimal(s) }
+
+func newSimulatedAPI() *betting.API {
+	return betting.NewTransportAPI(newSimulatedExchange())
+}
+
+func newSimulatedExchange() types.TransportInterface {
+	source := &bookSource{book: types.MarketBookWrapper{
+		MarketId: "1.1",
+		Status:   types.MarketStatusOpen,
+		Runners: []types.Runner{
+			{SelectionID: 1, Status: types.RunnerStatusActive, Exchange: types.ExchangePrices{
+				AvailableToBack: []types.Odds{{Price: d("3"), Size: d("100")}},
+				AvailableToLay:  []types.Odds{{Price: d("3.1"), Size: d("100")}},
+			}},
+			{SelectionID: 2, Status: types.RunnerStatusActive},
+		},
+	}}
+	return transport.NewSimulatedTransport(source, nil)
+}
+
+func limit(strategy string, selectionId int, side, price, size string) *types.PlaceInstructionParams {
+	return &types.PlaceInstructionParams{
+		MarketID:            "1.1",
+		CustomerStrategyRef: strategy,
+		Instructions: []types.PlaceInstruction{{
+			OrderType:   types.OrderTypeLimit,
+			SelectionId: selectionId,
+			Side:        side,
+			LimitOrder:  &types.LimitOrder{Price: d(price), Size: d(size), PersistanceType: types.PersistenceLapse},
+		}},
+	}
+}
+
+func TestManager_Lifecycle(t *testing.T) {
+	api := newSimulatedAPI()
+	m := NewManager(api)
+	var updates []Order
+	m.OnUpdate = func(order Order) { updates = append(updates, order) }
+
+	if _, err := m.Place(limit("alpha", 1, types.SideBack, "3", "10")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Place(limit("alpha", 1, types.SideLay, "2.5", "4")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Place(limit("beta", 2, types.SideBack, "5", "2")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Place(limit("beta", 9, types.SideBack, "5", "2")); err != nil {
+		t.Fatal(err)
+	}
+
+	all := m.Orders(Query{})
+	if len(all) != 4 || all[0].CustomerOrderRef == "" || all[0].CustomerOrderRef == all[1].CustomerOrderRef {
+		t.Fatalf("orders %+v", all)
+	}
+	if all[0].Status != types.OrderStatusExecutionComplete || all[3].Status != StatusFailed || all[3].ErrorCode == "" {
+		t.Errorf("statuses %s %s %s", all[0].Status, all[3].Status, all[3].ErrorCode)
+	}
+	// Each placement is reported as PENDING and again once placed
+	if len(updates) != 8 || updates[0].Status != types.OrderStatusPending {
+		t.Errorf("%d updates, first %s", len(updates), updates[0].Status)
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  int
+	}{
+		{name: "open in market", query: Query{MarketId: "1.1", OpenOnly: true}, want: 2},
+		{name: "by selection", query: Query{SelectionId: 1}, want: 2},
+		{name: "open by strategy", query: Query{Strategy: "alpha", OpenOnly: true}, want: 1},
+		{name: "other market", query: Query{MarketId: "1.2"}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Orders(tt.query); len(got) != tt.want {
+				t.Errorf("got %d orders, want %d", len(got), tt.want)
+			}
+		})
+	}
+
+	position := m.Position("1.1", 1, 0)
+	if position.BackMatched != d("10") || position.IfWin != d("20") || position.IfLose != d("-10") {
+		t.Errorf("position %+v", position)
+	}
+
+	lay := m.OpenOrders("1.1", 1, "alpha")[0]
+	if _, err := m.Replace(&types.ReplaceInstructionParams{
+		MarketID:     "1.1",
+		Instructions: []types.ReplaceInstruction{{BetId: lay.BetId, NewPrice: d("3.1")}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	original, _ := m.Order(lay.BetId)
+	replacement, ok := m.Order(original.ReplacedBy)
+	if original.Status != StatusCancelled || !ok || replacement.Replaces != lay.BetId || replacement.SizeMatched != d("4") {
+		t.Errorf("replaced %+v by %+v", original, replacement)
+	}
+	if position := m.Position("1.1", 1, 0); position.IfWin != d("11.6") || position.IfLose != d("-6") {
+		t.Errorf("position after laying off %+v", position)
+	}
+
+	open := m.OpenOrders("1.1", 2, "")
+	if _, err := m.Cancel(&types.CancelInstructionParams{MarketID: "1.1", Instructions: []types.CancelInstruction{{BetId: open[0].BetId}}}); err != nil {
+		t.Fatal(err)
+	}
+	if cancelled, _ := m.Order(open[0].CustomerOrderRef); cancelled.Status != StatusCancelled || cancelled.SizeCancelled != d("2") {
+		t.Errorf("cancelled %+v", cancelled)
+	}
+}
+
+func TestManager_Reconcile(t *testing.T) {
+	api := newSimulatedAPI()
+	before := NewManager(api)
+	if _, err := before.Place(limit("alpha", 2, types.SideBack, "5", "2")); err != nil {
+		t.Fatal(err)
+	}
+
+	// After a restart the open order is adopted
+	m := NewManager(&lostReplyAPI{APIInterface: api})
+	if err := m.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	adopted := m.Orders(Query{})
+	if len(adopted) != 1 || !adopted[0].Reconciled || adopted[0].Status != types.OrderStatusExecutable || adopted[0].CustomerStrategyRef != "alpha" {
+		t.Fatalf("adopted %+v", adopted)
+	}
+
+	// A placement whose reply is lost stays pending until reconciled by ref
+	if _, err := m.Place(limit("alpha", 1, types.SideBack, "3", "5")); err == nil {
+		t.Fatal("expected the placement error")
+	}
+	pending := m.OpenOrders("1.1", 1, "")
+	if len(pending) != 1 || pending[0].Status != types.OrderStatusPending || pending[0].BetId != "" {
+		t.Fatalf("pending %+v", pending)
+	}
+	if err := m.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	recovered, _ := m.Order(pending[0].CustomerOrderRef)
+	if recovered.BetId == "" || recovered.Reconciled || recovered.Status != types.OrderStatusExecutionComplete || len(m.Orders(Query{})) != 2 {
+		t.Errorf("recovered %+v", recovered)
+	}
+}
+
+func TestManager_PlaceFailures(t *testing.T) {
+	guarded, _ := risk.NewAPI(newSimulatedExchange(), risk.Limits{MaxOrderStake: d("50")})
+
+	tests := []struct {
+		name   string
+		api    betting.APIInterface
+		status string
+	}{
+		{name: "risk limit", api: guarded, status: StatusFailed},
+		{name: "exchange rejection", api: &stubAPI{err: &transport.RPCError{Code: -32099, Message: "ANGX-0003"}}, status: StatusFailed},
+		{name: "validation", api: &stubAPI{err: &betting.ValidationError{Request: []error{betting.ErrMarketNotOpen}}}, status: StatusFailed},
+		{name: "unknown outcome", api: &stubAPI{err: errors.New("timeout")}, status: types.OrderStatusPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(tt.api)
+			if _, err := m.Place(limit("alpha", 1, types.SideBack, "10", "60")); err == nil {
+				t.Fatal("expected the placement error")
+			}
+			if orders := m.Orders(Query{}); len(orders) != 1 || orders[0].Status != tt.status {
+				t.Errorf("orders %+v, want %s", orders, tt.status)
+			}
+		})
+	}
+}
+
+func TestManager_ReconcileSweepsMissingOrders(t *testing.T) {
+	api := newSimulatedAPI()
+	stub := &stubAPI{APIInterface: api}
+	m := NewManager(stub)
+	now := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	m.Now = func() time.Time { return now }
+
+	if _, err := m.Place(limit("alpha", 2, types.SideBack, "5", "2")); err != nil {
+		t.Fatal(err)
+	}
+	stub.err = errors.New("timeout")
+	if _, err := m.Place(limit("alpha", 1, types.SideBack, "5", "2")); err == nil {
+		t.Fatal("expected the placement error")
+	}
+	executable, pending := m.Orders(Query{})[0], m.Orders(Query{})[1]
+
+	now = now.Add(time.Second)
+	stub.listing = &types.CurrentOrdersWrapper{MoreAvailable: true}
+	if err := m.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	if open := m.OpenOrders("", 0, ""); len(open) != 2 {
+		t.Fatalf("open after a partial listing %+v", open)
+	}
+
+	stub.listing = &types.CurrentOrdersWrapper{}
+	if err := m.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	lapsed, _ := m.Order(executable.BetId)
+	stillPending, _ := m.Order(pending.CustomerOrderRef)
+	if lapsed.Status != StatusLapsed || lapsed.SizeLapsed != d("2") || stillPending.Status != types.OrderStatusPending {
+		t.Fatalf("after a complete listing %+v and %+v", lapsed, stillPending)
+	}
+
+	now = now.Add(DefaultPendingGrace)
+	if err := m.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	if failed, _ := m.Order(pending.CustomerOrderRef); failed.Status != StatusFailed {
+		t.Errorf("after the grace period %+v", failed)
+	}
+}
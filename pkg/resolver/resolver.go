@@ -0,0 +1,371 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	DefaultMinScore = 0.5
+
+	// tokenMatch is how alike two words must be to count as the same word,
+	// enough to forgive a typo or two in a longer name
+	tokenMatch = 0.75
+	// ambiguity is how close the top two scores must be to need a tie-break
+	ambiguity = 1e-9
+)
+
+var (
+	ErrNotFound  = errors.New("no match found")
+	ErrAmbiguous = errors.New("name matches more than one candidate")
+
+	// stopWords carry no meaning in a fixture or competition name
+	stopWords = map[string]bool{"v": true, "vs": true, "the": true, "and": true, "fc": true}
+)
+
+type (
+	// Resolver finds event type, competition and event IDs from human names,
+	// asking the exchange with a text query and scoring the names it returns
+	Resolver struct {
+		API betting.APIInterface
+		// MinScore is the lowest score a candidate needs, from 0 to 1
+		MinScore float64
+		Now      func() time.Time
+	}
+
+	// Options narrow a lookup. An empty field does not restrict it
+	Options struct {
+		EventTypeIds   []string
+		CompetitionIds []string
+		// CountryCode restricts events by country and competitions by region
+		CountryCode string
+		// From and To bound an event's start time
+		From time.Time
+		To   time.Time
+		// Near breaks ties between equally named events in favour of the one
+		// starting closest to it, the current time when zero
+		Near time.Time
+	}
+
+	// Candidate is a match, best first
+	Candidate struct {
+		ID          string
+		Name        string
+		Score       float64
+		CountryCode string
+		OpenDate    time.Time
+		MarketCount int
+	}
+
+	// AmbiguousError lists the candidates a name could not decide between
+	AmbiguousError struct {
+		Name       string
+		Candidates []Candidate
+	}
+)
+
+func NewResolver(api betting.APIInterface) *Resolver {
+	return &Resolver{
+		API:      api,
+		MinScore: DefaultMinScore,
+		Now:      time.Now,
+	}
+}
+
+func (e *AmbiguousError) Error() string {
+	names := make([]string, 0, len(e.Candidates))
+	for _, candidate := range e.Candidates {
+		names = append(names, fmt.Sprintf("%s (%s)", candidate.Name, candidate.ID))
+	}
+	return fmt.Sprintf("%q matches %s", e.Name, strings.Join(names, ", "))
+}
+
+func (e *AmbiguousError) Unwrap() error {
+	return ErrAmbiguous
+}
+
+// EventTypes ranks the event types by how well they match name
+func (r *Resolver) EventTypes(name string) ([]Candidate, error) {
+	eventTypes, err := r.API.ListEventTypes(&types.MarketFilter{})
+	if err != nil {
+		return nil, err
+	}
+	var candidates []Candidate
+	for _, eventType := range eventTypes {
+		if eventType.EventType != nil {
+			candidates = append(candidates, Candidate{ID: eventType.EventType.ID, Name: eventType.EventType.Name, MarketCount: eventType.MarketCount})
+		}
+	}
+	return r.rank(name, candidates, time.Time{}), nil
+}
+
+// Competitions ranks competitions by how well they match name
+func (r *Resolver) Competitions(name string, opts *Options) ([]Candidate, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	search := func(filter *types.MarketFilter) ([]Candidate, error) {
+		competitions, err := r.API.ListCompetitions(filter)
+		if err != nil {
+			return nil, err
+		}
+		var candidates []Candidate
+		for _, competition := range competitions {
+			if competition.Competition == nil {
+				continue
+			}
+			if opts.CountryCode != "" && !strings.EqualFold(competition.Region, opts.CountryCode) {
+				continue
+			}
+			candidates = append(candidates, Candidate{
+				ID:          competition.Competition.ID,
+				Name:        competition.Competition.Name,
+				CountryCode: competition.Region,
+				MarketCount: competition.MarketCount,
+			})
+		}
+		return r.rank(name, candidates, time.Time{}), nil
+	}
+	return r.withFallback(name, &types.MarketFilter{EventTypeIds: opts.EventTypeIds}, search)
+}
+
+// Events ranks events by how well they match name. Events with the same
+// name, such as a fixture played twice a season, are ordered by how close
+// they start to opts.Near
+func (r *Resolver) Events(name string, opts *Options) ([]Candidate, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	filter := &types.MarketFilter{
+		EventTypeIds:   opts.EventTypeIds,
+		CompetitionIds: opts.CompetitionIds,
+	}
+	if opts.CountryCode != "" {
+		filter.MarketCountries = []string{opts.CountryCode}
+	}
+	if !opts.From.IsZero() || !opts.To.IsZero() {
+		filter.MarketStartTime = &types.TimeRange{}
+		if !opts.From.IsZero() {
+			filter.MarketStartTime.From = opts.From.Format(time.RFC3339)
+		}
+		if !opts.To.IsZero() {
+			filter.MarketStartTime.To = opts.To.Format(time.RFC3339)
+		}
+	}
+	near := opts.Near
+	if near.IsZero() {
+		near = r.Now()
+	}
+
+	search := func(filter *types.MarketFilter) ([]Candidate, error) {
+		events, err := r.API.ListEvents(filter)
+		if err != nil {
+			return nil, err
+		}
+		var candidates []Candidate
+		for _, event := range events {
+			if event.Event == nil {
+				continue
+			}
+			if opts.CountryCode != "" && event.Event.CountryCode != "" && !strings.EqualFold(event.Event.CountryCode, opts.CountryCode) {
+				continue
+			}
+			openDate, _ := time.Parse(time.RFC3339, event.Event.OpenDate)
+			candidates = append(candidates, Candidate{
+				ID:          event.Event.ID,
+				Name:        event.Event.Name,
+				CountryCode: event.Event.CountryCode,
+				OpenDate:    openDate,
+				MarketCount: event.MarketCount,
+			})
+		}
+		return r.rank(name, candidates, near), nil
+	}
+	return r.withFallback(name, filter, search)
+}
+
+// EventType returns the one event type matching name
+func (r *Resolver) EventType(name string) (*Candidate, error) {
+	candidates, err := r.EventTypes(name)
+	return best(name, candidates, err)
+}
+
+// Competition returns the one competition matching name
+func (r *Resolver) Competition(name string, opts *Options) (*Candidate, error) {
+	candidates, err := r.Competitions(name, opts)
+	return best(name, candidates, err)
+}
+
+// Event returns the one event matching name, the one starting nearest
+// opts.Near when several share the name
+func (r *Resolver) Event(name string, opts *Options) (*Candidate, error) {
+	candidates, err := r.Events(name, opts)
+	return best(name, candidates, err)
+}
+
+// withFallback searches with the name as a text query, which the exchange
+// matches strictly, and again without it should that find nothing good
+func (r *Resolver) withFallback(name string, filter *types.MarketFilter, search func(*types.MarketFilter) ([]Candidate, error)) ([]Candidate, error) {
+	query := *filter
+	query.TextQuery = name
+	candidates, err := search(&query)
+	if err != nil || len(candidates) > 0 {
+		return candidates, err
+	}
+	return search(filter)
+}
+
+// rank scores the candidates, drops those below MinScore and orders the rest
+// by score, then start time nearest near, then market count
+func (r *Resolver) rank(name string, candidates []Candidate, near time.Time) []Candidate {
+	var ranked []Candidate
+	for _, candidate := range candidates {
+		candidate.Score = Score(name, candidate.Name)
+		if candidate.Score >= r.MinScore {
+			ranked = append(ranked, candidate)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if math.Abs(a.Score-b.Score) > ambiguity {
+			return a.Score > b.Score
+		}
+		if !near.IsZero() && !a.OpenDate.Equal(b.OpenDate) {
+			return distance(a.OpenDate, near) < distance(b.OpenDate, near)
+		}
+		return a.MarketCount > b.MarketCount
+	})
+	return ranked
+}
+
+func best(name string, candidates []Candidate, err error) (*Candidate, error) {
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	// Equal scores are only ambiguous when nothing else tells them apart
+	if len(candidates) > 1 && math.Abs(candidates[0].Score-candidates[1].Score) <= ambiguity &&
+		candidates[0].OpenDate.Equal(candidates[1].OpenDate) {
+		var tied []Candidate
+		for _, candidate := range candidates {
+			if math.Abs(candidate.Score-candidates[0].Score) <= ambiguity {
+				tied = append(tied, candidate)
+			}
+		}
+		return nil, &AmbiguousError{Name: name, Candidates: tied}
+	}
+	return &candidates[0], nil
+}
+
+func distance(t, near time.Time) time.Duration {
+	if t.IsZero() {
+		return math.MaxInt64
+	}
+	d := t.Sub(near)
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// Score rates how well a name matches a query from 0 to 1. Names are
+// compared word by word ignoring case, punctuation and words like "v", with
+// each query word matching the most alike word in the name. Words missing
+// from the name cost more than extra words in it
+func Score(query, name string) float64 {
+	q, n := tokens(query), tokens(name)
+	if len(q) == 0 || len(n) == 0 {
+		return 0
+	}
+	if strings.Join(q, " ") == strings.Join(n, " ") {
+		return 1
+	}
+	covered := coverage(q, n)
+	extra := coverage(n, q)
+	// An exact match is the only way to score 1
+	return math.Min(0.7*covered+0.29*extra, 0.99)
+}
+
+// coverage is the mean over the words of a of their best likeness to a word of b
+func coverage(a, b []string) float64 {
+	var total float64
+	for _, word := range a {
+		var best float64
+		for _, other := range b {
+			if l := likeness(word, other); l > best {
+				best = l
+			}
+		}
+		if best >= tokenMatch {
+			total += best
+		}
+	}
+	return total / float64(len(a))
+}
+
+// likeness is one less the edit distance as a share of the longer word
+func likeness(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// tokens lower-cases a name and splits it into words, dropping punctuation and stop words
+func tokens(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	result := words[:0]
+	for _, word := range words {
+		if !stopWords[word] {
+			result = append(result, word)
+		}
+	}
+	return result
+}
@@ -0,0 +1,155 @@
+package resolver
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type referenceAPI struct {
+	betting.APIInterface
+	filters []types.MarketFilter
+}
+
+func (a *referenceAPI) ListEventTypes(filter *types.MarketFilter) ([]types.EventTypeWrapper, error) {
+	return []types.EventTypeWrapper{
+		{EventType: &types.Detail{ID: "1", Name: "Soccer"}, MarketCount: 5000},
+		{EventType: &types.Detail{ID: "2", Name: "Tennis"}, MarketCount: 800},
+		{EventType: &types.Detail{ID: "7", Name: "Horse Racing"}, MarketCount: 600},
+		{EventType: &types.Detail{ID: "4339", Name: "Greyhound Racing"}, MarketCount: 900},
+	}, nil
+}
+
+func (a *referenceAPI) ListCompetitions(filter *types.MarketFilter) ([]types.CompetitionWrapper, error) {
+	a.filters = append(a.filters, *filter)
+	// The exchange's text query is strict, so a misspelt query finds nothing
+	if filter.TextQuery != "" && filter.TextQuery != "Premier League" {
+		return nil, nil
+	}
+	return []types.CompetitionWrapper{
+		{Competition: &types.Detail{ID: "10932509", Name: "English Premier League"}, Region: "GBR", MarketCount: 400},
+		{Competition: &types.Detail{ID: "2005", Name: "Premier League 2"}, Region: "GBR", MarketCount: 10},
+		{Competition: &types.Detail{ID: "11", Name: "Egyptian Premier League"}, Region: "EGY", MarketCount: 40},
+	}, nil
+}
+
+func (a *referenceAPI) ListEvents(filter *types.MarketFilter) ([]types.EventWrapper, error) {
+	a.filters = append(a.filters, *filter)
+	return []types.EventWrapper{
+		{Event: &types.Detail{ID: "100", Name: "Arsenal v Chelsea", CountryCode: "GB", OpenDate: "2020-08-22T14:00:00.000Z"}, MarketCount: 50},
+		{Event: &types.Detail{ID: "101", Name: "Arsenal v Chelsea", CountryCode: "GB", OpenDate: "2021-02-13T14:00:00.000Z"}, MarketCount: 50},
+		{Event: &types.Detail{ID: "102", Name: "Chelsea v Arsenal", CountryCode: "GB", OpenDate: "2020-12-05T17:30:00.000Z"}, MarketCount: 50},
+		{Event: &types.Detail{ID: "103", Name: "Arsenal Tula v Rostov", CountryCode: "RU", OpenDate: "2020-08-22T12:00:00.000Z"}, MarketCount: 20},
+	}, nil
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		query, name string
+		min, max    float64
+	}{
+		{"Soccer", "soccer", 1, 1},
+		{"Arsenal v Chelsea", "Arsenal vs. Chelsea", 1, 1},
+		{"Arsnal v Chelsea", "Arsenal v Chelsea", 0.9, 0.95},
+		{"Premier League", "English Premier League", 0.85, 0.9},
+		{"Chelsea", "Arsenal v Chelsea", 0.8, 0.9},
+		{"Tennis", "Soccer", 0, 0},
+		{"", "Soccer", 0, 0},
+	}
+	for _, tt := range tests {
+		if got := Score(tt.query, tt.name); got < tt.min || got > tt.max {
+			t.Errorf("Score(%q, %q) = %v, want between %v and %v", tt.query, tt.name, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestResolver_EventType(t *testing.T) {
+	r := NewResolver(&referenceAPI{})
+	tests := []struct {
+		name string
+		want string
+		err  error
+	}{
+		{"Soccer", "1", nil},
+		{"horse racing", "7", nil},
+		{"Greyhounds", "4339", nil},
+		{"Cricket", "", ErrNotFound},
+		{"Racing", "", ErrAmbiguous},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.EventType(tt.name)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("EventType() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && got.ID != tt.want {
+				t.Errorf("EventType() = %+v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	var ambiguous *AmbiguousError
+	if _, err := r.EventType("Racing"); !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+		t.Errorf("EventType() error = %v, want both racing codes", err)
+	}
+}
+
+func TestResolver_Competition(t *testing.T) {
+	api := &referenceAPI{}
+	r := NewResolver(api)
+
+	got, err := r.Competition("English Premier League", &Options{EventTypeIds: []string{"1"}})
+	if err != nil || got.ID != "10932509" {
+		t.Fatalf("Competition() = %+v, %v", got, err)
+	}
+	// The misspelt query falls back to matching the full list locally
+	if len(api.filters) != 2 || api.filters[1].TextQuery != "" || api.filters[1].EventTypeIds[0] != "1" {
+		t.Errorf("filters = %+v, want a text query then a fallback", api.filters)
+	}
+
+	candidates, err := r.Competitions("Premier League", &Options{CountryCode: "EGY"})
+	if err != nil || len(candidates) != 1 || candidates[0].ID != "11" {
+		t.Errorf("Competitions() in EGY = %+v, %v", candidates, err)
+	}
+}
+
+func TestResolver_Event(t *testing.T) {
+	api := &referenceAPI{}
+	r := NewResolver(api)
+	r.Now = func() time.Time { return time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC) }
+
+	got, err := r.Event("Arsenal v Chelsea", &Options{EventTypeIds: []string{"1"}, CountryCode: "GB"})
+	if err != nil || got.ID != "100" {
+		t.Fatalf("Event() = %+v, %v, want the next fixture", got, err)
+	}
+	if api.filters[0].TextQuery != "Arsenal v Chelsea" || api.filters[0].MarketCountries[0] != "GB" {
+		t.Errorf("filter = %+v", api.filters[0])
+	}
+
+	got, err = r.Event("Arsenal v Chelsea", &Options{Near: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil || got.ID != "101" {
+		t.Errorf("Event() near February = %+v, %v, want the return fixture", got, err)
+	}
+
+	candidates, err := r.Events("Arsenal", &Options{CountryCode: "GB"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, candidate := range candidates {
+		if candidate.ID == "103" {
+			t.Error("Events() included a fixture outside the country")
+		}
+	}
+
+	from := time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := r.Events("Arsenal", &Options{From: from}); err != nil {
+		t.Fatal(err)
+	}
+	last := api.filters[len(api.filters)-1]
+	if last.MarketStartTime == nil || last.MarketStartTime.From != "2020-09-01T00:00:00Z" || last.MarketStartTime.To != "" {
+		t.Errorf("MarketStartTime = %+v", last.MarketStartTime)
+	}
+}
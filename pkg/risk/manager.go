@@ -0,0 +1,776 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	LimitOrderStake        = "MAX_ORDER_STAKE"
+	LimitMarketLiability   = "MAX_MARKET_LIABILITY"
+	LimitEventLiability    = "MAX_EVENT_LIABILITY"
+	LimitStrategyLiability = "MAX_STRATEGY_LIABILITY"
+	LimitDailyLiability    = "MAX_DAILY_LIABILITY"
+	LimitOpenOrders        = "MAX_OPEN_ORDERS"
+	LimitDailyLoss         = "DAILY_LOSS_STOP"
+
+	// DefaultUnknownGrace is how long an order whose placement outcome is
+	// unknown may take to appear in listCurrentOrders
+	DefaultUnknownGrace = time.Minute
+	// DefaultSyncInterval is how stale the manager's view of the exchange may
+	// get before the next check syncs it
+	DefaultSyncInterval = time.Minute
+)
+
+var (
+	ErrLimitExceeded = errors.New("risk limit exceeded")
+	ErrKillSwitch    = errors.New("kill switch engaged")
+)
+
+type (
+	// Limits are checked before every placement. Zero disables a limit
+	Limits struct {
+		MaxOrderStake        types.Decimal
+		MaxMarketLiability   types.Decimal
+		MaxEventLiability    types.Decimal
+		MaxStrategyLiability types.Decimal
+		MaxDailyLiability    types.Decimal
+		MaxOpenOrders        int
+		// DailyLossLimit stops new orders once the day's realised loss reaches it
+		DailyLossLimit types.Decimal
+	}
+
+	// LimitError reports the limit an order would breach. It matches
+	// ErrLimitExceeded with errors.Is
+	LimitError struct {
+		Limit     string
+		Scope     string
+		Current   types.Decimal
+		Requested types.Decimal
+		Max       types.Decimal
+	}
+
+	// Manager is a betting.Guard enforcing Limits and a kill switch. Liability
+	// is reserved when an order is checked and released for size the exchange
+	// rejects, cancels, lapses or voids. Matched size stays counted until its
+	// market closes or settles. An order whose outcome is unknown continues to
+	// count until SyncOrders finds it by its customer order ref, or finds it
+	// missing once UnknownGrace has passed. Checks run Sync first once the last
+	// sync is SyncInterval old, so settled markets and the day's profit and
+	// loss are picked up without the caller syncing
+	Manager struct {
+		API    betting.APIInterface
+		Limits Limits
+		// EventOf resolves a market's event for the per-event limit. It
+		// defaults to a cached listMarketCatalogue lookup
+		EventOf      func(marketId string) (string, error)
+		Now          func() time.Time
+		UnknownGrace time.Duration
+		// SyncInterval is how often checks sync with the exchange. Zero
+		// leaves syncing to the caller
+		SyncInterval time.Duration
+
+		mu        sync.Mutex
+		synced    time.Time
+		killed    bool
+		day       string
+		daily     types.Decimal
+		dailyPnL  types.Decimal
+		markets   map[string]types.Decimal
+		events    map[string]types.Decimal
+		strategy  map[string]types.Decimal
+		orders    map[string]*order
+		open      int
+		eventById map[string]string
+		// unknown holds orders placed with an unknown outcome by market and
+		// customer order ref
+		unknown map[string]*unknownOrder
+		// reservedOn is the day CheckPlace reserved each placement's liability
+		reservedOn map[*types.PlaceInstructionParams]string
+		// settling holds finished orders by market whose matched liability
+		// is counted until the market settles
+		settling map[string][]*order
+		// cleared holds the bet ids whose settled profit is in dailyPnL
+		cleared map[string]bool
+	}
+
+	order struct {
+		marketId  string
+		eventId   string
+		strategy  string
+		side      string
+		orderType string
+		price     types.Decimal
+		// day is when the order's liability was reserved
+		day string
+		// remaining is the unmatched size, or liability for an SP order
+		remaining types.Decimal
+		// gone is the size cancelled, lapsed or voided and already released
+		gone types.Decimal
+		// held is the liability still counted for the order
+		held types.Decimal
+	}
+
+	unknownOrder struct {
+		order    *order
+		exposure exposure
+		at       time.Time
+	}
+
+	// exposure is the liability an instruction adds in each scope. Only
+	// exposure reserved on the current day counts towards the daily total
+	exposure struct {
+		marketId  string
+		eventId   string
+		strategy  string
+		day       string
+		liability types.Decimal
+	}
+)
+
+// NewAPI builds a betting API on client guarded by a new Manager, which uses
+// the same API to cancel orders and sync with the exchange
+func NewAPI(client types.TransportInterface, limits Limits, opts ...betting.Option) (*betting.API, *Manager) {
+	m := NewManager(nil, limits)
+	api := betting.NewTransportAPI(client, append(opts, betting.WithGuard(m))...)
+	m.API = api
+	return api, m
+}
+
+// NewManager creates a Manager to give to betting.WithGuard. api is used to
+// cancel orders, sync and look up events
+func NewManager(api betting.APIInterface, limits Limits) *Manager {
+	m := &Manager{
+		API:          api,
+		Limits:       limits,
+		Now:          time.Now,
+		UnknownGrace: DefaultUnknownGrace,
+		SyncInterval: DefaultSyncInterval,
+		markets:      map[string]types.Decimal{},
+		events:       map[string]types.Decimal{},
+		strategy:     map[string]types.Decimal{},
+		orders:       map[string]*order{},
+		eventById:    map[string]string{},
+		unknown:      map[string]*unknownOrder{},
+		reservedOn:   map[*types.PlaceInstructionParams]string{},
+		settling:     map[string][]*order{},
+		cleared:      map[string]bool{},
+	}
+	m.EventOf = m.lookupEvent
+	return m
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s exceeded for %s: %s with %s requested, limit %s", e.Limit, e.Scope, e.Current, e.Requested, e.Max)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// Liability is the most an instruction can lose: the stake of a back and the
+// stake times the price less one of a lay. SP orders state their liability
+func Liability(instruction *types.PlaceInstruction) types.Decimal {
+	switch {
+	case instruction.LimitOrder != nil:
+		return liability(instruction.Side, instruction.LimitOrder.Price, instruction.LimitOrder.Size)
+	case instruction.LimitOnCloseOrder != nil:
+		return instruction.LimitOnCloseOrder.Liability
+	case instruction.MarketOnCloseOrder != nil:
+		return instruction.MarketOnCloseOrder.Liability
+	}
+	return 0
+}
+
+func liability(side string, price, size types.Decimal) types.Decimal {
+	if side == types.SideLay {
+		return size.Mul(price.Sub(types.NewDecimalFromInt(1))).Round(types.MoneyPlaces)
+	}
+	return size
+}
+
+// Kill engages the kill switch, blocking new orders until Reset, and cancels
+// every unmatched order on the exchange
+func (m *Manager) Kill() (*types.CancelExecutionReport, error) {
+	m.mu.Lock()
+	m.killed = true
+	m.mu.Unlock()
+	return m.API.CancelOrders(&types.CancelInstructionParams{})
+}
+
+func (m *Manager) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.killed = false
+}
+
+func (m *Manager) Killed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.killed
+}
+
+// RecordProfit adds settled profit or loss to the day's total for the daily
+// loss stop. Profit on orders seen by SyncCleared is already counted
+func (m *Manager) RecordProfit(profit types.Decimal) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.roll()
+	m.dailyPnL = m.dailyPnL.Add(profit)
+}
+
+// Sync catches up with the exchange: listCurrentOrders for SyncOrders and
+// the day's settled orders for SyncCleared
+func (m *Manager) Sync() error {
+	if err := m.sync(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.synced = m.Now()
+	return nil
+}
+
+// syncIfDue runs Sync when the last one is SyncInterval old
+func (m *Manager) syncIfDue() error {
+	m.mu.Lock()
+	due := m.SyncInterval > 0 && m.Now().Sub(m.synced) >= m.SyncInterval
+	m.mu.Unlock()
+	if !due {
+		return nil
+	}
+	if err := m.Sync(); err != nil {
+		return fmt.Errorf("risk sync: %w", err)
+	}
+	return nil
+}
+
+func (m *Manager) sync() error {
+	current, err := m.API.ListCurrentOrders()
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		m.SyncOrders(current)
+	}
+
+	m.mu.Lock()
+	m.roll()
+	day := m.day
+	m.mu.Unlock()
+	params := &types.ClearedOrdersParams{
+		BetStatus:        types.BetStatusSettled,
+		SettledDateRange: &types.TimeRange{From: day + "T00:00:00Z"},
+	}
+	for {
+		report, err := m.API.ListClearedOrders(params)
+		if err != nil {
+			return err
+		}
+		if report == nil {
+			return nil
+		}
+		m.SyncCleared(report)
+		if !report.MoreAvailable || len(report.ClearedOrders) == 0 {
+			return nil
+		}
+		params.FromRecord += len(report.ClearedOrders)
+	}
+}
+
+// SyncCleared counts the profit of orders settled today towards the daily
+// loss stop, once per bet, and releases the liability of their markets
+func (m *Manager) SyncCleared(report *types.ClearedOrderSummaryReport) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.roll()
+	for _, c := range report.ClearedOrders {
+		if c.BetOutcome == "" {
+			// Lapsed, cancelled and voided orders do not settle their market
+			continue
+		}
+		m.settle(c.MarketId)
+		if m.cleared[c.BetId] || !strings.HasPrefix(c.SettledDate, m.day) {
+			continue
+		}
+		m.cleared[c.BetId] = true
+		m.dailyPnL = m.dailyPnL.Add(c.Profit)
+	}
+}
+
+// SyncMarkets releases the liability of markets whose book has closed
+func (m *Manager) SyncMarkets(books []types.MarketBookWrapper) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range books {
+		if books[i].Status == types.MarketStatusClosed {
+			m.settle(books[i].MarketId)
+		}
+	}
+}
+
+// SyncOrders catches up with listCurrentOrders. Size that lapsed, was
+// cancelled or voided releases its liability while matched size stays
+// counted. Orders placed with an unknown outcome are picked up by their
+// customer order ref, or released when a complete listing still lacks them
+// after UnknownGrace
+func (m *Manager) SyncOrders(current *types.CurrentOrdersWrapper) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	listed := map[string]*types.CurrentOrder{}
+	byRef := map[string]*types.CurrentOrder{}
+	for i := range current.Orders {
+		c := &current.Orders[i]
+		listed[c.BetId] = c
+		if c.CustomerOrderRef != "" {
+			byRef[unknownKey(c.MarketId, c.CustomerOrderRef)] = c
+		}
+	}
+
+	for key, u := range m.unknown {
+		if c, ok := byRef[key]; ok {
+			delete(m.unknown, key)
+			if _, tracked := m.orders[c.BetId]; !tracked {
+				m.orders[c.BetId] = u.order
+			} else {
+				m.add(u.exposure.negate())
+				m.open--
+			}
+			continue
+		}
+		if !current.MoreAvailable && m.Now().Sub(u.at) >= m.UnknownGrace {
+			delete(m.unknown, key)
+			m.add(u.exposure.negate())
+			m.open--
+		}
+	}
+
+	for betId, o := range m.orders {
+		c, ok := listed[betId]
+		if !ok {
+			// Orders leave the listing once their market settles, when any
+			// unmatched size has lapsed
+			if !current.MoreAvailable {
+				m.release(o, o.remaining)
+				delete(m.orders, betId)
+				m.finish(o)
+			}
+			continue
+		}
+		m.release(o, c.SizeLapsed.Add(c.SizeCancelled).Add(c.SizeVoided).Sub(o.gone))
+		o.remaining = c.SizeRemaining
+		if c.Status == types.OrderStatusExecutionComplete || o.remaining <= 0 {
+			delete(m.orders, betId)
+			m.finish(o)
+		}
+	}
+}
+
+// MarketLiability is the liability currently counted against a market
+func (m *Manager) MarketLiability(marketId string) types.Decimal {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.markets[marketId]
+}
+
+func (m *Manager) CheckPlace(params *types.PlaceInstructionParams) error {
+	if err := m.syncIfDue(); err != nil {
+		return err
+	}
+	var eventId string
+	if m.Limits.MaxEventLiability > 0 {
+		var err error
+		if eventId, err = m.EventOf(params.MarketID); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if eventId != "" {
+		m.eventById[params.MarketID] = eventId
+	}
+	if err := m.checkBlocked(); err != nil {
+		return err
+	}
+	if m.Limits.MaxOpenOrders > 0 && m.open+len(params.Instructions) > m.Limits.MaxOpenOrders {
+		return &LimitError{
+			Limit:     LimitOpenOrders,
+			Scope:     "account",
+			Current:   types.NewDecimalFromInt(int64(m.open)),
+			Requested: types.NewDecimalFromInt(int64(len(params.Instructions))),
+			Max:       types.NewDecimalFromInt(int64(m.Limits.MaxOpenOrders)),
+		}
+	}
+
+	var total types.Decimal
+	for i := range params.Instructions {
+		instruction := &params.Instructions[i]
+		if stake := stake(instruction); m.Limits.MaxOrderStake > 0 && stake > m.Limits.MaxOrderStake {
+			return &LimitError{Limit: LimitOrderStake, Scope: "order", Requested: stake, Max: m.Limits.MaxOrderStake}
+		}
+		total = total.Add(Liability(instruction))
+	}
+	e := exposure{marketId: params.MarketID, eventId: eventId, strategy: params.CustomerStrategyRef, day: m.day, liability: total}
+	if err := m.checkExposure(e); err != nil {
+		return err
+	}
+
+	// Reserve the liability until the report says otherwise
+	m.add(e)
+	m.reservedOn[params] = m.day
+	m.open += len(params.Instructions)
+	return nil
+}
+
+func (m *Manager) CheckReplace(params *types.ReplaceInstructionParams) error {
+	if err := m.syncIfDue(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkBlocked(); err != nil {
+		return err
+	}
+	for _, instruction := range params.Instructions {
+		o, ok := m.orders[instruction.BetId]
+		if !ok {
+			continue
+		}
+		delta := liability(o.side, instruction.NewPrice, o.remaining).Sub(liability(o.side, o.price, o.remaining))
+		if delta <= 0 {
+			continue
+		}
+		if err := m.checkExposure(exposure{marketId: o.marketId, eventId: o.eventId, strategy: o.strategy, day: m.day, liability: delta}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Placed settles the reservation of every instruction. Those without a
+// SUCCESS or TIMEOUT report were not placed and are released, including all
+// of them when the market level report failed
+func (m *Manager) Placed(params *types.PlaceInstructionParams, report *types.PlaceExecutionReport) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer delete(m.reservedOn, params)
+	for i := range params.Instructions {
+		o, e := m.newOrder(params, &params.Instructions[i])
+		status := report.Status
+		var ir *types.PlaceInstructionReport
+		if i < len(report.InstructionReports) {
+			ir = &report.InstructionReports[i]
+			status = ir.Status
+		}
+		switch {
+		case status == types.InstructionStatusTimeout:
+			m.remember(params, &params.Instructions[i], o, e)
+			continue
+		case status != types.InstructionStatusSuccess || ir == nil:
+			m.add(e.negate())
+			m.open--
+			continue
+		}
+		if o.orderType == types.OrderTypeLimit {
+			o.remaining = o.remaining.Sub(ir.SizeMatched)
+		}
+		if ir.OrderStatus == types.OrderStatusExecutionComplete {
+			// Whatever did not match straight away has lapsed
+			m.release(o, o.remaining)
+			m.finish(o)
+			continue
+		}
+		m.orders[ir.BetId] = o
+	}
+}
+
+// Failed releases the reservation of orders the exchange rejected. When the
+// outcome is unknown the reservation stands, and SyncOrders settles it for
+// orders with a customer order ref
+func (m *Manager) Failed(params *types.PlaceInstructionParams, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer delete(m.reservedOn, params)
+	for i := range params.Instructions {
+		o, e := m.newOrder(params, &params.Instructions[i])
+		if betting.Rejected(err) {
+			m.add(e.negate())
+			m.open--
+			continue
+		}
+		m.remember(params, &params.Instructions[i], o, e)
+	}
+}
+
+// newOrder is the order an instruction places and the liability reserved for it
+func (m *Manager) newOrder(params *types.PlaceInstructionParams, instruction *types.PlaceInstruction) (*order, exposure) {
+	e := exposure{
+		marketId:  params.MarketID,
+		eventId:   m.eventById[params.MarketID],
+		strategy:  params.CustomerStrategyRef,
+		day:       m.reservedOn[params],
+		liability: Liability(instruction),
+	}
+	o := &order{
+		marketId:  params.MarketID,
+		eventId:   e.eventId,
+		strategy:  params.CustomerStrategyRef,
+		day:       e.day,
+		side:      instruction.Side,
+		orderType: instruction.OrderType,
+		remaining: e.liability,
+		held:      e.liability,
+	}
+	if instruction.LimitOrder != nil {
+		o.price = instruction.LimitOrder.Price
+		o.remaining = instruction.LimitOrder.Size
+	}
+	return o, e
+}
+
+// remember holds an order with an unknown outcome for SyncOrders. Without a
+// customer order ref it cannot be found, so its reservation stands
+func (m *Manager) remember(params *types.PlaceInstructionParams, instruction *types.PlaceInstruction, o *order, e exposure) {
+	if instruction.CustomerOrderRef == "" {
+		return
+	}
+	m.unknown[unknownKey(params.MarketID, instruction.CustomerOrderRef)] = &unknownOrder{order: o, exposure: e, at: m.Now()}
+}
+
+func unknownKey(marketId, customerOrderRef string) string {
+	return marketId + "/" + customerOrderRef
+}
+
+func (m *Manager) Cancelled(report *types.CancelExecutionReport) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, ir := range report.InstructionReports {
+		if ir.Status != types.InstructionStatusSuccess {
+			continue
+		}
+		if o, ok := m.orders[ir.Instruction.BetId]; ok {
+			m.reduce(ir.Instruction.BetId, o, ir.SizeCancelled)
+		}
+	}
+}
+
+// CancelFailed keeps counting the orders, which may not have been cancelled,
+// and has the next check sync to find out
+func (m *Manager) CancelFailed(params *types.CancelInstructionParams, err error) {
+	m.unsure(err)
+}
+
+// ReplaceFailed keeps counting the orders at their old prices and has the
+// next check sync to find out whether they moved
+func (m *Manager) ReplaceFailed(params *types.ReplaceInstructionParams, err error) {
+	m.unsure(err)
+}
+
+// unsure forces a sync before the next check unless the exchange rejected
+// the request outright
+func (m *Manager) unsure(err error) {
+	if betting.Rejected(err) {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.synced = time.Time{}
+}
+
+func (m *Manager) Replaced(params *types.ReplaceInstructionParams, report *types.ReplaceExecutionReport) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.roll()
+	for i, ir := range report.InstructionReports {
+		if i >= len(params.Instructions) {
+			break
+		}
+		o, ok := m.orders[params.Instructions[i].BetId]
+		cancel := ir.CancelInstructionReport
+		if !ok || cancel == nil || cancel.Status != types.InstructionStatusSuccess {
+			continue
+		}
+		m.reduce(params.Instructions[i].BetId, o, cancel.SizeCancelled)
+		place := ir.PlaceInstructionReport
+		if place == nil || place.Status != types.InstructionStatusSuccess {
+			continue
+		}
+		replacement := *o
+		replacement.price = params.Instructions[i].NewPrice
+		replacement.day = m.day
+		replacement.gone = 0
+		replacement.remaining = cancel.SizeCancelled.Sub(place.SizeMatched)
+		replacement.held = liability(o.side, replacement.price, cancel.SizeCancelled)
+		m.add(exposure{
+			marketId:  o.marketId,
+			eventId:   o.eventId,
+			strategy:  o.strategy,
+			day:       m.day,
+			liability: replacement.held,
+		})
+		m.open++
+		if replacement.remaining > 0 {
+			m.orders[place.BetId] = &replacement
+		} else {
+			m.finish(&replacement)
+		}
+	}
+}
+
+func (m *Manager) checkBlocked() error {
+	if m.killed {
+		return ErrKillSwitch
+	}
+	m.roll()
+	if m.Limits.DailyLossLimit > 0 && m.dailyPnL.Neg() >= m.Limits.DailyLossLimit {
+		return &LimitError{Limit: LimitDailyLoss, Scope: m.day, Current: m.dailyPnL.Neg(), Max: m.Limits.DailyLossLimit}
+	}
+	return nil
+}
+
+func (m *Manager) checkExposure(e exposure) error {
+	checks := []struct {
+		limit   string
+		scope   string
+		current types.Decimal
+		max     types.Decimal
+	}{
+		{LimitMarketLiability, e.marketId, m.markets[e.marketId], m.Limits.MaxMarketLiability},
+		{LimitEventLiability, e.eventId, m.events[e.eventId], m.Limits.MaxEventLiability},
+		{LimitStrategyLiability, e.strategy, m.strategy[e.strategy], m.Limits.MaxStrategyLiability},
+		{LimitDailyLiability, m.day, m.daily, m.Limits.MaxDailyLiability},
+	}
+	for _, check := range checks {
+		if check.max > 0 && check.current.Add(e.liability) > check.max {
+			return &LimitError{Limit: check.limit, Scope: check.scope, Current: check.current, Requested: e.liability, Max: check.max}
+		}
+	}
+	return nil
+}
+
+func (m *Manager) add(e exposure) {
+	m.markets[e.marketId] = m.markets[e.marketId].Add(e.liability)
+	if e.eventId != "" {
+		m.events[e.eventId] = m.events[e.eventId].Add(e.liability)
+	}
+	m.strategy[e.strategy] = m.strategy[e.strategy].Add(e.liability)
+	if e.day == m.day {
+		m.daily = m.daily.Add(e.liability)
+	}
+}
+
+// reduce releases size cancelled from an order's unmatched remainder
+func (m *Manager) reduce(betId string, o *order, size types.Decimal) {
+	if size <= 0 {
+		return
+	}
+	if size > o.remaining {
+		size = o.remaining
+	}
+	m.release(o, size)
+	o.remaining = o.remaining.Sub(size)
+	if o.remaining <= 0 {
+		delete(m.orders, betId)
+		m.finish(o)
+	}
+}
+
+// finish stops counting an order as open. Liability still held for its
+// matched size waits for the market to settle
+func (m *Manager) finish(o *order) {
+	m.open--
+	if o.held > 0 {
+		m.settling[o.marketId] = append(m.settling[o.marketId], o)
+	}
+}
+
+// settle releases everything still counted against a market that has closed.
+// Orders left open on it have lapsed
+func (m *Manager) settle(marketId string) {
+	for _, o := range m.settling[marketId] {
+		m.releaseHeld(o)
+	}
+	delete(m.settling, marketId)
+	for betId, o := range m.orders {
+		if o.marketId == marketId {
+			m.releaseHeld(o)
+			delete(m.orders, betId)
+			m.open--
+		}
+	}
+}
+
+func (m *Manager) releaseHeld(o *order) {
+	m.add(exposure{marketId: o.marketId, eventId: o.eventId, strategy: o.strategy, day: o.day, liability: o.held}.negate())
+	o.held = 0
+}
+
+// release gives back the liability of size that is no longer at risk
+func (m *Manager) release(o *order, size types.Decimal) {
+	if size <= 0 {
+		return
+	}
+	released := size
+	if o.orderType == types.OrderTypeLimit {
+		released = liability(o.side, o.price, size)
+	}
+	m.add(exposure{marketId: o.marketId, eventId: o.eventId, strategy: o.strategy, day: o.day, liability: released}.negate())
+	o.gone = o.gone.Add(size)
+	o.held = o.held.Sub(released)
+}
+
+// roll starts a new day's liability and loss totals at midnight UTC. Exposure
+// reserved the day before is released from the other scopes but no longer
+// from the daily total
+func (m *Manager) roll() {
+	day := m.Now().UTC().Format("2006-01-02")
+	if day != m.day {
+		m.day = day
+		m.daily = 0
+		m.dailyPnL = 0
+		m.cleared = map[string]bool{}
+	}
+}
+
+func (m *Manager) lookupEvent(marketId string) (string, error) {
+	m.mu.Lock()
+	eventId, ok := m.eventById[marketId]
+	m.mu.Unlock()
+	if ok {
+		return eventId, nil
+	}
+
+	catalogue, err := m.API.ListMarketCatalogue(&types.MarketFilter{MarketIds: []string{marketId}}, 1, []string{"EVENT"})
+	if err != nil {
+		return "", err
+	}
+	if len(catalogue) == 0 || catalogue[0].Event == nil {
+		return "", fmt.Errorf("no event found for market %s", marketId)
+	}
+	m.mu.Lock()
+	m.eventById[marketId] = catalogue[0].Event.ID
+	m.mu.Unlock()
+	return catalogue[0].Event.ID, nil
+}
+
+func (e exposure) negate() exposure {
+	e.liability = e.liability.Neg()
+	return e
+}
+
+func stake(instruction *types.PlaceInstruction) types.Decimal {
+	switch {
+	case instruction.LimitOrder != nil:
+		return instruction.LimitOrder.Size
+	case instruction.Side == types.SideBack:
+		return Liability(instruction)
+	}
+	return 0
+}
@@ -0,0 +1,580 @@
+package risk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/transport"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type bookSource struct {
+	books []types.MarketBookWrapper
+}
+
+func (s *bookSource) MarketBooks(marketIds []string) ([]types.MarketBookWrapper, error) {
+	var books []types.MarketBookWrapper
+	for _, book := range s.books {
+		for _, id := range marketIds {
+			if book.MarketId == id {
+				books = append(books, book)
+			}
+		}
+	}
+	return books, nil
+}
+
+func d(s string) types.Decimal { return types.MustParseDecimal(s) }
+
+func book(marketId string) types.MarketBookWrapper {
+	return types.MarketBookWrapper{
+		MarketId: marketId,
+		Status:   types.MarketStatusOpen,
+		Runners: []types.Runner{
+			{SelectionID: 1, Status: types.RunnerStatusActive, Exchange: types.ExchangePrices{
+				AvailableToBack: []types.Odds{{Price: d("3"), Size: d("100")}},
+				AvailableToLay:  []types.Odds{{Price: d("3.1"), Size: d("100")}},
+			}},
+		},
+	}
+}
+
+func newGuardedAPI(limits Limits) (*betting.API, *Manager) {
+	api, m, _ := newFailingAPI(limits)
+	return api, m
+}
+
+// newFailingAPI guards a simulated exchange whose placeOrders responses the
+// failingTransport can replace
+func newFailingAPI(limits Limits) (*betting.API, *Manager, *failingTransport) {
+	source := &bookSource{books: []types.MarketBookWrapper{book("1.1"), book("1.2"), book("1.3")}}
+	failing := &failingTransport{TransportInterface: transport.NewSimulatedTransport(source, nil)}
+	api, m := NewAPI(failing, limits)
+	m.EventOf = func(marketId string) (string, error) {
+		if marketId == "1.3" {
+			return "e2", nil
+		}
+		return "e1", nil
+	}
+	return api, m, failing
+}
+
+// limit prices away from the book so orders rest unmatched
+func limit(marketId, strategy, side, price, size string) *types.PlaceInstructionParams {
+	return &types.PlaceInstructionParams{
+		MarketID:            marketId,
+		CustomerStrategyRef: strategy,
+		Instructions: []types.PlaceInstruction{{
+			OrderType:   types.OrderTypeLimit,
+			SelectionId: 1,
+			Side:        side,
+			LimitOrder:  &types.LimitOrder{Price: d(price), Size: d(size), PersistanceType: types.PersistenceLapse},
+		}},
+	}
+}
+
+func TestLiability(t *testing.T) {
+	tests := []struct {
+		name        string
+		instruction types.PlaceInstruction
+		want        string
+	}{
+		{"back", types.PlaceInstruction{Side: types.SideBack, LimitOrder: &types.LimitOrder{Price: d("4"), Size: d("10")}}, "10"},
+		{"lay", types.PlaceInstruction{Side: types.SideLay, LimitOrder: &types.LimitOrder{Price: d("4"), Size: d("10")}}, "30"},
+		{"lay SP", types.PlaceInstruction{Side: types.SideLay, MarketOnCloseOrder: &types.MarketOnCloseOrder{Liability: d("25")}}, "25"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Liability(&tt.instruction); got != d(tt.want) {
+				t.Errorf("Liability() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_Limits(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits Limits
+		// placed are accepted before order is checked
+		placed []*types.PlaceInstructionParams
+		order  *types.PlaceInstructionParams
+		limit  string
+	}{
+		{
+			name:   "order stake",
+			limits: Limits{MaxOrderStake: d("50")},
+			order:  limit("1.1", "s", types.SideBack, "10", "60"),
+			limit:  LimitOrderStake,
+		},
+		{
+			name:   "market liability from a lay",
+			limits: Limits{MaxMarketLiability: d("100")},
+			placed: []*types.PlaceInstructionParams{limit("1.1", "s", types.SideBack, "10", "50")},
+			order:  limit("1.1", "s", types.SideLay, "2", "60"),
+			limit:  LimitMarketLiability,
+		},
+		{
+			name:   "event liability across markets",
+			limits: Limits{MaxEventLiability: d("100")},
+			placed: []*types.PlaceInstructionParams{limit("1.1", "s", types.SideBack, "10", "60")},
+			order:  limit("1.2", "s", types.SideBack, "10", "60"),
+			limit:  LimitEventLiability,
+		},
+		{
+			name:   "strategy liability",
+			limits: Limits{MaxStrategyLiability: d("100")},
+			placed: []*types.PlaceInstructionParams{limit("1.1", "s", types.SideBack, "10", "60")},
+			order:  limit("1.3", "s", types.SideBack, "10", "60"),
+			limit:  LimitStrategyLiability,
+		},
+		{
+			name:   "daily liability",
+			limits: Limits{MaxDailyLiability: d("100")},
+			placed: []*types.PlaceInstructionParams{limit("1.1", "a", types.SideBack, "10", "60")},
+			order:  limit("1.3", "b", types.SideBack, "10", "60"),
+			limit:  LimitDailyLiability,
+		},
+		{
+			name:   "open orders",
+			limits: Limits{MaxOpenOrders: 1},
+			placed: []*types.PlaceInstructionParams{limit("1.1", "s", types.SideBack, "10", "2")},
+			order:  limit("1.1", "s", types.SideBack, "10", "2"),
+			limit:  LimitOpenOrders,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, _ := newGuardedAPI(tt.limits)
+			for _, params := range tt.placed {
+				if _, err := api.PlaceOrders(params); err != nil {
+					t.Fatal(err)
+				}
+			}
+			report, err := api.PlaceOrders(tt.order)
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Fatalf("PlaceOrders() = %v, %v, want limit error", report, err)
+			}
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit != tt.limit {
+				t.Errorf("error = %v, want %s", err, tt.limit)
+			}
+		})
+	}
+}
+
+func TestManager_CancelReleasesLiability(t *testing.T) {
+	api, m := newGuardedAPI(Limits{MaxMarketLiability: d("100"), MaxOpenOrders: 1})
+	report, err := api.PlaceOrders(limit("1.1", "s", types.SideLay, "2", "80"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.MarketLiability("1.1"); got != d("80") {
+		t.Fatalf("MarketLiability() = %s, want 80", got)
+	}
+	if _, err := api.PlaceOrders(limit("1.1", "s", types.SideBack, "10", "30")); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("PlaceOrders() error = %v, want limit error", err)
+	}
+
+	betId := report.InstructionReports[0].BetId
+	if _, err := api.CancelOrders(&types.CancelInstructionParams{
+		MarketID:     "1.1",
+		Instructions: []types.CancelInstruction{{BetId: betId, SizeReduction: d("50")}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.MarketLiability("1.1"); got != d("30") {
+		t.Fatalf("MarketLiability() after partial cancel = %s, want 30", got)
+	}
+
+	if _, err := api.CancelOrders(&types.CancelInstructionParams{MarketID: "1.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.MarketLiability("1.1"); got != 0 {
+		t.Fatalf("MarketLiability() after cancel = %s, want 0", got)
+	}
+	if _, err := api.PlaceOrders(limit("1.1", "s", types.SideBack, "10", "30")); err != nil {
+		t.Fatalf("PlaceOrders() after cancel error = %v", err)
+	}
+}
+
+func TestManager_Replace(t *testing.T) {
+	api, m := newGuardedAPI(Limits{MaxMarketLiability: d("100")})
+	report, err := api.PlaceOrders(limit("1.1", "s", types.SideLay, "2", "40"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	betId := report.InstructionReports[0].BetId
+
+	_, err = api.ReplaceOrders(&types.ReplaceInstructionParams{
+		MarketID:     "1.1",
+		Instructions: []types.ReplaceInstruction{{BetId: betId, NewPrice: d("4")}},
+	})
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("ReplaceOrders() error = %v, want limit error", err)
+	}
+
+	if _, err := api.ReplaceOrders(&types.ReplaceInstructionParams{
+		MarketID:     "1.1",
+		Instructions: []types.ReplaceInstruction{{BetId: betId, NewPrice: d("2.5")}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.MarketLiability("1.1"); got != d("60") {
+		t.Errorf("MarketLiability() after replace = %s, want 60", got)
+	}
+}
+
+func TestManager_KillSwitch(t *testing.T) {
+	api, m := newGuardedAPI(Limits{})
+	if _, err := api.PlaceOrders(limit("1.1", "s", types.SideBack, "10", "5")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.PlaceOrders(limit("1.3", "s", types.SideBack, "10", "5")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	current, err := api.ListCurrentOrders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, order := range current.Orders {
+		if order.SizeRemaining > 0 {
+			t.Errorf("order %s still open after kill: %s", order.BetId, order.SizeRemaining)
+		}
+	}
+	if _, err := api.PlaceOrders(limit("1.1", "s", types.SideBack, "10", "5")); err != ErrKillSwitch {
+		t.Fatalf("PlaceOrders() error = %v, want %v", err, ErrKillSwitch)
+	}
+
+	m.Reset()
+	if _, err := api.PlaceOrders(limit("1.1", "s", types.SideBack, "10", "5")); err != nil {
+		t.Fatalf("PlaceOrders() after reset error = %v", err)
+	}
+}
+
+func TestManager_DailyLossStop(t *testing.T) {
+	api, m := newGuardedAPI(Limits{DailyLossLimit: d("50")})
+	now := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	m.Now = func() time.Time { return now }
+
+	m.RecordProfit(d("-30"))
+	if _, err := api.PlaceOrders(limit("1.1", "s", types.SideBack, "10", "5")); err != nil {
+		t.Fatal(err)
+	}
+	m.RecordProfit(d("-25"))
+	var limitErr *LimitError
+	if _, err := api.PlaceOrders(limit("1.1", "s", types.SideBack, "10", "5")); !errors.As(err, &limitErr) || limitErr.Limit != LimitDailyLoss {
+		t.Fatalf("PlaceOrders() error = %v, want %s", err, LimitDailyLoss)
+	}
+
+	now = now.Add(8 * time.Hour)
+	if _, err := api.PlaceOrders(limit("1.1", "s", types.SideBack, "10", "5")); err != nil {
+		t.Fatalf("PlaceOrders() next day error = %v", err)
+	}
+}
+
+func TestManager_SyncOrdersKeepsMatchedLiability(t *testing.T) {
+	api, m := newGuardedAPI(Limits{MaxMarketLiability: d("200"), MaxOpenOrders: 1})
+	// 100 is offered at 3, so 100 of the 150 matches straight away
+	report, err := api.PlaceOrders(limit("1.1", "s", types.SideBack, "3", "150"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	betId := report.InstructionReports[0].BetId
+
+	steps := []struct {
+		name      string
+		order     types.CurrentOrder
+		liability string
+		open      bool
+	}{
+		{
+			name:      "more matches",
+			order:     types.CurrentOrder{Status: types.OrderStatusExecutable, SizeMatched: d("120"), SizeRemaining: d("30")},
+			liability: "150",
+			open:      true,
+		},
+		{
+			name:      "remainder lapses",
+			order:     types.CurrentOrder{Status: types.OrderStatusExecutionComplete, SizeMatched: d("120"), SizeLapsed: d("30")},
+			liability: "120",
+		},
+	}
+	for _, step := range steps {
+		step.order.BetId = betId
+		step.order.MarketId = "1.1"
+		m.SyncOrders(&types.CurrentOrdersWrapper{Orders: []types.CurrentOrder{step.order}})
+		if got := m.MarketLiability("1.1"); got != d(step.liability) {
+			t.Errorf("%s: MarketLiability() = %s, want %s", step.name, got, step.liability)
+		}
+		_, err := api.PlaceOrders(limit("1.2", "t", types.SideBack, "10", "2"))
+		var limitErr *LimitError
+		if step.open && (!errors.As(err, &limitErr) || limitErr.Limit != LimitOpenOrders) {
+			t.Errorf("%s: PlaceOrders() error = %v, want %s", step.name, err, LimitOpenOrders)
+		}
+		if !step.open && err != nil {
+			t.Errorf("%s: PlaceOrders() error = %v", step.name, err)
+		}
+	}
+
+	// Matched size still counts against the market
+	if _, err := api.PlaceOrders(limit("1.1", "s", types.SideBack, "10", "90")); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("PlaceOrders() over matched liability error = %v, want limit error", err)
+	}
+}
+
+type failingTransport struct {
+	types.TransportInterface
+	err error
+	// body replaces the placeOrders response when set
+	body []byte
+	// cancelErr is returned once cancelOrders has been carried out
+	cancelErr error
+}
+
+func (f *failingTransport) Do(id int, method string, filter *types.MarketFilter, additionalParams interface{}) ([]byte, error) {
+	if method == "placeOrders" && f.err != nil {
+		return nil, f.err
+	}
+	if method == "placeOrders" && f.body != nil {
+		return f.body, nil
+	}
+	if method == "cancelOrders" && f.cancelErr != nil {
+		if _, err := f.TransportInterface.Do(id, method, filter, additionalParams); err != nil {
+			return nil, err
+		}
+		return nil, f.cancelErr
+	}
+	return f.TransportInterface.Do(id, method, filter, additionalParams)
+}
+
+func TestManager_Failed(t *testing.T) {
+	api, m, failing := newFailingAPI(Limits{MaxOpenOrders: 2})
+	// The listings below stand in for the exchange's
+	m.SyncInterval = 0
+	now := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	m.Now = func() time.Time { return now }
+	withRef := func(ref string) *types.PlaceInstructionParams {
+		params := limit("1.1", "s", types.SideBack, "10", "10")
+		params.Instructions[0].CustomerOrderRef = ref
+		return params
+	}
+
+	failing.err = &transport.RPCError{Code: -32099, Message: "ANGX-0003"}
+	for i := 0; i < 3; i++ {
+		if _, err := api.PlaceOrders(withRef("")); !betting.Rejected(err) {
+			t.Fatalf("PlaceOrders() error = %v, want a rejection", err)
+		}
+	}
+	if got := m.MarketLiability("1.1"); got != 0 {
+		t.Fatalf("MarketLiability() after rejections = %s, want 0", got)
+	}
+
+	// The outcome of these two is unknown, so they fill the open order limit
+	failing.err = errors.New("context deadline exceeded")
+	for _, ref := range []string{"found", "missing"} {
+		if _, err := api.PlaceOrders(withRef(ref)); err == nil || betting.Rejected(err) {
+			t.Fatalf("PlaceOrders() error = %v, want an unknown outcome", err)
+		}
+	}
+	if got := m.MarketLiability("1.1"); got != d("20") {
+		t.Fatalf("MarketLiability() with unknown outcomes = %s, want 20", got)
+	}
+
+	listing := &types.CurrentOrdersWrapper{Orders: []types.CurrentOrder{{
+		BetId: "99", MarketId: "1.1", Status: types.OrderStatusExecutable, SizeRemaining: d("10"), CustomerOrderRef: "found",
+	}}}
+	m.SyncOrders(listing)
+	if got := m.MarketLiability("1.1"); got != d("20") {
+		t.Fatalf("MarketLiability() within the grace period = %s, want 20", got)
+	}
+
+	now = now.Add(DefaultUnknownGrace)
+	m.SyncOrders(listing)
+	if got := m.MarketLiability("1.1"); got != d("10") {
+		t.Fatalf("MarketLiability() after the grace period = %s, want 10", got)
+	}
+	failing.err = nil
+	if _, err := api.PlaceOrders(withRef("")); err != nil {
+		t.Fatalf("PlaceOrders() with one open order error = %v", err)
+	}
+	if _, err := api.PlaceOrders(withRef("")); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("PlaceOrders() error = %v, want the open order limit", err)
+	}
+
+	// The order that was found is tracked by its bet id from now on
+	listing.Orders[0].Status = types.OrderStatusExecutionComplete
+	listing.Orders[0].SizeRemaining = 0
+	listing.Orders[0].SizeCancelled = d("10")
+	current, err := api.ListCurrentOrders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listing.Orders = append(listing.Orders, current.Orders...)
+	m.SyncOrders(listing)
+	if got := m.MarketLiability("1.1"); got != d("10") {
+		t.Errorf("MarketLiability() after the found order is cancelled = %s, want 10", got)
+	}
+}
+
+func TestManager_PlacedReconcilesEveryInstruction(t *testing.T) {
+	api, m, failing := newFailingAPI(Limits{MaxOpenOrders: 2})
+	two := limit("1.1", "s", types.SideBack, "10", "10")
+	two.Instructions = append(two.Instructions, two.Instructions[0])
+
+	bodies := []struct {
+		name string
+		body string
+	}{
+		{"market level failure", `{"status":"FAILURE","errorCode":"MARKET_SUSPENDED","marketId":"1.1"}`},
+		{"fewer reports than instructions", `{"status":"SUCCESS","marketId":"1.1","instructionReports":[{"status":"SUCCESS","betId":"7","orderStatus":"EXECUTION_COMPLETE","sizeMatched":10}]}`},
+	}
+	for _, tt := range bodies {
+		failing.body = []byte(tt.body)
+		if _, err := api.PlaceOrders(two); err != nil {
+			t.Fatalf("%s: PlaceOrders() error = %v", tt.name, err)
+		}
+	}
+	if got := m.MarketLiability("1.1"); got != d("10") {
+		t.Fatalf("MarketLiability() = %s, want only the matched 10", got)
+	}
+
+	failing.body = []byte(`null`)
+	if _, err := api.PlaceOrders(two); !errors.Is(err, betting.ErrNoReport) {
+		t.Fatalf("PlaceOrders() error = %v, want %v", err, betting.ErrNoReport)
+	}
+	if got := m.MarketLiability("1.1"); got != d("30") {
+		t.Errorf("MarketLiability() with no report = %s, want the reservation kept at 30", got)
+	}
+}
+
+func TestManager_DailyLiabilityAcrossMidnight(t *testing.T) {
+	api, m := newGuardedAPI(Limits{MaxDailyLiability: d("100")})
+	now := time.Date(2020, 6, 1, 23, 0, 0, 0, time.UTC)
+	m.Now = func() time.Time { return now }
+	if _, err := api.PlaceOrders(limit("1.1", "s", types.SideLay, "2", "80")); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := api.PlaceOrders(limit("1.2", "s", types.SideBack, "10", "10")); err != nil {
+		t.Fatal(err)
+	}
+	// Yesterday's 80 comes off the market but not today's total
+	if _, err := api.CancelOrders(&types.CancelInstructionParams{MarketID: "1.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.PlaceOrders(limit("1.2", "s", types.SideBack, "10", "90")); err != nil {
+		t.Fatalf("PlaceOrders() error = %v", err)
+	}
+	var limitErr *LimitError
+	if _, err := api.PlaceOrders(limit("1.2", "s", types.SideBack, "10", "10")); !errors.As(err, &limitErr) || limitErr.Limit != LimitDailyLiability {
+		t.Errorf("PlaceOrders() error = %v, want %s", err, LimitDailyLiability)
+	}
+}
+
+func TestManager_SyncSettlesMarkets(t *testing.T) {
+	source := &bookSource{books: []types.MarketBookWrapper{book("1.1"), book("1.2")}}
+	api, m := NewAPI(transport.NewSimulatedTransport(source, nil), Limits{MaxStrategyLiability: d("100"), DailyLossLimit: d("50")})
+	m.EventOf = func(string) (string, error) { return "e1", nil }
+
+	// Both match in full at 3
+	if _, err := api.PlaceOrders(limit("1.1", "s", types.SideBack, "3", "60")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.PlaceOrders(limit("1.2", "s", types.SideBack, "3", "30")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.MarketLiability("1.1"); got != d("60") {
+		t.Fatalf("MarketLiability() before settlement = %s, want 60", got)
+	}
+
+	m.SyncMarkets([]types.MarketBookWrapper{{MarketId: "1.2", Status: types.MarketStatusClosed}})
+	if got := m.MarketLiability("1.2"); got != 0 {
+		t.Fatalf("MarketLiability() of a closed market = %s, want 0", got)
+	}
+
+	source.books[0].Status = types.MarketStatusClosed
+	source.books[0].Runners[0].Status = types.RunnerStatusLoser
+	for i := 0; i < 2; i++ {
+		if err := m.Sync(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := m.MarketLiability("1.1"); got != 0 {
+		t.Errorf("MarketLiability() after settlement = %s, want 0", got)
+	}
+	var limitErr *LimitError
+	_, err := api.PlaceOrders(limit("1.2", "s", types.SideBack, "10", "5"))
+	if !errors.As(err, &limitErr) || limitErr.Limit != LimitDailyLoss || limitErr.Current != d("60") {
+		t.Errorf("PlaceOrders() error = %v, want %s at 60", err, LimitDailyLoss)
+	}
+}
+
+func TestManager_SyncsItself(t *testing.T) {
+	now := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	source := &bookSource{books: []types.MarketBookWrapper{book("1.1"), book("1.2")}}
+	sim := transport.NewSimulatedTransport(source, nil)
+	sim.Now = clock
+	api, m := NewAPI(sim, Limits{DailyLossLimit: d("50")})
+	m.Now = clock
+
+	// Matches in full at 3 and then loses
+	if _, err := api.PlaceOrders(limit("1.1", "s", types.SideBack, "3", "60")); err != nil {
+		t.Fatal(err)
+	}
+	source.books[0].Status = types.MarketStatusClosed
+	source.books[0].Runners[0].Status = types.RunnerStatusLoser
+
+	// The loss is not known until the next sync is due
+	now = now.Add(DefaultSyncInterval / 2)
+	if _, err := api.PlaceOrders(limit("1.2", "s", types.SideBack, "10", "5")); err != nil {
+		t.Fatalf("PlaceOrders() before the sync is due error = %v", err)
+	}
+	now = now.Add(DefaultSyncInterval)
+	var limitErr *LimitError
+	_, err := api.PlaceOrders(limit("1.2", "s", types.SideBack, "10", "5"))
+	if !errors.As(err, &limitErr) || limitErr.Limit != LimitDailyLoss || limitErr.Current != d("60") {
+		t.Errorf("PlaceOrders() error = %v, want %s at 60", err, LimitDailyLoss)
+	}
+	if got := m.MarketLiability("1.1"); got != 0 {
+		t.Errorf("MarketLiability() of the settled market = %s, want 0", got)
+	}
+}
+
+func TestManager_UnsureCancelSyncs(t *testing.T) {
+	api, m, failing := newFailingAPI(Limits{})
+	now := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	m.Now = func() time.Time { return now }
+	report, err := api.PlaceOrders(limit("1.1", "s", types.SideBack, "10", "10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The cancel reaches the exchange but its response is lost
+	failing.cancelErr = errors.New("context deadline exceeded")
+	if _, err := api.CancelOrders(&types.CancelInstructionParams{MarketID: "1.1"}); err == nil {
+		t.Fatal("CancelOrders() error = nil")
+	}
+	if got := m.MarketLiability("1.1"); got != d("10") {
+		t.Fatalf("MarketLiability() after an unsure cancel = %s, want 10", got)
+	}
+
+	// The next check syncs and finds the order cancelled
+	if _, err := api.PlaceOrders(limit("1.2", "s", types.SideBack, "10", "10")); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.MarketLiability("1.1"); got != 0 {
+		t.Errorf("MarketLiability() of %s after the sync = %s, want 0", report.BetId, got)
+	}
+}
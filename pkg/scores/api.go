@@ -0,0 +1,93 @@
+package scores
+
+import (
+	"encoding/json"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	scoresId = 1
+)
+
+type (
+	API struct {
+		Client types.TransportInterface
+	}
+
+	APIInterface interface {
+		ListRaceDetails(meetingIds, raceIds []string) ([]types.RaceDetails, error)
+		ListAvailableEvents(eventIds, eventTypeIds, eventStatus []string) ([]types.AvailableEvent, error)
+		ListScores(updateKeys []types.UpdateKey) ([]types.EventScore, error)
+		ListIncidents(updateKeys []types.UpdateKey) ([]types.EventIncidents, error)
+	}
+)
+
+func NewAPI(client types.TransportInterface) *API {
+	return &API{
+		Client: client,
+	}
+}
+
+func (a *API) ListRaceDetails(meetingIds, raceIds []string) ([]types.RaceDetails, error) {
+	buf, err := a.Client.Call(scoresId, "ScoresAPING/v1.0/listRaceDetails", &types.RaceDetailsParams{
+		MeetingIds: meetingIds,
+		RaceIds:    raceIds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.RaceDetails
+	if err := json.Unmarshal(buf, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (a *API) ListAvailableEvents(eventIds, eventTypeIds, eventStatus []string) ([]types.AvailableEvent, error) {
+	buf, err := a.Client.Call(scoresId, "ScoresAPING/v1.0/listAvailableEvents", &types.AvailableEventsParams{
+		EventIds:     eventIds,
+		EventTypeIds: eventTypeIds,
+		EventStatus:  eventStatus,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.AvailableEvent
+	if err := json.Unmarshal(buf, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (a *API) ListScores(updateKeys []types.UpdateKey) ([]types.EventScore, error) {
+	buf, err := a.Client.Call(scoresId, "ScoresAPING/v1.0/listScores", &types.UpdateKeysParams{
+		UpdateKeys: updateKeys,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.EventScore
+	if err := json.Unmarshal(buf, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (a *API) ListIncidents(updateKeys []types.UpdateKey) ([]types.EventIncidents, error) {
+	buf, err := a.Client.Call(scoresId, "ScoresAPING/v1.0/listIncidents", &types.UpdateKeysParams{
+		UpdateKeys: updateKeys,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.EventIncidents
+	if err := json.Unmarshal(buf, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package scores
+
+import (
+	"sync"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type (
+	// Feed correlates Betting API events with their live score feeds and keeps
+	// the latest score for each, asking only for updates it has not yet seen
+	Feed struct {
+		API APIInterface
+
+		mu        sync.Mutex
+		sequences map[string]int64
+		scores    map[string]*types.EventScore
+	}
+)
+
+func NewFeed(api APIInterface) *Feed {
+	return &Feed{
+		API:       api,
+		sequences: map[string]int64{},
+		scores:    map[string]*types.EventScore{},
+	}
+}
+
+// Track registers the events that have a score feed and returns them, leaving
+// out events the Scores API does not cover
+func (f *Feed) Track(events []types.EventWrapper) ([]types.EventWrapper, error) {
+	ids := make([]string, 0, len(events))
+	for _, event := range events {
+		if event.Event != nil {
+			ids = append(ids, event.Event.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	available, err := f.API.ListAvailableEvents(ids, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	covered := map[string]bool{}
+	for _, event := range available {
+		covered[string(event.EventId)] = true
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var tracked []types.EventWrapper
+	for _, event := range events {
+		if event.Event == nil || !covered[event.Event.ID] {
+			continue
+		}
+		if _, ok := f.sequences[event.Event.ID]; !ok {
+			f.sequences[event.Event.ID] = 0
+		}
+		tracked = append(tracked, event)
+	}
+	return tracked, nil
+}
+
+// Refresh fetches new scores for every tracked event and returns the events
+// whose score changed
+func (f *Feed) Refresh() (map[string]*types.EventScore, error) {
+	f.mu.Lock()
+	keys := make([]types.UpdateKey, 0, len(f.sequences))
+	for id, sequence := range f.sequences {
+		keys = append(keys, types.UpdateKey{EventId: id, LastUpdateSequenceProcessed: sequence})
+	}
+	f.mu.Unlock()
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	scores, err := f.API.ListScores(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	changed := map[string]*types.EventScore{}
+	for i := range scores {
+		score := scores[i]
+		if score.ResponseCode != "" && score.ResponseCode != types.ScoresResponseOK {
+			continue
+		}
+		id := string(score.EventId)
+		if score.UpdateContext != nil {
+			f.sequences[id] = score.UpdateContext.UpdateSequence
+		}
+		f.scores[id] = &score
+		changed[id] = &score
+	}
+	return changed, nil
+}
+
+// Score returns the latest score for an event ID taken from an EventWrapper
+func (f *Feed) Score(eventId string) *types.EventScore {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.scores[eventId]
+}
+
+// Incidents returns all incidents recorded so far for an event
+func (f *Feed) Incidents(eventId string) ([]types.Incident, error) {
+	result, err := f.API.ListIncidents([]types.UpdateKey{{EventId: eventId}})
+	if err != nil {
+		return nil, err
+	}
+	var incidents []types.Incident
+	for _, event := range result {
+		if string(event.EventId) == eventId {
+			incidents = append(incidents, event.Values...)
+		}
+	}
+	return incidents, nil
+}
@@ -0,0 +1,68 @@
+package scores
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type fakeTransport struct {
+	types.TransportInterface
+	results map[string]string
+	params  map[string]interface{}
+}
+
+func (f *fakeTransport) Call(id int, method string, params interface{}) ([]byte, error) {
+	f.params[method] = params
+	return []byte(f.results[method]), nil
+}
+
+func TestFeed(t *testing.T) {
+	transport := &fakeTransport{
+		params: map[string]interface{}{},
+		results: map[string]string{
+			"ScoresAPING/v1.0/listAvailableEvents": `[{"eventId":29001,"eventTypeId":1,"eventStatus":"IN_PLAY"}]`,
+			"ScoresAPING/v1.0/listScores": `[{"eventId":29001,"eventTypeId":1,"eventStatus":"IN_PLAY","responseCode":"OK",
+				"updateContext":{"updateSequence":7},"matchStatus":"SecondHalfKickOff","timeElapsed":61,
+				"score":{"home":{"name":"Arsenal","score":"2","halfTimeScore":"1","numberOfYellowCards":1},
+				"away":{"name":"Chelsea","score":"1","halfTimeScore":"1","numberOfRedCards":1}}}]`,
+		},
+	}
+	feed := NewFeed(NewAPI(transport))
+
+	events := []types.EventWrapper{
+		{Event: &types.Detail{ID: "29001", Name: "Arsenal v Chelsea"}},
+		{Event: &types.Detail{ID: "29002", Name: "Spurs v Everton"}},
+	}
+	tracked, err := feed.Track(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracked) != 1 || tracked[0].Event.ID != "29001" {
+		t.Fatalf("Track() = %v, want only 29001", tracked)
+	}
+
+	if _, err := feed.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	got := feed.Score("29001").Football()
+	want := types.FootballScore{
+		Home:        types.FootballTeam{Name: "Arsenal", Goals: 2, HalfTimeGoals: 1, YellowCards: 1},
+		Away:        types.FootballTeam{Name: "Chelsea", Goals: 1, HalfTimeGoals: 1, RedCards: 1},
+		MatchStatus: "SecondHalfKickOff",
+		Elapsed:     61,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Football() = %+v, want %+v", got, want)
+	}
+
+	if _, err := feed.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	sent, _ := json.Marshal(transport.params["ScoresAPING/v1.0/listScores"])
+	if string(sent) != `{"updateKeys":[{"eventId":"29001","lastUpdateSequenceProcessed":7}]}` {
+		t.Errorf("second listScores params = %s", sent)
+	}
+}
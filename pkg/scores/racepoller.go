@@ -0,0 +1,121 @@
+package scores
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	DefaultRacePollInterval = time.Second
+)
+
+type (
+	RaceTransition struct {
+		MeetingId   string
+		RaceId      string
+		From        types.RaceStatus
+		To          types.RaceStatus
+		LastUpdated string
+	}
+
+	// RacePoller polls listRaceDetails and reports each change of race status.
+	// The first status seen for a race is reported with an empty From
+	RacePoller struct {
+		API        APIInterface
+		MeetingIds []string
+		RaceIds    []string
+		Interval   time.Duration
+
+		mu       sync.Mutex
+		statuses map[string]types.RaceStatus
+	}
+)
+
+func NewRacePoller(api APIInterface, meetingIds, raceIds []string) *RacePoller {
+	return &RacePoller{
+		API:        api,
+		MeetingIds: meetingIds,
+		RaceIds:    raceIds,
+		Interval:   DefaultRacePollInterval,
+		statuses:   map[string]types.RaceStatus{},
+	}
+}
+
+// Poll makes a single listRaceDetails request and returns the transitions since the last poll
+func (p *RacePoller) Poll() ([]RaceTransition, error) {
+	details, err := p.API.ListRaceDetails(p.MeetingIds, p.RaceIds)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.statuses == nil {
+		p.statuses = map[string]types.RaceStatus{}
+	}
+
+	var transitions []RaceTransition
+	for _, race := range details {
+		if race.RaceStatus == "" {
+			continue
+		}
+		previous, seen := p.statuses[race.RaceId]
+		if seen && previous == race.RaceStatus {
+			continue
+		}
+		p.statuses[race.RaceId] = race.RaceStatus
+		transitions = append(transitions, RaceTransition{
+			MeetingId:   race.MeetingId,
+			RaceId:      race.RaceId,
+			From:        previous,
+			To:          race.RaceStatus,
+			LastUpdated: race.LastUpdated,
+		})
+	}
+	return transitions, nil
+}
+
+// Run polls until ctx is done, sending transitions on the channel. Poll errors
+// are sent to errs when it is non-nil and polling carries on
+func (p *RacePoller) Run(ctx context.Context, transitions chan<- RaceTransition, errs chan<- error) error {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultRacePollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		changes, err := p.Poll()
+		if err != nil && errs != nil {
+			select {
+			case errs <- err:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		for _, change := range changes {
+			select {
+			case transitions <- change:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Status returns the last known status of a race
+func (p *RacePoller) Status(raceId string) types.RaceStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.statuses[raceId]
+}
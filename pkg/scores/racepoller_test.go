@@ -0,0 +1,51 @@
+package scores
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type scriptedRaces struct {
+	APIInterface
+	rounds [][]types.RaceDetails
+}
+
+func (s *scriptedRaces) ListRaceDetails(meetingIds, raceIds []string) ([]types.RaceDetails, error) {
+	round := s.rounds[0]
+	if len(s.rounds) > 1 {
+		s.rounds = s.rounds[1:]
+	}
+	return round, nil
+}
+
+func TestRacePoller_Poll(t *testing.T) {
+	api := &scriptedRaces{rounds: [][]types.RaceDetails{
+		{{MeetingId: "m1", RaceId: "r1", RaceStatus: types.RaceStatusAtThePost}},
+		{{MeetingId: "m1", RaceId: "r1", RaceStatus: types.RaceStatusAtThePost}},
+		{{MeetingId: "m1", RaceId: "r1", RaceStatus: types.RaceStatusUnderOrders}, {MeetingId: "m1", RaceId: "r2", ResponseCode: types.ScoresResponseNoLiveDataAvailable}},
+	}}
+	poller := NewRacePoller(api, []string{"m1"}, nil)
+
+	want := [][]RaceTransition{
+		{{MeetingId: "m1", RaceId: "r1", To: types.RaceStatusAtThePost}},
+		nil,
+		{{MeetingId: "m1", RaceId: "r1", From: types.RaceStatusAtThePost, To: types.RaceStatusUnderOrders}},
+	}
+	for i, w := range want {
+		got, err := poller.Poll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("Poll() round %d = %v, want %v", i, got, w)
+		}
+	}
+	if !poller.Status("r1").AtOrAfter(types.RaceStatusUnderOrders) {
+		t.Errorf("Status() = %s, want at or after UNDERORDERS", poller.Status("r1"))
+	}
+	if types.RaceStatusParading.AtOrAfter(types.RaceStatusUnderOrders) {
+		t.Errorf("PARADING should not be at or after UNDERORDERS")
+	}
+}
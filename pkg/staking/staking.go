@@ -0,0 +1,211 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/guysports/go-betfair-api/pkg/account"
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+var (
+	ErrNoEdge             = errors.New("model probability gives no edge at this price")
+	ErrInvalidPrice       = errors.New("price must be greater than 1")
+	ErrInvalidBankroll    = errors.New("bankroll must be positive")
+	ErrUnsupportedPlan    = errors.New("plan does not support this side")
+	ErrInvalidFraction    = errors.New("fraction must be between 0 and 1")
+	ErrInvalidPercent     = errors.New("percent must be between 0 and 100")
+	ErrInvalidProbability = errors.New("probability must be between 0 and 1")
+)
+
+type (
+	// Bet describes the order a plan sizes. Probability is the model's chance
+	// of the selection winning and is only needed by Kelly
+	Bet struct {
+		Side        string
+		Price       types.Decimal
+		Probability float64
+		// Currency selects the minimum stake rules, GBP when empty
+		Currency string
+	}
+
+	// Plan sizes a limit order. Stakes are rounded down to the penny and
+	// checked against the currency's minimum bet rules
+	Plan interface {
+		Stake(bet Bet) (types.Decimal, error)
+	}
+
+	// Bankroll supplies the balance percentage and Kelly plans stake from
+	Bankroll interface {
+		Balance() (types.Decimal, error)
+	}
+
+	// FixedBankroll is a bankroll that never changes, for backtests
+	FixedBankroll types.Decimal
+
+	// AccountBankroll reads the available to bet balance from the account API
+	AccountBankroll struct {
+		API    *account.API
+		Wallet string
+	}
+
+	// Fixed stakes the same amount on every bet
+	Fixed struct {
+		Amount types.Decimal
+	}
+
+	// Percentage stakes a percentage of the bankroll. For lays the percentage
+	// is the liability risked rather than the stake
+	Percentage struct {
+		Bankroll Bankroll
+		Percent  types.Decimal
+	}
+
+	// Kelly stakes a fraction of the Kelly criterion from the model probability
+	// and the price. For lays the Kelly fraction sizes the liability
+	Kelly struct {
+		Bankroll Bankroll
+		Fraction float64
+		// Max caps the stake when positive
+		Max types.Decimal
+	}
+
+	// LevelProfit stakes so that the bet wins Profit: a back wins its stake
+	// times the price less one and a lay wins its stake
+	LevelProfit struct {
+		Profit types.Decimal
+	}
+
+	// LevelLiability stakes so that the bet risks Liability: a lay risks its
+	// stake times the price less one and a back risks its stake
+	LevelLiability struct {
+		Liability types.Decimal
+	}
+)
+
+func (b FixedBankroll) Balance() (types.Decimal, error) {
+	return types.Decimal(b), nil
+}
+
+func (b *AccountBankroll) Balance() (types.Decimal, error) {
+	funds, err := b.API.GetAccountFunds(b.Wallet)
+	if err != nil {
+		return 0, err
+	}
+	return funds.AvailableToBetBalance, nil
+}
+
+func (p *Fixed) Stake(bet Bet) (types.Decimal, error) {
+	return finish(p.Amount, bet)
+}
+
+func (p *Percentage) Stake(bet Bet) (types.Decimal, error) {
+	if p.Percent <= 0 || p.Percent > types.NewDecimalFromInt(100) {
+		return 0, ErrInvalidPercent
+	}
+	balance, err := balance(p.Bankroll)
+	if err != nil {
+		return 0, err
+	}
+	risk := balance.Mul(p.Percent).Div(types.NewDecimalFromInt(100))
+	return stakeForRisk(risk, bet)
+}
+
+func (p *Kelly) Stake(bet Bet) (types.Decimal, error) {
+	if p.Fraction <= 0 || p.Fraction > 1 {
+		return 0, ErrInvalidFraction
+	}
+	if bet.Probability <= 0 || bet.Probability >= 1 {
+		return 0, ErrInvalidProbability
+	}
+	if bet.Price <= types.NewDecimalFromInt(1) {
+		return 0, ErrInvalidPrice
+	}
+	f := KellyFraction(bet.Side, bet.Price, bet.Probability)
+	if f <= 0 {
+		return 0, ErrNoEdge
+	}
+	balance, err := balance(p.Bankroll)
+	if err != nil {
+		return 0, err
+	}
+	stake, err := stakeForRisk(balance.Mul(types.NewDecimal(f*p.Fraction)), bet)
+	if err != nil {
+		return 0, err
+	}
+	if p.Max > 0 && stake > p.Max {
+		return finish(p.Max, bet)
+	}
+	return stake, nil
+}
+
+func (p *LevelProfit) Stake(bet Bet) (types.Decimal, error) {
+	switch bet.Side {
+	case types.SideBack:
+		if bet.Price <= types.NewDecimalFromInt(1) {
+			return 0, ErrInvalidPrice
+		}
+		return finish(p.Profit.Div(bet.Price.Sub(types.NewDecimalFromInt(1))), bet)
+	case types.SideLay:
+		return finish(p.Profit, bet)
+	}
+	return 0, ErrUnsupportedPlan
+}
+
+func (p *LevelLiability) Stake(bet Bet) (types.Decimal, error) {
+	switch bet.Side {
+	case types.SideBack, types.SideLay:
+		return stakeForRisk(p.Liability, bet)
+	}
+	return 0, ErrUnsupportedPlan
+}
+
+// KellyFraction is the share of the bankroll full Kelly risks at the price.
+// A back risks its stake to win price - 1 times it; a lay risks price - 1
+// times its stake to win the stake
+func KellyFraction(side string, price types.Decimal, probability float64) float64 {
+	odds := price.Float64() - 1
+	if odds <= 0 {
+		return 0
+	}
+	if side == types.SideLay {
+		return (1 - probability) - probability*odds
+	}
+	return (probability*odds - (1 - probability)) / odds
+}
+
+func balance(bankroll Bankroll) (types.Decimal, error) {
+	balance, err := bankroll.Balance()
+	if err != nil {
+		return 0, err
+	}
+	if balance <= 0 {
+		return 0, ErrInvalidBankroll
+	}
+	return balance, nil
+}
+
+// stakeForRisk converts the amount a bet may lose into its stake
+func stakeForRisk(risk types.Decimal, bet Bet) (types.Decimal, error) {
+	switch bet.Side {
+	case types.SideBack:
+		return finish(risk, bet)
+	case types.SideLay:
+		if bet.Price <= types.NewDecimalFromInt(1) {
+			return 0, ErrInvalidPrice
+		}
+		return finish(risk.Div(bet.Price.Sub(types.NewDecimalFromInt(1))), bet)
+	}
+	return 0, betting.ErrInvalidSide
+}
+
+// finish rounds a stake down to the penny, so a plan never exceeds what it was
+// asked to risk, and applies the minimum bet rules
+func finish(stake types.Decimal, bet Bet) (types.Decimal, error) {
+	stake = stake.Truncate(types.MoneyPlaces)
+	if !types.RulesForCurrency(bet.Currency).MeetsMinimumStake(stake, bet.Price) {
+		return 0, fmt.Errorf("%w: %s", betting.ErrBelowMinimumStake, stake)
+	}
+	return stake, nil
+}
@@ -0,0 +1,68 @@
+package staking
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+func d(s string) types.Decimal { return types.MustParseDecimal(s) }
+
+func TestPlans(t *testing.T) {
+	bankroll := FixedBankroll(d("1000"))
+	tests := []struct {
+		name string
+		plan Plan
+		bet  Bet
+		want string
+		err  error
+	}{
+		{"fixed", &Fixed{Amount: d("5")}, Bet{Side: types.SideBack, Price: d("3")}, "5", nil},
+		{"fixed below minimum", &Fixed{Amount: d("0.5")}, Bet{Side: types.SideBack, Price: d("3")}, "0", betting.ErrBelowMinimumStake},
+		{"fixed below size but above payout", &Fixed{Amount: d("0.5")}, Bet{Side: types.SideBack, Price: d("25")}, "0.5", nil},
+		{"fixed USD minimum", &Fixed{Amount: d("2")}, Bet{Side: types.SideBack, Price: d("3"), Currency: "USD"}, "0", betting.ErrBelowMinimumStake},
+		{"percentage back", &Percentage{Bankroll: bankroll, Percent: d("2.5")}, Bet{Side: types.SideBack, Price: d("3")}, "25", nil},
+		{"percentage lay risks liability", &Percentage{Bankroll: bankroll, Percent: d("1")}, Bet{Side: types.SideLay, Price: d("4")}, "3.33", nil},
+		{"percentage out of range", &Percentage{Bankroll: bankroll, Percent: d("150")}, Bet{Side: types.SideBack, Price: d("3")}, "0", ErrInvalidPercent},
+		// Full Kelly at 3.0 with p=0.4 is (0.4*2-0.6)/2 = 10%
+		{"half kelly back", &Kelly{Bankroll: bankroll, Fraction: 0.5}, Bet{Side: types.SideBack, Price: d("3"), Probability: 0.4}, "50", nil},
+		{"kelly capped", &Kelly{Bankroll: bankroll, Fraction: 1, Max: d("20")}, Bet{Side: types.SideBack, Price: d("3"), Probability: 0.4}, "20", nil},
+		{"kelly no edge", &Kelly{Bankroll: bankroll, Fraction: 1}, Bet{Side: types.SideBack, Price: d("2"), Probability: 0.4}, "0", ErrNoEdge},
+		// Laying at 3.0 with p=0.2 risks 0.8-0.2*2 = 40% as liability
+		{"kelly lay", &Kelly{Bankroll: bankroll, Fraction: 0.25}, Bet{Side: types.SideLay, Price: d("3"), Probability: 0.2}, "50", nil},
+		{"kelly lay no edge", &Kelly{Bankroll: bankroll, Fraction: 1}, Bet{Side: types.SideLay, Price: d("3"), Probability: 0.4}, "0", ErrNoEdge},
+		{"kelly bad probability", &Kelly{Bankroll: bankroll, Fraction: 1}, Bet{Side: types.SideBack, Price: d("3"), Probability: 1}, "0", ErrInvalidProbability},
+		{"level profit back", &LevelProfit{Profit: d("10")}, Bet{Side: types.SideBack, Price: d("4")}, "3.33", nil},
+		{"level profit lay", &LevelProfit{Profit: d("10")}, Bet{Side: types.SideLay, Price: d("4")}, "10", nil},
+		{"level liability lay", &LevelLiability{Liability: d("10")}, Bet{Side: types.SideLay, Price: d("7")}, "1.66", nil},
+		{"level liability back", &LevelLiability{Liability: d("10")}, Bet{Side: types.SideBack, Price: d("7")}, "10", nil},
+		{"level liability below minimum", &LevelLiability{Liability: d("5")}, Bet{Side: types.SideLay, Price: d("21")}, "0", betting.ErrBelowMinimumStake},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.plan.Stake(tt.bet)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Stake() error = %v, want %v", err, tt.err)
+			}
+			if got != d(tt.want) {
+				t.Errorf("Stake() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+type failingBankroll struct{}
+
+func (failingBankroll) Balance() (types.Decimal, error) { return 0, errors.New("unavailable") }
+
+func TestPercentage_BankrollErrors(t *testing.T) {
+	bet := Bet{Side: types.SideBack, Price: d("3")}
+	if _, err := (&Percentage{Bankroll: failingBankroll{}, Percent: d("1")}).Stake(bet); err == nil {
+		t.Error("Stake() with failing bankroll succeeded")
+	}
+	if _, err := (&Percentage{Bankroll: FixedBankroll(0), Percent: d("1")}).Stake(bet); !errors.Is(err, ErrInvalidBankroll) {
+		t.Errorf("Stake() with empty bankroll error = %v, want %v", err, ErrInvalidBankroll)
+	}
+}
@@ -0,0 +1,301 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	DefaultMaxMarkets = 100
+)
+
+var (
+	// ErrUnguarded is returned by Run when the API was built without a guard
+	ErrUnguarded = errors.New("the betting API was built without a guard")
+
+	DefaultMarketProjection = []string{"EVENT", "EVENT_TYPE", "COMPETITION", "MARKET_START_TIME", "MARKET_DESCRIPTION", "RUNNER_DESCRIPTION"}
+)
+
+type (
+	// Selection chooses the markets a Runner trades with listMarketCatalogue
+	Selection struct {
+		Filter           *types.MarketFilter
+		MaxResults       int
+		MarketProjection []string
+		// Refresh re-runs the selection at this interval to pick up new
+		// markets. Zero selects once
+		Refresh time.Duration
+	}
+
+	// Runner drives strategies from a Source. Each cycle it fetches books for
+	// the selected markets, calls OnMarketUpdate or, once, OnMarketClosed,
+	// polls listCurrentOrders for changes to report through OnOrderUpdate and
+	// fires OnTimer when due. Run returns when the context is done, the source
+	// is exhausted or every selected market has closed. The API must have been
+	// built with a guard, so every order a strategy places is vetted
+	Runner struct {
+		API        *betting.API
+		Strategies []Strategy
+		Selection  Selection
+		Source     Source
+		// TimerInterval between OnTimer calls. Zero disables the timer
+		TimerInterval time.Duration
+		// OnError receives source and API errors and recovered panics as *PanicError
+		OnError func(err error)
+		Now     func() time.Time
+
+		mu         sync.Mutex
+		catalogues map[string]*types.MarketCatalogueWrapper
+		open       []string
+		closed     map[string]bool
+		orders     map[string]types.CurrentOrder
+	}
+)
+
+func NewRunner(api *betting.API, selection Selection, strategies ...Strategy) *Runner {
+	return &Runner{
+		API:        api,
+		Strategies: strategies,
+		Selection:  selection,
+		Source:     NewPollingSource(api),
+		Now:        time.Now,
+	}
+}
+
+func (r *Runner) Run(ctx context.Context) error {
+	if !r.API.Guarded() {
+		return ErrUnguarded
+	}
+	r.mu.Lock()
+	r.catalogues = map[string]*types.MarketCatalogueWrapper{}
+	r.closed = map[string]bool{}
+	r.orders = map[string]types.CurrentOrder{}
+	r.open = nil
+	r.mu.Unlock()
+
+	if err := r.selectMarkets(); err != nil {
+		return err
+	}
+	now := r.Now()
+	nextTimer := now.Add(r.TimerInterval)
+	nextSelection := now.Add(r.Selection.Refresh)
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		marketIds := r.openMarkets()
+		if len(marketIds) == 0 && r.Selection.Refresh <= 0 {
+			return nil
+		}
+
+		books, err := r.Source.Next(ctx, marketIds)
+		switch {
+		case err == io.EOF:
+			return nil
+		case err != nil && ctx.Err() != nil:
+			return nil
+		case err != nil:
+			r.report(err)
+		}
+		for i := range books {
+			r.dispatchBook(&books[i])
+		}
+		if len(marketIds) > 0 {
+			r.pollOrders()
+		}
+
+		now = r.Now()
+		if r.TimerInterval > 0 && !now.Before(nextTimer) {
+			nextTimer = now.Add(r.TimerInterval)
+			for _, strategy := range r.Strategies {
+				sctx := r.context(strategy)
+				r.safely(strategy, "OnTimer", func() { strategy.OnTimer(sctx) })
+			}
+		}
+		if r.Selection.Refresh > 0 && !now.Before(nextSelection) {
+			nextSelection = now.Add(r.Selection.Refresh)
+			if err := r.selectMarkets(); err != nil {
+				r.report(err)
+			}
+		}
+	}
+}
+
+func (r *Runner) selectMarkets() error {
+	maxResults := r.Selection.MaxResults
+	if maxResults <= 0 {
+		maxResults = DefaultMaxMarkets
+	}
+	projection := r.Selection.MarketProjection
+	if projection == nil {
+		projection = DefaultMarketProjection
+	}
+	filter := r.Selection.Filter
+	if filter == nil {
+		filter = &types.MarketFilter{}
+	}
+	catalogues, err := r.API.ListMarketCatalogue(filter, maxResults, projection)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range catalogues {
+		catalogue := &catalogues[i]
+		if _, seen := r.catalogues[catalogue.MarketId]; seen {
+			continue
+		}
+		r.catalogues[catalogue.MarketId] = catalogue
+		r.open = append(r.open, catalogue.MarketId)
+	}
+	return nil
+}
+
+func (r *Runner) openMarkets() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.open...)
+}
+
+func (r *Runner) catalogue(marketId string) *types.MarketCatalogueWrapper {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.catalogues[marketId]
+}
+
+func (r *Runner) dispatchBook(book *types.MarketBookWrapper) {
+	r.mu.Lock()
+	catalogue, known := r.catalogues[book.MarketId]
+	alreadyClosed := r.closed[book.MarketId]
+	r.mu.Unlock()
+	if !known || alreadyClosed {
+		return
+	}
+
+	closed := book.Status == types.MarketStatusClosed
+	for _, strategy := range r.Strategies {
+		if selector, ok := strategy.(MarketSelector); ok && !selector.SelectMarket(catalogue) {
+			continue
+		}
+		ctx := r.context(strategy)
+		if closed {
+			r.safely(strategy, "OnMarketClosed", func() { strategy.OnMarketClosed(ctx, book) })
+		} else {
+			r.safely(strategy, "OnMarketUpdate", func() { strategy.OnMarketUpdate(ctx, book) })
+		}
+	}
+
+	if closed {
+		r.mu.Lock()
+		r.closed[book.MarketId] = true
+		for i, id := range r.open {
+			if id == book.MarketId {
+				r.open = append(r.open[:i], r.open[i+1:]...)
+				break
+			}
+		}
+		r.mu.Unlock()
+	}
+}
+
+// pollOrders reports every order that is new or has changed since the last
+// poll to the strategy that placed it
+func (r *Runner) pollOrders() {
+	current, err := r.API.ListCurrentOrders()
+	if err != nil {
+		r.report(err)
+		return
+	}
+	if current == nil {
+		return
+	}
+
+	var changed []types.CurrentOrder
+	r.mu.Lock()
+	seen := map[string]bool{}
+	for _, order := range current.Orders {
+		seen[order.BetId] = true
+		if previous, ok := r.orders[order.BetId]; !ok || orderChanged(previous, order) {
+			changed = append(changed, order)
+		}
+		r.orders[order.BetId] = order
+	}
+	// Orders drop out of listCurrentOrders once their market settles
+	for betId := range r.orders {
+		if !seen[betId] {
+			delete(r.orders, betId)
+		}
+	}
+	r.mu.Unlock()
+
+	for i := range changed {
+		order := &changed[i]
+		for _, strategy := range r.Strategies {
+			if StrategyRef(strategy.Name()) != order.CustomerStrategyRef {
+				continue
+			}
+			ctx := r.context(strategy)
+			r.safely(strategy, "OnOrderUpdate", func() { strategy.OnOrderUpdate(ctx, order) })
+		}
+	}
+}
+
+func orderChanged(previous, order types.CurrentOrder) bool {
+	return previous.Status != order.Status ||
+		previous.PriceSize != order.PriceSize ||
+		previous.SizeMatched != order.SizeMatched ||
+		previous.SizeRemaining != order.SizeRemaining ||
+		previous.SizeCancelled != order.SizeCancelled ||
+		previous.SizeLapsed != order.SizeLapsed ||
+		previous.SizeVoided != order.SizeVoided
+}
+
+func (r *Runner) strategyOrders(ref, marketId string) []types.CurrentOrder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var orders []types.CurrentOrder
+	for _, order := range r.orders {
+		if order.CustomerStrategyRef == ref && (marketId == "" || order.MarketId == marketId) {
+			orders = append(orders, order)
+		}
+	}
+	return orders
+}
+
+func (r *Runner) context(strategy Strategy) *Context {
+	return &Context{
+		Time:     r.Now(),
+		Strategy: strategy.Name(),
+		runner:   r,
+	}
+}
+
+// safely isolates a strategy's panic so the runner and the other strategies carry on
+func (r *Runner) safely(strategy Strategy, hook string, fn func()) {
+	defer func() {
+		if value := recover(); value != nil {
+			r.report(&PanicError{
+				Strategy: strategy.Name(),
+				Hook:     hook,
+				Value:    value,
+				Stack:    debug.Stack(),
+			})
+		}
+	}()
+	fn()
+}
+
+func (r *Runner) report(err error) {
+	if r.OnError != nil && !errors.Is(err, context.Canceled) {
+		r.OnError(err)
+	}
+}
@@ -0,0 +1,169 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/risk"
+	"github.com/guysports/go-betfair-api/pkg/transport"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type (
+	// countingSource closes the market after a number of book requests
+	countingSource struct {
+		calls   int
+		closeAt int
+	}
+
+	// catalogueTransport serves the simulated exchange's catalogue
+	catalogueTransport struct {
+		types.TransportInterface
+	}
+
+	backer struct {
+		Base
+		updates int
+		closed  int
+		timers  int
+		orders  []types.CurrentOrder
+	}
+
+	faulty struct {
+		Base
+	}
+)
+
+func (s *countingSource) MarketBooks(marketIds []string) ([]types.MarketBookWrapper, error) {
+	s.calls++
+	book := types.MarketBookWrapper{
+		MarketId: "1.1",
+		Status:   types.MarketStatusOpen,
+		Runners: []types.Runner{{
+			SelectionID: 1,
+			Status:      types.RunnerStatusActive,
+			Exchange: types.ExchangePrices{
+				AvailableToBack: []types.Odds{{Price: types.MustParseDecimal("2.5"), Size: types.NewMoney(100)}},
+			},
+		}},
+	}
+	if s.calls >= s.closeAt {
+		book.Status = types.MarketStatusClosed
+		book.Runners[0].Status = types.RunnerStatusWinner
+	}
+	return []types.MarketBookWrapper{book}, nil
+}
+
+func (c *catalogueTransport) Do(id int, method string, filter *types.MarketFilter, additionalParams interface{}) ([]byte, error) {
+	if method != "listMarketCatalogue" {
+		return nil, fmt.Errorf("unexpected %s", method)
+	}
+	return json.Marshal([]types.MarketCatalogueWrapper{{MarketId: "1.1", MarketName: "Match Odds"}})
+}
+
+func (b *backer) Name() string { return "a-long-backer-strategy-name" }
+
+func (b *backer) OnMarketUpdate(ctx *Context, book *types.MarketBookWrapper) {
+	b.updates++
+	if b.updates > 1 {
+		return
+	}
+	report, err := ctx.Place(&types.PlaceInstructionParams{
+		MarketID: book.MarketId,
+		Instructions: []types.PlaceInstruction{{
+			OrderType:   types.OrderTypeLimit,
+			SelectionId: 1,
+			Side:        types.SideBack,
+			LimitOrder:  &types.LimitOrder{Size: types.NewMoney(2), Price: types.MustParseDecimal("2.4"), PersistanceType: types.PersistenceLapse},
+		}},
+	})
+	if err != nil || report.Status != types.InstructionStatusSuccess {
+		panic("order failed")
+	}
+}
+
+func (b *backer) OnOrderUpdate(ctx *Context, order *types.CurrentOrder) {
+	b.orders = append(b.orders, *order)
+	if len(ctx.Orders(order.MarketId)) != 1 || ctx.Catalogue(order.MarketId) == nil {
+		panic("order not tracked")
+	}
+}
+
+func (b *backer) OnMarketClosed(*Context, *types.MarketBookWrapper) { b.closed++ }
+func (b *backer) OnTimer(*Context)                                  { b.timers++ }
+
+func (f *faulty) Name() string { return "faulty" }
+
+func (f *faulty) OnMarketUpdate(*Context, *types.MarketBookWrapper) {
+	panic("boom")
+}
+
+func TestRunner_Run(t *testing.T) {
+	sim := transport.NewSimulatedTransport(&countingSource{closeAt: 5}, &catalogueTransport{})
+	api, _ := risk.NewAPI(sim, risk.Limits{MaxOrderStake: types.NewMoney(10)})
+
+	b := &backer{}
+	runner := NewRunner(api, Selection{}, &faulty{}, b)
+	runner.Source.(*PollingSource).Interval = time.Millisecond
+	runner.TimerInterval = time.Nanosecond
+	var errs []error
+	runner.OnError = func(err error) { errs = append(errs, err) }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := runner.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("runner did not stop once the market closed")
+	}
+
+	if b.updates < 1 || b.closed != 1 || b.timers < 1 {
+		t.Errorf("updates %d, closed %d, timers %d", b.updates, b.closed, b.timers)
+	}
+	if len(b.orders) != 1 || b.orders[0].SizeMatched != types.NewMoney(2) || b.orders[0].CustomerStrategyRef != "a-long-backer-s" {
+		t.Errorf("order updates %+v", b.orders)
+	}
+	if len(errs) == 0 {
+		t.Fatal("expected the faulty strategy's panics to be reported")
+	}
+	for _, err := range errs {
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) || panicErr.Strategy != "faulty" {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+}
+
+func TestRunner_RefusesUnguardedAPI(t *testing.T) {
+	sim := transport.NewSimulatedTransport(&countingSource{closeAt: 5}, &catalogueTransport{})
+	runner := NewRunner(betting.NewTransportAPI(sim), Selection{}, &backer{})
+	if err := runner.Run(context.Background()); err != ErrUnguarded {
+		t.Errorf("Run() error = %v, want %v", err, ErrUnguarded)
+	}
+}
+
+func TestStreamSource_Next(t *testing.T) {
+	messages := make(chan *types.MarketChangeMessage, 2)
+	ltp := types.MustParseDecimal("3")
+	messages <- &types.MarketChangeMessage{Op: types.StreamOpMarketChange, MarketChanges: []types.MarketChange{{ID: "1.1", RunnerChanges: []types.RunnerChange{{ID: 1, LastTradedPrice: &ltp}}}}}
+	messages <- &types.MarketChangeMessage{Op: types.StreamOpMarketChange, MarketChanges: []types.MarketChange{{ID: "1.2"}}}
+	close(messages)
+
+	source := NewStreamSource(messages)
+	books, err := source.Next(context.Background(), []string{"1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(books) != 1 || books[0].Runners[0].LastPriceTraded != ltp {
+		t.Errorf("books %+v", books)
+	}
+	if _, err := source.Next(context.Background(), []string{"1.1"}); err == nil {
+		t.Error("expected io.EOF once the messages are exhausted")
+	}
+}
@@ -0,0 +1,148 @@
+package strategy
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/stream"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	DefaultPollInterval = time.Second
+	// DefaultBatchSize keeps a listMarketBook request with best offers inside
+	// the exchange's data weight limit
+	DefaultBatchSize  = 40
+	DefaultStreamIdle = time.Second
+)
+
+var (
+	DefaultPriceProjection = &types.PriceProjection{
+		PriceData: []string{"EX_BEST_OFFERS", "EX_TRADED"},
+	}
+)
+
+type (
+	// Source delivers market books to a Runner. Next blocks until there are
+	// books for some of the markets or the source is idle, and returns io.EOF
+	// once it has nothing more to deliver
+	Source interface {
+		Next(ctx context.Context, marketIds []string) ([]types.MarketBookWrapper, error)
+	}
+
+	// PollingSource calls listMarketBook for every market at a fixed interval
+	PollingSource struct {
+		API             betting.APIInterface
+		Interval        time.Duration
+		BatchSize       int
+		PriceProjection *types.PriceProjection
+
+		last time.Time
+	}
+
+	// StreamSource applies market change messages, from the stream or a
+	// historic file, to a cache and returns the books they changed
+	StreamSource struct {
+		Messages <-chan *types.MarketChangeMessage
+		Cache    *stream.MarketCache
+		// Idle is how long Next waits for a message before returning no books,
+		// so timers still fire in a quiet market
+		Idle time.Duration
+	}
+)
+
+func NewPollingSource(api betting.APIInterface) *PollingSource {
+	return &PollingSource{
+		API:             api,
+		Interval:        DefaultPollInterval,
+		BatchSize:       DefaultBatchSize,
+		PriceProjection: DefaultPriceProjection,
+	}
+}
+
+func (s *PollingSource) Next(ctx context.Context, marketIds []string) ([]types.MarketBookWrapper, error) {
+	if wait := time.Until(s.last.Add(s.Interval)); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	s.last = time.Now()
+
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	var books []types.MarketBookWrapper
+	for start := 0; start < len(marketIds); start += batchSize {
+		end := start + batchSize
+		if end > len(marketIds) {
+			end = len(marketIds)
+		}
+		batch, err := s.API.ListMarketBook(marketIds[start:end], s.PriceProjection, "", "")
+		if err != nil {
+			return books, err
+		}
+		books = append(books, batch...)
+	}
+	return books, nil
+}
+
+func NewStreamSource(messages <-chan *types.MarketChangeMessage) *StreamSource {
+	return &StreamSource{
+		Messages: messages,
+		Cache:    stream.NewMarketCache(),
+		Idle:     DefaultStreamIdle,
+	}
+}
+
+func (s *StreamSource) Next(ctx context.Context, marketIds []string) ([]types.MarketBookWrapper, error) {
+	idle := time.NewTimer(s.Idle)
+	defer idle.Stop()
+
+	changed := map[string]bool{}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-idle.C:
+		return nil, nil
+	case msg, ok := <-s.Messages:
+		if !ok {
+			return nil, io.EOF
+		}
+		for _, id := range s.Cache.Apply(msg) {
+			changed[id] = true
+		}
+	}
+
+	// Fold in anything else already waiting so strategies see the latest state
+	for drained := false; !drained; {
+		select {
+		case msg, ok := <-s.Messages:
+			if !ok {
+				drained = true
+				break
+			}
+			for _, id := range s.Cache.Apply(msg) {
+				changed[id] = true
+			}
+		default:
+			drained = true
+		}
+	}
+
+	var books []types.MarketBookWrapper
+	for _, marketId := range marketIds {
+		if changed[marketId] {
+			if book := s.Cache.Book(marketId); book != nil {
+				books = append(books, *book)
+			}
+		}
+	}
+	return books, nil
+}
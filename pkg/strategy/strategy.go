@@ -0,0 +1,119 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+// ErrStrategyRefMismatch is returned when an order placed through a Context
+// already carries another strategy's customerStrategyRef
+var ErrStrategyRefMismatch = errors.New("customerStrategyRef does not match the strategy")
+
+type (
+	// Strategy is driven by a Runner. Hooks are called from a single goroutine
+	// so a strategy needs no locking of its own, and a panic in one strategy is
+	// recovered without affecting the others
+	Strategy interface {
+		Name() string
+		OnMarketUpdate(ctx *Context, book *types.MarketBookWrapper)
+		OnOrderUpdate(ctx *Context, order *types.CurrentOrder)
+		OnMarketClosed(ctx *Context, book *types.MarketBookWrapper)
+		OnTimer(ctx *Context)
+	}
+
+	// MarketSelector is optionally implemented by strategies that only want
+	// some of the runner's markets
+	MarketSelector interface {
+		SelectMarket(catalogue *types.MarketCatalogueWrapper) bool
+	}
+
+	// Base implements every hook as a no-op, so a strategy can embed it and
+	// define only Name and the hooks it needs
+	Base struct{}
+
+	// Context is passed to every hook. Orders placed through it are routed via
+	// the runner's betting API and tagged with the strategy's customerStrategyRef
+	Context struct {
+		Time     time.Time
+		Strategy string
+		runner   *Runner
+	}
+
+	// PanicError reports a panic recovered from a strategy hook
+	PanicError struct {
+		Strategy string
+		Hook     string
+		Value    interface{}
+		Stack    []byte
+	}
+)
+
+func (Base) OnMarketUpdate(*Context, *types.MarketBookWrapper) {}
+func (Base) OnOrderUpdate(*Context, *types.CurrentOrder)       {}
+func (Base) OnMarketClosed(*Context, *types.MarketBookWrapper) {}
+func (Base) OnTimer(*Context)                                  {}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("strategy %s panicked in %s: %v", e.Strategy, e.Hook, e.Value)
+}
+
+// StrategyRef is the customerStrategyRef a strategy's orders are tagged with,
+// its name cut on a character boundary to the length the exchange accepts
+func StrategyRef(name string) string {
+	if len(name) <= betting.MaxCustomerStrategyRefLength {
+		return name
+	}
+	end := betting.MaxCustomerStrategyRefLength
+	for end > 0 && !utf8.RuneStart(name[end]) {
+		end--
+	}
+	return name[:end]
+}
+
+// Place tags the orders with the strategy's customerStrategyRef. Orders
+// already tagged for another strategy are refused
+func (c *Context) Place(params *types.PlaceInstructionParams) (*types.PlaceExecutionReport, error) {
+	ref := StrategyRef(c.Strategy)
+	if params.CustomerStrategyRef != "" && params.CustomerStrategyRef != ref {
+		return nil, fmt.Errorf("%w: %q, want %q", ErrStrategyRefMismatch, params.CustomerStrategyRef, ref)
+	}
+	params.CustomerStrategyRef = ref
+	return c.runner.API.PlaceOrders(params)
+}
+
+// Cancel cancels the strategy's orders. Without instructions it cancels every
+// unmatched order the strategy has on the market, leaving other strategies'
+// orders alone
+func (c *Context) Cancel(params *types.CancelInstructionParams) (*types.CancelExecutionReport, error) {
+	if len(params.Instructions) == 0 {
+		for _, order := range c.Orders(params.MarketID) {
+			if order.SizeRemaining > 0 {
+				params.Instructions = append(params.Instructions, types.CancelInstruction{BetId: order.BetId})
+			}
+		}
+		if len(params.Instructions) == 0 {
+			return &types.CancelExecutionReport{Status: types.InstructionStatusSuccess, MarketID: params.MarketID}, nil
+		}
+	}
+	return c.runner.API.CancelOrders(params)
+}
+
+func (c *Context) Replace(params *types.ReplaceInstructionParams) (*types.ReplaceExecutionReport, error) {
+	return c.runner.API.ReplaceOrders(params)
+}
+
+// Orders returns the strategy's current orders on a market, or on every
+// market when marketId is empty, as of the runner's last order poll
+func (c *Context) Orders(marketId string) []types.CurrentOrder {
+	return c.runner.strategyOrders(StrategyRef(c.Strategy), marketId)
+}
+
+// Catalogue returns the catalogue entry the runner selected a market from
+func (c *Context) Catalogue(marketId string) *types.MarketCatalogueWrapper {
+	return c.runner.catalogue(marketId)
+}
@@ -0,0 +1,37 @@
+package strategy
+
+import (
+	"errors"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+func TestStrategyRef(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "short", in: "backer", want: "backer"},
+		{name: "cut", in: "a-long-backer-strategy", want: "a-long-backer-s"},
+		{name: "multi-byte character at the limit", in: "backer-€uro-ñame", want: "backer-€uro-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := StrategyRef(tt.in)
+			if got != tt.want || !utf8.ValidString(got) {
+				t.Errorf("StrategyRef() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContext_PlaceRefusesAnotherRef(t *testing.T) {
+	ctx := &Context{Strategy: "backer"}
+	_, err := ctx.Place(&types.PlaceInstructionParams{MarketID: "1.1", CustomerStrategyRef: "layer"})
+	if !errors.Is(err, ErrStrategyRefMismatch) {
+		t.Errorf("Place() error = %v, want %v", err, ErrStrategyRefMismatch)
+	}
+}
@@ -0,0 +1,364 @@
+package stream
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type (
+	// MarketCache applies market change messages, whether from the live stream
+	// or a historic file, and produces MarketBookWrapper snapshots
+	MarketCache struct {
+		mu      sync.RWMutex
+		markets map[string]*marketState
+	}
+
+	marketState struct {
+		id          string
+		definition  *types.MarketDefinition
+		publishTime time.Time
+		// lastMatch is the publish time of the last change in traded volume
+		lastMatch   time.Time
+		totalVolume types.Decimal
+		runners     map[runnerKey]*runnerState
+	}
+
+	runnerKey struct {
+		id       int
+		handicap types.Decimal
+	}
+
+	runnerState struct {
+		ltp   types.Decimal
+		tv    types.Decimal
+		spn   types.Decimal
+		spf   types.Decimal
+		atb   map[types.Decimal]types.Decimal
+		atl   map[types.Decimal]types.Decimal
+		trd   map[types.Decimal]types.Decimal
+		spb   map[types.Decimal]types.Decimal
+		spl   map[types.Decimal]types.Decimal
+		batb  map[int]types.PriceVol
+		batl  map[int]types.PriceVol
+		bdatb map[int]types.PriceVol
+		bdatl map[int]types.PriceVol
+	}
+)
+
+func NewMarketCache() *MarketCache {
+	return &MarketCache{
+		markets: map[string]*marketState{},
+	}
+}
+
+// Apply folds a message into the cache and returns the IDs of the markets it changed
+func (c *MarketCache) Apply(msg *types.MarketChangeMessage) []string {
+	if msg.Op != "" && msg.Op != types.StreamOpMarketChange {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	publishTime := msg.Time()
+	changed := make([]string, 0, len(msg.MarketChanges))
+	for i := range msg.MarketChanges {
+		mc := &msg.MarketChanges[i]
+		market, ok := c.markets[mc.ID]
+		var traded types.Decimal
+		if ok {
+			traded = market.traded()
+		}
+		if !ok || mc.Image {
+			market = &marketState{
+				id:      mc.ID,
+				runners: map[runnerKey]*runnerState{},
+			}
+			if ok {
+				market.definition = c.markets[mc.ID].definition
+				market.lastMatch = c.markets[mc.ID].lastMatch
+			}
+			c.markets[mc.ID] = market
+		}
+		market.publishTime = publishTime
+		if mc.MarketDefinition != nil {
+			market.definition = mc.MarketDefinition
+		}
+		if mc.TotalVolume != nil {
+			market.totalVolume = *mc.TotalVolume
+		}
+		for j := range mc.RunnerChanges {
+			market.runner(mc.RunnerChanges[j].ID, mc.RunnerChanges[j].Handicap).apply(&mc.RunnerChanges[j])
+		}
+		if market.traded() != traded {
+			market.lastMatch = publishTime
+		}
+		changed = append(changed, mc.ID)
+	}
+	return changed
+}
+
+// Book returns a snapshot of a market, or nil if the cache has not seen it
+func (c *MarketCache) Book(marketId string) *types.MarketBookWrapper {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	market, ok := c.markets[marketId]
+	if !ok {
+		return nil
+	}
+	return market.book()
+}
+
+// Definition returns the latest market definition seen for a market
+func (c *MarketCache) Definition(marketId string) *types.MarketDefinition {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if market, ok := c.markets[marketId]; ok {
+		return market.definition
+	}
+	return nil
+}
+
+// PublishTime returns the time of the last change applied to a market
+func (c *MarketCache) PublishTime(marketId string) time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if market, ok := c.markets[marketId]; ok {
+		return market.publishTime
+	}
+	return time.Time{}
+}
+
+func (c *MarketCache) MarketIds() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ids := make([]string, 0, len(c.markets))
+	for id := range c.markets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Remove drops a market, e.g. once it has closed
+func (c *MarketCache) Remove(marketId string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.markets, marketId)
+}
+
+func (m *marketState) runner(id int, handicap types.Decimal) *runnerState {
+	key := runnerKey{id: id, handicap: handicap}
+	runner, ok := m.runners[key]
+	if !ok {
+		runner = &runnerState{
+			atb:   map[types.Decimal]types.Decimal{},
+			atl:   map[types.Decimal]types.Decimal{},
+			trd:   map[types.Decimal]types.Decimal{},
+			spb:   map[types.Decimal]types.Decimal{},
+			spl:   map[types.Decimal]types.Decimal{},
+			batb:  map[int]types.PriceVol{},
+			batl:  map[int]types.PriceVol{},
+			bdatb: map[int]types.PriceVol{},
+			bdatl: map[int]types.PriceVol{},
+		}
+		m.runners[key] = runner
+	}
+	return runner
+}
+
+// traded is the market's total traded volume, summed from the runners when
+// the stream has not sent a market total
+func (m *marketState) traded() types.Decimal {
+	if m.totalVolume != 0 {
+		return m.totalVolume
+	}
+	var total types.Decimal
+	for _, r := range m.runners {
+		total = total.Add(r.traded())
+	}
+	return total
+}
+
+func (m *marketState) book() *types.MarketBookWrapper {
+	book := &types.MarketBookWrapper{
+		MarketId:     m.id,
+		TotalMatched: m.totalVolume,
+	}
+	if !m.lastMatch.IsZero() {
+		book.LastMatchTime = m.lastMatch.Format(time.RFC3339Nano)
+	}
+
+	// Runners come out in definition order, followed by any the definition
+	// has not mentioned yet
+	var keys []runnerKey
+	seen := map[runnerKey]bool{}
+	definitions := map[runnerKey]*types.RunnerDefinition{}
+	if def := m.definition; def != nil {
+		book.Status = def.Status
+		book.BetDelay = def.BetDelay
+		book.BspReconciled = def.BspReconciled
+		book.Complete = def.Complete
+		book.Inplay = def.InPlay
+		book.NumberOfWinners = def.NumberOfWinners
+		book.NumberOfRunners = len(def.Runners)
+		book.CrossMatching = def.CrossMatching
+		book.RunnersVoidable = def.RunnersVoidable
+		book.Version = def.Version
+		for i := range def.Runners {
+			key := runnerKey{id: def.Runners[i].ID, handicap: def.Runners[i].Handicap}
+			definitions[key] = &def.Runners[i]
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+	var extra []runnerKey
+	for key := range m.runners {
+		if !seen[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].id < extra[j].id })
+	keys = append(keys, extra...)
+
+	for _, key := range keys {
+		runner := types.Runner{
+			SelectionID: key.id,
+			Handicap:    key.handicap,
+			Status:      types.RunnerStatusActive,
+		}
+		if def, ok := definitions[key]; ok {
+			runner.Status = def.Status
+			runner.AdjustmentFactor = def.AdjustmentFactor
+			runner.RemovalDate = def.RemovalDate
+			if def.BSP != nil {
+				runner.StartingPrices = &types.StartingPrices{ActualSP: *def.BSP}
+			}
+		}
+		if state, ok := m.runners[key]; ok {
+			state.fill(&runner)
+		}
+		book.Runners = append(book.Runners, runner)
+	}
+	return book
+}
+
+func (r *runnerState) apply(rc *types.RunnerChange) {
+	if rc.LastTradedPrice != nil {
+		r.ltp = *rc.LastTradedPrice
+	}
+	if rc.TotalVolume != nil {
+		r.tv = *rc.TotalVolume
+	}
+	if rc.StartingPriceNear != nil {
+		r.spn = rc.StartingPriceNear.Decimal()
+	}
+	if rc.StartingPriceFar != nil {
+		r.spf = rc.StartingPriceFar.Decimal()
+	}
+	applyPrices(r.atb, rc.AvailableToBack)
+	applyPrices(r.atl, rc.AvailableToLay)
+	applyPrices(r.trd, rc.Traded)
+	applyPrices(r.spb, rc.StartingPriceBack)
+	applyPrices(r.spl, rc.StartingPriceLay)
+	applyLevels(r.batb, rc.BestAvailableToBack)
+	applyLevels(r.batl, rc.BestAvailableToLay)
+	applyLevels(r.bdatb, rc.BestDisplayAvailableToBack)
+	applyLevels(r.bdatl, rc.BestDisplayAvailableToLay)
+}
+
+func (r *runnerState) traded() types.Decimal {
+	if r.tv != 0 {
+		return r.tv
+	}
+	var total types.Decimal
+	for _, size := range r.trd {
+		total += size
+	}
+	return total
+}
+
+func (r *runnerState) fill(runner *types.Runner) {
+	runner.LastPriceTraded = r.ltp
+	runner.TotalMatched = r.traded()
+
+	// Full depth ladders take precedence over the best-price views
+	switch {
+	case len(r.atb) > 0 || len(r.atl) > 0:
+		runner.Exchange.AvailableToBack = priceLadder(r.atb, true)
+		runner.Exchange.AvailableToLay = priceLadder(r.atl, false)
+	case len(r.batb) > 0 || len(r.batl) > 0:
+		runner.Exchange.AvailableToBack = levelLadder(r.batb)
+		runner.Exchange.AvailableToLay = levelLadder(r.batl)
+	default:
+		runner.Exchange.AvailableToBack = levelLadder(r.bdatb)
+		runner.Exchange.AvailableToLay = levelLadder(r.bdatl)
+	}
+	runner.Exchange.TradedVolume = priceLadder(r.trd, false)
+
+	if r.spn != 0 || r.spf != 0 || len(r.spb) > 0 || len(r.spl) > 0 {
+		if runner.StartingPrices == nil {
+			runner.StartingPrices = &types.StartingPrices{}
+		}
+		runner.StartingPrices.NearPrice = types.ProjectedPrice(r.spn)
+		runner.StartingPrices.FarPrice = types.ProjectedPrice(r.spf)
+		runner.StartingPrices.BackStakeTaken = priceLadder(r.spb, false)
+		runner.StartingPrices.LayLiabilityTaken = priceLadder(r.spl, true)
+	}
+}
+
+func applyPrices(ladder map[types.Decimal]types.Decimal, changes []types.PriceVol) {
+	for _, change := range changes {
+		if change.Size() == 0 {
+			delete(ladder, change.Price())
+		} else {
+			ladder[change.Price()] = change.Size()
+		}
+	}
+}
+
+func applyLevels(ladder map[int]types.PriceVol, changes []types.LevelPriceVol) {
+	for _, change := range changes {
+		if change.Size() == 0 {
+			delete(ladder, change.Level())
+		} else {
+			ladder[change.Level()] = types.PriceVol{change.Price(), change.Size()}
+		}
+	}
+}
+
+// priceLadder sorts best first: descending prices to back, ascending to lay
+func priceLadder(ladder map[types.Decimal]types.Decimal, descending bool) []types.Odds {
+	if len(ladder) == 0 {
+		return nil
+	}
+	odds := make([]types.Odds, 0, len(ladder))
+	for price, size := range ladder {
+		odds = append(odds, types.Odds{Price: price, Size: size})
+	}
+	sort.Slice(odds, func(i, j int) bool {
+		if descending {
+			return odds[i].Price > odds[j].Price
+		}
+		return odds[i].Price < odds[j].Price
+	})
+	return odds
+}
+
+func levelLadder(ladder map[int]types.PriceVol) []types.Odds {
+	if len(ladder) == 0 {
+		return nil
+	}
+	levels := make([]int, 0, len(ladder))
+	for level := range ladder {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+	odds := make([]types.Odds, 0, len(levels))
+	for _, level := range levels {
+		odds = append(odds, types.Odds{Price: ladder[level].Price(), Size: ladder[level].Size()})
+	}
+	return odds
+}
@@ -0,0 +1,113 @@
+package stream
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+func apply(t *testing.T, cache *MarketCache, line string) []string {
+	t.Helper()
+	var msg types.MarketChangeMessage
+	if err := json.Unmarshal([]byte(line), &msg); err != nil {
+		t.Fatal(err)
+	}
+	return cache.Apply(&msg)
+}
+
+func ladder(odds []types.Odds) []string {
+	var out []string
+	for _, o := range odds {
+		out = append(out, o.Price.String()+"@"+o.Size.String())
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMarketCache_Apply(t *testing.T) {
+	cache := NewMarketCache()
+	changed := apply(t, cache, `{"op":"mcm","pt":1600000000000,"mc":[{"id":"1.1","img":true,
+		"marketDefinition":{"status":"OPEN","runners":[{"id":12,"status":"ACTIVE"},{"id":11,"status":"ACTIVE"}]},
+		"rc":[{"id":11,"atb":[[2.5,10],[2.48,20]],"atl":[[2.52,15],[2.54,5]]},{"id":13,"atb":[[9,1]]}]}]}`)
+	if !equal(changed, []string{"1.1"}) {
+		t.Fatalf("Apply() = %v", changed)
+	}
+
+	apply(t, cache, `{"op":"mcm","pt":1600000060000,"mc":[{"id":"1.1","rc":[{"id":11,"atb":[[2.5,0],[2.46,7]],"atl":[[2.52,3]]}]}]}`)
+	book := cache.Book("1.1")
+	if book.Status != types.MarketStatusOpen || len(book.Runners) != 3 {
+		t.Fatalf("book %+v", book)
+	}
+	// Definition order first, then runners the definition has not listed
+	if ids := [3]int{book.Runners[0].SelectionID, book.Runners[1].SelectionID, book.Runners[2].SelectionID}; ids != [3]int{12, 11, 13} {
+		t.Errorf("runner order %v", ids)
+	}
+	runner := book.Runners[1]
+	if got := ladder(runner.Exchange.AvailableToBack); !equal(got, []string{"2.48@20", "2.46@7"}) {
+		t.Errorf("AvailableToBack = %v", got)
+	}
+	if got := ladder(runner.Exchange.AvailableToLay); !equal(got, []string{"2.52@3", "2.54@5"}) {
+		t.Errorf("AvailableToLay = %v", got)
+	}
+	if book.LastMatchTime != "" {
+		t.Errorf("LastMatchTime = %s before anything traded", book.LastMatchTime)
+	}
+
+	// A new image replaces the ladders but keeps the definition
+	apply(t, cache, `{"op":"mcm","pt":1600000120000,"mc":[{"id":"1.1","img":true,"rc":[{"id":11,"atb":[[3,1]],"trd":[[3,4]]}]}]}`)
+	book = cache.Book("1.1")
+	if book.Status != types.MarketStatusOpen || len(book.Runners) != 2 {
+		t.Fatalf("book after image %+v", book)
+	}
+	runner = book.Runners[1]
+	if got := ladder(runner.Exchange.AvailableToBack); !equal(got, []string{"3@1"}) || runner.Exchange.AvailableToLay != nil {
+		t.Errorf("ladders after image %v %v", got, runner.Exchange.AvailableToLay)
+	}
+	traded := time.Unix(1600000120, 0).UTC().Format(time.RFC3339Nano)
+	if book.LastMatchTime != traded || runner.TotalMatched.String() != "4" {
+		t.Errorf("LastMatchTime = %s, matched %s", book.LastMatchTime, runner.TotalMatched)
+	}
+
+	// A change that trades nothing leaves the last match time alone
+	apply(t, cache, `{"op":"mcm","pt":1600000180000,"mc":[{"id":"1.1","rc":[{"id":11,"atl":[[3.05,2]]}]}]}`)
+	if book := cache.Book("1.1"); book.LastMatchTime != traded {
+		t.Errorf("LastMatchTime = %s, want %s", book.LastMatchTime, traded)
+	}
+	if got := cache.PublishTime("1.1"); !got.Equal(time.Unix(1600000180, 0)) {
+		t.Errorf("PublishTime() = %v", got)
+	}
+}
+
+func TestMarketCache_BestLevels(t *testing.T) {
+	cache := NewMarketCache()
+	apply(t, cache, `{"op":"mcm","pt":1600000000000,"mc":[{"id":"1.1","img":true,"rc":[{"id":11,"batb":[[0,2.5,10],[1,2.48,20]],"batl":[[0,2.52,15]]}]}]}`)
+	apply(t, cache, `{"op":"mcm","pt":1600000001000,"mc":[{"id":"1.1","rc":[{"id":11,"batb":[[1,2.48,0]],"batl":[[0,2.54,6]]}]}]}`)
+	runner := cache.Book("1.1").Runners[0]
+	if got := ladder(runner.Exchange.AvailableToBack); !equal(got, []string{"2.5@10"}) {
+		t.Errorf("AvailableToBack = %v", got)
+	}
+	if got := ladder(runner.Exchange.AvailableToLay); !equal(got, []string{"2.54@6"}) {
+		t.Errorf("AvailableToLay = %v", got)
+	}
+
+	if cache.Book("1.2") != nil {
+		t.Error("Book() of an unseen market is not nil")
+	}
+	cache.Remove("1.1")
+	if len(cache.MarketIds()) != 0 {
+		t.Errorf("MarketIds() = %v after Remove", cache.MarketIds())
+	}
+}
@@ -0,0 +1,169 @@
+package trading
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+var (
+	ErrNoSelections = errors.New("no selections to dutch")
+	ErrNoProfit     = errors.New("book percentage leaves no profit to target")
+)
+
+type (
+	// DutchSelection is a runner to dutch at a price
+	DutchSelection struct {
+		SelectionId int
+		Handicap    types.Decimal
+		Price       types.Decimal
+	}
+
+	// DutchStake is a selection's share of a dutch and the dutch's profit
+	// should that selection win
+	DutchStake struct {
+		DutchSelection
+		Stake  types.Decimal
+		Profit types.Decimal
+	}
+
+	// Dutch spreads stakes over selections so any of them winning gives the
+	// same result. Backing, the stakes are in proportion to each selection's
+	// implied probability; laying, the liabilities are. Unselected is the
+	// profit should none of the selections win
+	Dutch struct {
+		Side           string
+		Stakes         []DutchStake
+		TotalStake     types.Decimal
+		BookPercentage types.Decimal
+		Unselected     types.Decimal
+	}
+)
+
+// DutchSelections prices selections at the best offer in the book for the
+// side being dutched
+func DutchSelections(book *types.MarketBookWrapper, side string, selectionIds ...int) ([]DutchSelection, error) {
+	selections := make([]DutchSelection, 0, len(selectionIds))
+	for _, selectionId := range selectionIds {
+		var selection *DutchSelection
+		for i := range book.Runners {
+			runner := &book.Runners[i]
+			if runner.SelectionID != selectionId || runner.Status != types.RunnerStatusActive {
+				continue
+			}
+			quote := BestQuote(runner)
+			price := quote.Back
+			if side == types.SideLay {
+				price = quote.Lay
+			}
+			selection = &DutchSelection{SelectionId: selectionId, Handicap: runner.Handicap, Price: price}
+		}
+		if selection == nil || selection.Price <= types.NewDecimalFromInt(1) {
+			return nil, fmt.Errorf("%w: selection %d", ErrNoPrice, selectionId)
+		}
+		selections = append(selections, *selection)
+	}
+	return selections, nil
+}
+
+// DutchTotal splits a total stake across the selections
+func DutchTotal(side string, selections []DutchSelection, total types.Decimal, currency string) (*Dutch, error) {
+	book, err := bookFraction(selections)
+	if err != nil {
+		return nil, err
+	}
+	// Each stake is return / price, for a return of total / book
+	return dutch(side, selections, total.Div(book), currency)
+}
+
+// DutchProfit stakes for a target profit. Backing, it is the profit should
+// any selection win and needs a book under 100%; laying, it is the profit
+// should none of them win, which is the total lay stake
+func DutchProfit(side string, selections []DutchSelection, profit types.Decimal, currency string) (*Dutch, error) {
+	book, err := bookFraction(selections)
+	if err != nil {
+		return nil, err
+	}
+	one := types.NewDecimalFromInt(1)
+	if side == types.SideLay {
+		return dutch(side, selections, profit.Div(book), currency)
+	}
+	if book >= one {
+		return nil, ErrNoProfit
+	}
+	// The return covers the profit plus the total staked: total = return * book
+	return dutch(side, selections, profit.Div(one.Sub(book)), currency)
+}
+
+// dutch stakes return / price on each selection and works out the profit of
+// each outcome from the rounded stakes
+func dutch(side string, selections []DutchSelection, ret types.Decimal, currency string) (*Dutch, error) {
+	if side != types.SideBack && side != types.SideLay {
+		return nil, betting.ErrInvalidSide
+	}
+	rules := types.RulesForCurrency(currency)
+	hundred := types.NewDecimalFromInt(100)
+	result := &Dutch{Side: side}
+	for _, selection := range selections {
+		stake := ret.Div(selection.Price).Round(types.MoneyPlaces)
+		if !rules.MeetsMinimumStake(stake, selection.Price) {
+			return nil, fmt.Errorf("%w: %s on selection %d", betting.ErrBelowMinimumStake, stake, selection.SelectionId)
+		}
+		result.Stakes = append(result.Stakes, DutchStake{DutchSelection: selection, Stake: stake})
+		result.TotalStake = result.TotalStake.Add(stake)
+		result.BookPercentage = result.BookPercentage.Add(hundred.Div(selection.Price))
+	}
+	result.BookPercentage = result.BookPercentage.Round(types.MoneyPlaces)
+
+	for i := range result.Stakes {
+		stake := &result.Stakes[i]
+		// The winner pays out its stake times the price; every stake is lost
+		// backing, or won laying
+		payout := stake.Stake.Mul(stake.Price).Round(types.MoneyPlaces)
+		stake.Profit = payout.Sub(result.TotalStake)
+		if side == types.SideLay {
+			stake.Profit = stake.Profit.Neg()
+		}
+	}
+	result.Unselected = result.TotalStake.Neg()
+	if side == types.SideLay {
+		result.Unselected = result.TotalStake
+	}
+	return result, nil
+}
+
+// bookFraction is the sum of the selections' implied probabilities
+func bookFraction(selections []DutchSelection) (types.Decimal, error) {
+	if len(selections) == 0 {
+		return 0, ErrNoSelections
+	}
+	var book types.Decimal
+	for _, selection := range selections {
+		if selection.Price <= types.NewDecimalFromInt(1) {
+			return 0, fmt.Errorf("%w: selection %d", ErrNoPrice, selection.SelectionId)
+		}
+		book = book.Add(types.NewDecimalFromInt(1).Div(selection.Price))
+	}
+	return book, nil
+}
+
+// Instructions are the limit orders for the dutch, ready for PlaceOrders
+func (d *Dutch) Instructions(persistenceType string) []types.PlaceInstruction {
+	instructions := make([]types.PlaceInstruction, 0, len(d.Stakes))
+	for _, stake := range d.Stakes {
+		instructions = append(instructions, types.PlaceInstruction{
+			OrderType:   types.OrderTypeLimit,
+			SelectionId: stake.SelectionId,
+			Handicap:    stake.Handicap,
+			Side:        d.Side,
+			LimitOrder: &types.LimitOrder{
+				Size:            stake.Stake,
+				Price:           stake.Price,
+				PersistanceType: persistenceType,
+			},
+		})
+	}
+	return instructions
+}
@@ -0,0 +1,161 @@
+package trading
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/guysports/go-betfair-api/pkg/betting"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+func selections(prices ...string) []DutchSelection {
+	var selections []DutchSelection
+	for i, price := range prices {
+		selections = append(selections, DutchSelection{SelectionId: i + 1, Price: d(price)})
+	}
+	return selections
+}
+
+func TestDutch(t *testing.T) {
+	tests := []struct {
+		name       string
+		side       string
+		selections []DutchSelection
+		total      string
+		profit     string
+		stakes     []string
+		profits    []string
+		book       string
+		unselected string
+		err        error
+	}{
+		{
+			name:       "back a total stake",
+			side:       types.SideBack,
+			selections: selections("4", "5", "10"),
+			total:      "100",
+			stakes:     []string{"45.45", "36.36", "18.18"},
+			profits:    []string{"81.81", "81.81", "81.81"},
+			book:       "55",
+			unselected: "-99.99",
+		},
+		{
+			name:       "back for a profit",
+			side:       types.SideBack,
+			selections: selections("4", "5", "10"),
+			profit:     "50",
+			stakes:     []string{"27.78", "22.22", "11.11"},
+			profits:    []string{"50.01", "49.99", "49.99"},
+			book:       "55",
+			unselected: "-61.11",
+		},
+		{
+			name:       "lay for a profit",
+			side:       types.SideLay,
+			selections: selections("4", "5"),
+			profit:     "20",
+			stakes:     []string{"11.11", "8.89"},
+			profits:    []string{"-24.44", "-24.45"},
+			book:       "45",
+			unselected: "20",
+		},
+		{
+			name:       "lay a total stake",
+			side:       types.SideLay,
+			selections: selections("2", "4"),
+			total:      "30",
+			stakes:     []string{"20", "10"},
+			profits:    []string{"-10", "-10"},
+			book:       "75",
+			unselected: "30",
+		},
+		{
+			name:       "overround back book has no profit",
+			side:       types.SideBack,
+			selections: selections("1.5", "2.5"),
+			profit:     "10",
+			err:        ErrNoProfit,
+		},
+		{
+			name:       "below minimum stake",
+			side:       types.SideBack,
+			selections: selections("4", "5", "10"),
+			total:      "2",
+			err:        betting.ErrBelowMinimumStake,
+		},
+		{
+			name:       "unpriced selection",
+			side:       types.SideBack,
+			selections: selections("4", "0"),
+			total:      "10",
+			err:        ErrNoPrice,
+		},
+		{
+			name:  "no selections",
+			side:  types.SideBack,
+			total: "10",
+			err:   ErrNoSelections,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *Dutch
+			var err error
+			if tt.total != "" {
+				got, err = DutchTotal(tt.side, tt.selections, d(tt.total), "")
+			} else {
+				got, err = DutchProfit(tt.side, tt.selections, d(tt.profit), "")
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			for i, stake := range got.Stakes {
+				if stake.Stake != d(tt.stakes[i]) || stake.Profit != d(tt.profits[i]) {
+					t.Errorf("Stakes[%d] = %s winning %s, want %s winning %s", i, stake.Stake, stake.Profit, tt.stakes[i], tt.profits[i])
+				}
+			}
+			if got.BookPercentage != d(tt.book) {
+				t.Errorf("BookPercentage = %s, want %s", got.BookPercentage, tt.book)
+			}
+			if got.Unselected != d(tt.unselected) {
+				t.Errorf("Unselected = %s, want %s", got.Unselected, tt.unselected)
+			}
+		})
+	}
+}
+
+func TestDutchSelections(t *testing.T) {
+	book := &types.MarketBookWrapper{Runners: []types.Runner{
+		{SelectionID: 1, Status: types.RunnerStatusActive, Exchange: types.ExchangePrices{
+			AvailableToBack: []types.Odds{{Price: d("4"), Size: d("10")}},
+			AvailableToLay:  []types.Odds{{Price: d("4.1"), Size: d("10")}},
+		}},
+		{SelectionID: 2, Status: types.RunnerStatusActive, Exchange: types.ExchangePrices{
+			AvailableToBack: []types.Odds{{Price: d("5"), Size: d("10")}},
+		}},
+	}}
+
+	got, err := DutchSelections(book, types.SideLay, 1)
+	if err != nil || len(got) != 1 || got[0].Price != d("4.1") {
+		t.Errorf("DutchSelections(LAY) = %+v, %v", got, err)
+	}
+	if _, err := DutchSelections(book, types.SideLay, 1, 2); !errors.Is(err, ErrNoPrice) {
+		t.Errorf("DutchSelections() without a lay price error = %v, want %v", err, ErrNoPrice)
+	}
+
+	selections, err := DutchSelections(book, types.SideBack, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dutch, err := DutchTotal(types.SideBack, selections, d("18"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	instructions := dutch.Instructions(types.PersistenceLapse)
+	if len(instructions) != 2 || instructions[1].SelectionId != 2 || instructions[1].LimitOrder.Size != d("8") || instructions[1].Side != types.SideBack {
+		t.Errorf("Instructions() = %+v", instructions)
+	}
+}
@@ -0,0 +1,290 @@
+package trading
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/guysports/go-betfair-api/pkg/orders"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+var (
+	ErrNoPrice = errors.New("no price to hedge at")
+)
+
+type (
+	// RunnerPosition is the matched profit or loss on a runner should it win or lose
+	RunnerPosition struct {
+		SelectionId int
+		Handicap    types.Decimal
+		IfWin       types.Decimal
+		IfLose      types.Decimal
+	}
+
+	// MarketPosition holds the matched position on each runner of a market
+	MarketPosition struct {
+		MarketId string
+		Runners  []RunnerPosition
+	}
+
+	// Quote is the price a hedge backs at and the price it lays at
+	Quote struct {
+		Back types.Decimal
+		Lay  types.Decimal
+	}
+
+	// Hedge is an order that levels a position, with the runner's profit
+	// should it win or lose once the hedge is matched
+	Hedge struct {
+		SelectionId int
+		Handicap    types.Decimal
+		Side        string
+		Price       types.Decimal
+		Size        types.Decimal
+		IfWin       types.Decimal
+		IfLose      types.Decimal
+	}
+
+	// MarketHedge levels a whole market. Profit is the market's profit should
+	// each selection win once every hedge is matched
+	MarketHedge struct {
+		Hedges []Hedge
+		Profit map[int]types.Decimal
+	}
+)
+
+// PositionFromOrders builds a market position from the matched part of its current orders
+func PositionFromOrders(marketId string, current []types.CurrentOrder) *MarketPosition {
+	matched := make([]orders.Order, 0, len(current))
+	for _, order := range current {
+		matched = append(matched, orders.Order{
+			MarketId:            order.MarketId,
+			SelectionId:         order.SelectionId,
+			Handicap:            order.Handicap,
+			Side:                order.Side,
+			SizeMatched:         order.SizeMatched,
+			AveragePriceMatched: order.AveragePriceMatched,
+		})
+	}
+	return NewMarketPosition(marketId, orders.MatchedPositions(marketId, matched))
+}
+
+// NewMarketPosition builds a market position from an order manager's
+// positions, as returned by orders.Manager.Positions
+func NewMarketPosition(marketId string, positions []orders.Position) *MarketPosition {
+	position := &MarketPosition{MarketId: marketId}
+	for _, p := range positions {
+		position.Runners = append(position.Runners, RunnerPosition{
+			SelectionId: p.SelectionId,
+			Handicap:    p.Handicap,
+			IfWin:       p.IfWin,
+			IfLose:      p.IfLose,
+		})
+	}
+	return position
+}
+
+// Runner returns the position on a runner, which is flat when it has no matched orders
+func (p *MarketPosition) Runner(selectionId int, handicap types.Decimal) RunnerPosition {
+	for _, runner := range p.Runners {
+		if runner.SelectionId == selectionId && runner.Handicap == handicap {
+			return runner
+		}
+	}
+	return RunnerPosition{SelectionId: selectionId, Handicap: handicap}
+}
+
+// Profit is the market's profit should the selection win, its IfWin plus the
+// IfLose of every other runner
+func (p *MarketPosition) Profit(selectionId int) types.Decimal {
+	var profit types.Decimal
+	for _, runner := range p.Runners {
+		if runner.SelectionId == selectionId {
+			profit = profit.Add(runner.IfWin)
+		} else {
+			profit = profit.Add(runner.IfLose)
+		}
+	}
+	return profit
+}
+
+// BestQuote is the best price available to back and to lay a runner, zero
+// where that side of the book is empty
+func BestQuote(runner *types.Runner) Quote {
+	var quote Quote
+	if len(runner.Exchange.AvailableToBack) > 0 {
+		quote.Back = runner.Exchange.AvailableToBack[0].Price
+	}
+	if len(runner.Exchange.AvailableToLay) > 0 {
+		quote.Lay = runner.Exchange.AvailableToLay[0].Price
+	}
+	return quote
+}
+
+// BestQuotes returns the best quote for every active runner keyed by selection
+func BestQuotes(book *types.MarketBookWrapper) map[int]Quote {
+	quotes := map[int]Quote{}
+	for i := range book.Runners {
+		if book.Runners[i].Status == types.RunnerStatusActive {
+			quotes[book.Runners[i].SelectionID] = BestQuote(&book.Runners[i])
+		}
+	}
+	return quotes
+}
+
+// GreenUp returns the order that makes a runner's profit the same whether it
+// wins or loses: a lay of (IfWin - IfLose) / price when winning pays more,
+// otherwise a back. It returns nil when the position is already level
+func GreenUp(position RunnerPosition, quote Quote) (*Hedge, error) {
+	diff := position.IfWin.Sub(position.IfLose)
+	hedge := &Hedge{SelectionId: position.SelectionId, Handicap: position.Handicap, Side: types.SideLay, Price: quote.Lay}
+	if diff < 0 {
+		hedge.Side, hedge.Price, diff = types.SideBack, quote.Back, diff.Neg()
+	}
+	if hedge.Price <= types.NewDecimalFromInt(1) {
+		return nil, ErrNoPrice
+	}
+	hedge.Size = diff.Div(hedge.Price).Round(types.MoneyPlaces)
+	if hedge.Size == 0 {
+		return nil, nil
+	}
+	hedge.IfWin, hedge.IfLose = apply(position, hedge)
+	return hedge, nil
+}
+
+// GreenUpAtBest greens up a runner at the best prices in the book
+func GreenUpAtBest(position RunnerPosition, book *types.MarketBookWrapper) (*Hedge, error) {
+	for i := range book.Runners {
+		runner := &book.Runners[i]
+		if runner.SelectionID == position.SelectionId && runner.Handicap == position.Handicap {
+			return GreenUp(position, BestQuote(runner))
+		}
+	}
+	return nil, ErrNoPrice
+}
+
+// GreenUpMarket levels the market's profit across every selection in quotes,
+// which should be all the market's active runners. Backing all but the most
+// profitable selection and laying all but the least profitable both level
+// the market; the one locking in more profit is returned. Handicap markets,
+// whose outcomes are not exclusive, cannot be levelled this way
+func GreenUpMarket(position *MarketPosition, quotes map[int]Quote) (*MarketHedge, error) {
+	selections := make([]int, 0, len(quotes))
+	for selectionId := range quotes {
+		selections = append(selections, selectionId)
+	}
+	sort.Ints(selections)
+
+	backs, backErr := levelMarket(position, quotes, selections, types.SideBack)
+	lays, layErr := levelMarket(position, quotes, selections, types.SideLay)
+	switch {
+	case backErr != nil && layErr != nil:
+		return nil, backErr
+	case backErr != nil:
+		return lays, nil
+	case layErr != nil:
+		return backs, nil
+	}
+	if worst(lays.Profit) > worst(backs.Profit) {
+		return lays, nil
+	}
+	return backs, nil
+}
+
+// levelMarket stakes (target - profit) / price on each selection, backing up
+// to the best outcome or laying down to the worst
+func levelMarket(position *MarketPosition, quotes map[int]Quote, selections []int, side string) (*MarketHedge, error) {
+	profits := map[int]types.Decimal{}
+	target := types.Decimal(0)
+	for i, selectionId := range selections {
+		profits[selectionId] = position.Profit(selectionId)
+		if i == 0 || (side == types.SideBack && profits[selectionId] > target) || (side == types.SideLay && profits[selectionId] < target) {
+			target = profits[selectionId]
+		}
+	}
+
+	result := &MarketHedge{Profit: map[int]types.Decimal{}}
+	for _, selectionId := range selections {
+		diff := target.Sub(profits[selectionId]).Abs()
+		if diff == 0 {
+			continue
+		}
+		price := quotes[selectionId].Back
+		if side == types.SideLay {
+			price = quotes[selectionId].Lay
+		}
+		if price <= types.NewDecimalFromInt(1) {
+			return nil, ErrNoPrice
+		}
+		hedge := Hedge{
+			SelectionId: selectionId,
+			Side:        side,
+			Price:       price,
+			Size:        diff.Div(price).Round(types.MoneyPlaces),
+		}
+		if hedge.Size == 0 {
+			continue
+		}
+		hedge.IfWin, hedge.IfLose = apply(position.Runner(selectionId, 0), &hedge)
+		result.Hedges = append(result.Hedges, hedge)
+	}
+
+	for _, selectionId := range selections {
+		profit := profits[selectionId]
+		for _, hedge := range result.Hedges {
+			win, lose := apply(RunnerPosition{}, &hedge)
+			if hedge.SelectionId == selectionId {
+				profit = profit.Add(win)
+			} else {
+				profit = profit.Add(lose)
+			}
+		}
+		result.Profit[selectionId] = profit
+	}
+	return result, nil
+}
+
+// apply returns the runner's profit should it win or lose once the hedge matches
+func apply(position RunnerPosition, hedge *Hedge) (types.Decimal, types.Decimal) {
+	winnings := hedge.Size.Mul(hedge.Price.Sub(types.NewDecimalFromInt(1))).Round(types.MoneyPlaces)
+	if hedge.Side == types.SideBack {
+		return position.IfWin.Add(winnings), position.IfLose.Sub(hedge.Size)
+	}
+	return position.IfWin.Sub(winnings), position.IfLose.Add(hedge.Size)
+}
+
+func worst(profits map[int]types.Decimal) types.Decimal {
+	first := true
+	var min types.Decimal
+	for _, profit := range profits {
+		if first || profit < min {
+			min, first = profit, false
+		}
+	}
+	return min
+}
+
+// Instruction is the limit order for the hedge
+func (h *Hedge) Instruction(persistenceType string) types.PlaceInstruction {
+	return types.PlaceInstruction{
+		OrderType:   types.OrderTypeLimit,
+		SelectionId: h.SelectionId,
+		Handicap:    h.Handicap,
+		Side:        h.Side,
+		LimitOrder: &types.LimitOrder{
+			Size:            h.Size,
+			Price:           h.Price,
+			PersistanceType: persistenceType,
+		},
+	}
+}
+
+// Instructions are the limit orders for every hedge, ready for PlaceOrders.
+// Stakes below the currency minimum are left for the OrderValidator to catch
+func (m *MarketHedge) Instructions(persistenceType string) []types.PlaceInstruction {
+	instructions := make([]types.PlaceInstruction, 0, len(m.Hedges))
+	for i := range m.Hedges {
+		instructions = append(instructions, m.Hedges[i].Instruction(persistenceType))
+	}
+	return instructions
+}
@@ -0,0 +1,166 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/guysports/go-betfair-api/pkg/orders"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+func d(s string) types.Decimal { return types.MustParseDecimal(s) }
+
+func matched(selectionId int, side, price, size string) types.CurrentOrder {
+	return types.CurrentOrder{
+		MarketId:            "1.1",
+		SelectionId:         selectionId,
+		Side:                side,
+		AveragePriceMatched: d(price),
+		SizeMatched:         d(size),
+	}
+}
+
+func TestPositionFromOrders(t *testing.T) {
+	position := PositionFromOrders("1.1", []types.CurrentOrder{
+		matched(1, types.SideBack, "4", "10"),
+		matched(1, types.SideLay, "3", "5"),
+		matched(2, types.SideLay, "2", "10"),
+		{MarketId: "1.1", SelectionId: 3, Side: types.SideBack, SizeRemaining: d("5")},
+		{MarketId: "1.2", SelectionId: 1, Side: types.SideBack, AveragePriceMatched: d("2"), SizeMatched: d("5")},
+	})
+	want := []RunnerPosition{
+		{SelectionId: 1, IfWin: d("20"), IfLose: d("-5")},
+		{SelectionId: 2, IfWin: d("-10"), IfLose: d("10")},
+	}
+	if len(position.Runners) != len(want) {
+		t.Fatalf("Runners = %+v, want %+v", position.Runners, want)
+	}
+	for i := range want {
+		if position.Runners[i] != want[i] {
+			t.Errorf("Runners[%d] = %+v, want %+v", i, position.Runners[i], want[i])
+		}
+	}
+	if got := position.Profit(1); got != d("30") {
+		t.Errorf("Profit(1) = %s, want 30", got)
+	}
+	if got := position.Profit(3); got != d("5") {
+		t.Errorf("Profit(3) = %s, want 5", got)
+	}
+}
+
+func TestGreenUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		position RunnerPosition
+		quote    Quote
+		want     *Hedge
+		err      error
+	}{
+		{
+			name:     "lay off a back",
+			position: RunnerPosition{SelectionId: 1, IfWin: d("30"), IfLose: d("-10")},
+			quote:    Quote{Back: d("2.9"), Lay: d("3")},
+			want:     &Hedge{SelectionId: 1, Side: types.SideLay, Price: d("3"), Size: d("13.33"), IfWin: d("3.34"), IfLose: d("3.33")},
+		},
+		{
+			name:     "back off a lay",
+			position: RunnerPosition{SelectionId: 1, IfWin: d("-10"), IfLose: d("10")},
+			quote:    Quote{Back: d("3"), Lay: d("3.1")},
+			want:     &Hedge{SelectionId: 1, Side: types.SideBack, Price: d("3"), Size: d("6.67"), IfWin: d("3.34"), IfLose: d("3.33")},
+		},
+		{
+			name:     "already level",
+			position: RunnerPosition{SelectionId: 1, IfWin: d("5"), IfLose: d("5")},
+			quote:    Quote{Back: d("3"), Lay: d("3.1")},
+		},
+		{
+			name:     "no lay price",
+			position: RunnerPosition{SelectionId: 1, IfWin: d("30"), IfLose: d("-10")},
+			quote:    Quote{Back: d("3")},
+			err:      ErrNoPrice,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GreenUp(tt.position, tt.quote)
+			if err != tt.err {
+				t.Fatalf("GreenUp() error = %v, want %v", err, tt.err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("GreenUp() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGreenUpMarket(t *testing.T) {
+	position := PositionFromOrders("1.1", []types.CurrentOrder{matched(1, types.SideBack, "4", "10")})
+	book := &types.MarketBookWrapper{
+		MarketId: "1.1",
+		Runners: []types.Runner{
+			{SelectionID: 1, Status: types.RunnerStatusActive, Exchange: types.ExchangePrices{
+				AvailableToBack: []types.Odds{{Price: d("3.9"), Size: d("100")}},
+				AvailableToLay:  []types.Odds{{Price: d("4"), Size: d("100")}},
+			}},
+			{SelectionID: 2, Status: types.RunnerStatusActive, Exchange: types.ExchangePrices{
+				AvailableToBack: []types.Odds{{Price: d("2"), Size: d("100")}},
+				AvailableToLay:  []types.Odds{{Price: d("2.02"), Size: d("100")}},
+			}},
+			{SelectionID: 3, Status: types.RunnerStatusActive, Exchange: types.ExchangePrices{
+				AvailableToBack: []types.Odds{{Price: d("3"), Size: d("100")}},
+				AvailableToLay:  []types.Odds{{Price: d("3.05"), Size: d("100")}},
+			}},
+			{SelectionID: 4, Status: types.RunnerStatusRemoved},
+		},
+	}
+
+	// Laying the backed runner at 4 scratches the position, which beats
+	// backing the other two for a loss of 3.33
+	hedge, err := GreenUpMarket(position, BestQuotes(book))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hedge.Hedges) != 1 || hedge.Hedges[0].Side != types.SideLay || hedge.Hedges[0].Size != d("10") || hedge.Hedges[0].Price != d("4") {
+		t.Fatalf("Hedges = %+v, want a lay of 10 at 4", hedge.Hedges)
+	}
+	for _, selectionId := range []int{1, 2, 3} {
+		if hedge.Profit[selectionId] != 0 {
+			t.Errorf("Profit[%d] = %s, want 0", selectionId, hedge.Profit[selectionId])
+		}
+	}
+	if _, removed := hedge.Profit[4]; removed {
+		t.Error("removed runner included in the profit")
+	}
+
+	instructions := hedge.Instructions(types.PersistenceLapse)
+	if len(instructions) != 1 || instructions[0].LimitOrder.Size != d("10") || instructions[0].OrderType != types.OrderTypeLimit {
+		t.Errorf("Instructions() = %+v", instructions)
+	}
+
+	// Without lay prices it backs the other runners instead
+	quotes := BestQuotes(book)
+	for selectionId, quote := range quotes {
+		quote.Lay = 0
+		quotes[selectionId] = quote
+	}
+	hedge, err = GreenUpMarket(position, quotes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hedge.Hedges) != 2 || hedge.Hedges[0].Size != d("20") || hedge.Hedges[1].Size != d("13.33") {
+		t.Fatalf("Hedges = %+v, want backs of 20 and 13.33", hedge.Hedges)
+	}
+	for selectionId, want := range map[int]string{1: "-3.33", 2: "-3.33", 3: "-3.34"} {
+		if hedge.Profit[selectionId] != d(want) {
+			t.Errorf("Profit[%d] = %s, want %s", selectionId, hedge.Profit[selectionId], want)
+		}
+	}
+}
+
+func TestNewMarketPosition(t *testing.T) {
+	position := NewMarketPosition("1.1", []orders.Position{
+		{MarketId: "1.1", SelectionId: 1, BackMatched: d("10"), IfWin: d("30"), IfLose: d("-10")},
+	})
+	if got := position.Runner(1, 0); got.IfWin != d("30") || got.IfLose != d("-10") {
+		t.Errorf("Runner() = %+v", got)
+	}
+}
@@ -0,0 +1,326 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+const (
+	InteractionAuthenticate = "authenticate"
+	InteractionDo           = "do"
+	InteractionCall         = "call"
+
+	Redacted = "REDACTED"
+)
+
+var (
+	ErrNoInteraction = errors.New("no recorded interaction matches the request")
+
+	// sensitiveKeys are redacted wherever they appear in params or results
+	sensitiveKeys = map[string]bool{
+		"sessiontoken":     true,
+		"password":         true,
+		"username":         true,
+		"appkey":           true,
+		"ssoid":            true,
+		"x-authentication": true,
+		"x-application":    true,
+	}
+)
+
+type (
+	// Interaction is one recorded request and its raw result or error. Params
+	// are stored in canonical form so replayed requests can be compared
+	Interaction struct {
+		Kind   string          `json:"kind"`
+		Method string          `json:"method,omitempty"`
+		Params json.RawMessage `json:"params,omitempty"`
+		Result json.RawMessage `json:"result,omitempty"`
+		Error  string          `json:"error,omitempty"`
+		// RPCCode and RPCMessage keep a JSON-RPC error so that replaying it
+		// returns an *RPCError, as the live call did
+		RPCCode    int    `json:"rpcCode,omitempty"`
+		RPCMessage string `json:"rpcMessage,omitempty"`
+	}
+
+	Cassette struct {
+		Interactions []Interaction `json:"interactions"`
+	}
+
+	// RecordingTransport passes every call to Transport and records it with
+	// session tokens and credentials redacted. Call Save to write the cassette
+	RecordingTransport struct {
+		Transport types.TransportInterface
+		Cassette  *Cassette
+
+		mu      sync.Mutex
+		secrets []string
+	}
+
+	// ReplayTransport answers from a cassette. Requests are matched on kind,
+	// method and params; identical requests are served in recorded order and
+	// anything unmatched fails with ErrNoInteraction
+	ReplayTransport struct {
+		Cassette *Cassette
+
+		mu   sync.Mutex
+		used []bool
+	}
+
+	// doParams is how a Do request is recorded
+	doParams struct {
+		Filter *types.MarketFilter `json:"filter,omitempty"`
+		Params interface{}         `json:"params,omitempty"`
+	}
+)
+
+func LoadCassette(path string) (*Cassette, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(buf, &cassette); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cassette, nil
+}
+
+func (c *Cassette) Save(path string) error {
+	buf, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(buf, '\n'), 0600)
+}
+
+func NewRecordingTransport(transport types.TransportInterface) *RecordingTransport {
+	return &RecordingTransport{
+		Transport: transport,
+		Cassette:  &Cassette{},
+	}
+}
+
+func (r *RecordingTransport) Authenticate() (*types.Authenticate, error) {
+	auth, err := r.Transport.Authenticate()
+	if auth != nil {
+		r.addSecret(auth.SessionToken)
+	}
+	r.record(InteractionAuthenticate, "", nil, auth, err)
+	return auth, err
+}
+
+func (r *RecordingTransport) SetSessionKey(key string) {
+	r.addSecret(key)
+	r.Transport.SetSessionKey(key)
+}
+
+func (r *RecordingTransport) Do(id int, method string, filter *types.MarketFilter, additionalParams interface{}) ([]byte, error) {
+	buf, err := r.Transport.Do(id, method, filter, additionalParams)
+	r.record(InteractionDo, method, &doParams{Filter: filter, Params: additionalParams}, json.RawMessage(buf), err)
+	return buf, err
+}
+
+func (r *RecordingTransport) Call(id int, method string, params interface{}) ([]byte, error) {
+	buf, err := r.Transport.Call(id, method, params)
+	r.record(InteractionCall, method, params, json.RawMessage(buf), err)
+	return buf, err
+}
+
+// Save writes the interactions recorded so far
+func (r *RecordingTransport) Save(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.Cassette.Save(path)
+}
+
+func (r *RecordingTransport) addSecret(secret string) {
+	if secret == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.secrets = append(r.secrets, secret)
+}
+
+func (r *RecordingTransport) record(kind, method string, params, result interface{}, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	interaction := Interaction{Kind: kind, Method: method}
+	if params != nil {
+		interaction.Params = r.redact(params)
+	}
+	if err != nil {
+		interaction.Error = redactString(err.Error(), r.secrets)
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) {
+			interaction.RPCCode = rpcErr.Code
+			interaction.RPCMessage = redactString(rpcErr.Message, r.secrets)
+		}
+	} else if raw, ok := result.(json.RawMessage); !ok || len(raw) > 0 {
+		interaction.Result = r.redact(result)
+	}
+	r.Cassette.Interactions = append(r.Cassette.Interactions, interaction)
+}
+
+func (r *RecordingTransport) redact(v interface{}) json.RawMessage {
+	canonical, err := canonicalise(v, r.secrets)
+	if err != nil {
+		// Params that cannot be marshalled cannot be replayed either, so keep
+		// a readable marker
+		canonical, _ = json.Marshal(fmt.Sprintf("%T", v))
+	}
+	return canonical
+}
+
+func redactString(s string, secrets []string) string {
+	for _, secret := range secrets {
+		s = strings.Replace(s, secret, Redacted, -1)
+	}
+	return s
+}
+
+func NewReplayTransport(cassette *Cassette) *ReplayTransport {
+	// Saved cassettes are indented, so params are compacted back to the
+	// form requests are compared in
+	for i := range cassette.Interactions {
+		var compact bytes.Buffer
+		if json.Compact(&compact, cassette.Interactions[i].Params) == nil {
+			cassette.Interactions[i].Params = compact.Bytes()
+		}
+	}
+	return &ReplayTransport{
+		Cassette: cassette,
+		used:     make([]bool, len(cassette.Interactions)),
+	}
+}
+
+func (r *ReplayTransport) Authenticate() (*types.Authenticate, error) {
+	interaction, err := r.next(InteractionAuthenticate, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if interaction.Error != "" {
+		return nil, interaction.err()
+	}
+	var auth types.Authenticate
+	if err := json.Unmarshal(interaction.Result, &auth); err != nil {
+		return nil, err
+	}
+	return &auth, nil
+}
+
+func (r *ReplayTransport) SetSessionKey(key string) {}
+
+func (r *ReplayTransport) Do(id int, method string, filter *types.MarketFilter, additionalParams interface{}) ([]byte, error) {
+	return r.result(r.next(InteractionDo, method, &doParams{Filter: filter, Params: additionalParams}))
+}
+
+func (r *ReplayTransport) Call(id int, method string, params interface{}) ([]byte, error) {
+	return r.result(r.next(InteractionCall, method, params))
+}
+
+// Unused returns the recorded interactions that have not been replayed, for
+// tests that expect the whole cassette to be consumed
+func (r *ReplayTransport) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Interaction
+	for i, used := range r.used {
+		if !used {
+			unused = append(unused, r.Cassette.Interactions[i])
+		}
+	}
+	return unused
+}
+
+func (r *ReplayTransport) result(interaction *Interaction, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	if interaction.Error != "" {
+		return nil, interaction.err()
+	}
+	return []byte(interaction.Result), nil
+}
+
+// err rebuilds the error a recorded call returned
+func (i *Interaction) err() error {
+	if i.RPCCode != 0 || i.RPCMessage != "" {
+		return &RPCError{Code: i.RPCCode, Message: i.RPCMessage}
+	}
+	return errors.New(i.Error)
+}
+
+func (r *ReplayTransport) next(kind, method string, params interface{}) (*Interaction, error) {
+	var canonical json.RawMessage
+	if params != nil {
+		var err error
+		canonical, err = canonicalise(params, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.Cassette.Interactions {
+		interaction := &r.Cassette.Interactions[i]
+		if r.used[i] || interaction.Kind != kind || interaction.Method != method {
+			continue
+		}
+		if !bytes.Equal(interaction.Params, canonical) {
+			continue
+		}
+		r.used[i] = true
+		return interaction, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s %s", ErrNoInteraction, kind, method, canonical)
+}
+
+// canonicalise marshals v with sorted keys and exact numbers, redacting
+// sensitive members and any occurrence of the secrets
+func canonicalise(v interface{}, secrets []string) (json.RawMessage, error) {
+	buf, ok := v.(json.RawMessage)
+	if !ok {
+		var err error
+		if buf, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(redactValue(generic, secrets))
+}
+
+func redactValue(value interface{}, secrets []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, member := range v {
+			if sensitiveKeys[strings.ToLower(key)] {
+				v[key] = Redacted
+			} else {
+				v[key] = redactValue(member, secrets)
+			}
+		}
+	case []interface{}:
+		for i, element := range v {
+			v[i] = redactValue(element, secrets)
+		}
+	case string:
+		return redactString(v, secrets)
+	}
+	return value
+}
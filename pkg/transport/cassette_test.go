@@ -0,0 +1,125 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type fakeTransport struct {
+	calls int
+}
+
+func (f *fakeTransport) Authenticate() (*types.Authenticate, error) {
+	return &types.Authenticate{SessionToken: "live-token-123", LoginStatus: "SUCCESS"}, nil
+}
+
+func (f *fakeTransport) SetSessionKey(string) {}
+
+func (f *fakeTransport) Do(id int, method string, filter *types.MarketFilter, additionalParams interface{}) ([]byte, error) {
+	f.calls++
+	if method == "listCountries" {
+		return nil, errors.New("request failed for session live-token-123")
+	}
+	if method == "placeOrders" {
+		return nil, &RPCError{Code: -32099, Message: "ANGX-0003"}
+	}
+	return []byte(fmt.Sprintf(`[{"marketId":"1.%d","totalMatched":12345678.91}]`, f.calls)), nil
+}
+
+func (f *fakeTransport) Call(id int, method string, params interface{}) ([]byte, error) {
+	return []byte(`{"actionPerformed":"NONE","actualTimeoutSeconds":10}`), nil
+}
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "session.json")
+
+	books := &types.MarketFilterParams{MarketIds: []string{"1.1"}, PriceProjection: &types.PriceProjection{PriceData: []string{"EX_BEST_OFFERS"}}}
+	heartbeat := &types.HeartbeatParams{PreferredTimeoutSeconds: 10}
+
+	recorder := NewRecordingTransport(&fakeTransport{})
+	if _, err := recorder.Authenticate(); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := recorder.Do(1, "listMarketBook", nil, books)
+	second, _ := recorder.Do(1, "listMarketBook", nil, books)
+	_, _ = recorder.Do(1, "listCountries", &types.MarketFilter{EventTypeIds: []string{"1"}}, nil)
+	_, _ = recorder.Call(1, "HeartbeatAPING/v1.0/heartbeat", heartbeat)
+	_, _ = recorder.Do(1, "placeOrders", nil, nil)
+	if err := recorder.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, _ := ioutil.ReadFile(path)
+	if strings.Contains(string(saved), "live-token-123") {
+		t.Fatalf("cassette leaks the session token:\n%s", saved)
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay := NewReplayTransport(cassette)
+
+	auth, err := replay.Authenticate()
+	if err != nil || auth.SessionToken != Redacted || auth.LoginStatus != "SUCCESS" {
+		t.Errorf("authenticate %+v %v", auth, err)
+	}
+
+	// Identical requests are answered in the order they were recorded
+	for _, want := range [][]byte{first, second} {
+		got, err := replay.Do(1, "listMarketBook", nil, &types.MarketFilterParams{MarketIds: []string{"1.1"}, PriceProjection: &types.PriceProjection{PriceData: []string{"EX_BEST_OFFERS"}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var gotBooks, wantBooks []types.MarketBookWrapper
+		_ = json.Unmarshal(got, &gotBooks)
+		_ = json.Unmarshal(want, &wantBooks)
+		if len(gotBooks) != 1 || gotBooks[0].MarketId != wantBooks[0].MarketId || gotBooks[0].TotalMatched != wantBooks[0].TotalMatched {
+			t.Errorf("replayed %s, want %s", got, want)
+		}
+	}
+
+	if _, err := replay.Do(1, "listCountries", &types.MarketFilter{EventTypeIds: []string{"1"}}, nil); err == nil || err.Error() != "request failed for session REDACTED" {
+		t.Errorf("recorded error %v", err)
+	}
+	if _, err := replay.Call(1, "HeartbeatAPING/v1.0/heartbeat", heartbeat); err != nil {
+		t.Error(err)
+	}
+	var rpcErr *RPCError
+	if _, err := replay.Do(1, "placeOrders", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != -32099 || rpcErr.Message != "ANGX-0003" {
+		t.Errorf("recorded RPC error %v", err)
+	}
+	if unused := replay.Unused(); len(unused) != 0 {
+		t.Errorf("unused interactions %+v", unused)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		params interface{}
+	}{
+		{name: "exhausted", method: "listMarketBook", params: books},
+		{name: "different params", method: "listMarketBook", params: &types.MarketFilterParams{MarketIds: []string{"1.2"}}},
+		{name: "unrecorded method", method: "listEvents"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := replay.Do(1, tt.method, nil, tt.params); !errors.Is(err, ErrNoInteraction) {
+				t.Errorf("got %v, want ErrNoInteraction", err)
+			}
+		})
+	}
+}
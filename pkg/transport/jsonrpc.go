@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"github.com/guysports/go-betfair-api/pkg/types"
 	"github.com/hashicorp/go-retryablehttp"
@@ -21,11 +22,21 @@ type (
 		Config   *types.Config
 		Ctx      context.Context
 	}
+
+	// RPCError is an error the exchange returned in the JSON-RPC response, so
+	// the request was received and refused rather than lost
+	RPCError struct {
+		Code    int
+		Message string
+	}
 )
 
 const (
-	authenticateUrl = "https://identitysso-cert.betfair.com/api/certlogin"
-	jsonRPCUrl      = "https://api.betfair.com/exchange/betting/json-rpc/v1"
+	authenticateUrl     = "https://identitysso-cert.betfair.com/api/certlogin"
+	jsonRPCUrl          = "https://api.betfair.com/exchange/betting/json-rpc/v1"
+	accountJsonRPCUrl   = "https://api.betfair.com/exchange/account/json-rpc/v1"
+	heartbeatJsonRPCUrl = "https://api.betfair.com/exchange/heartbeat/json-rpc/v1"
+	scoresJsonRPCUrl    = "https://api.betfair.com/exchange/scores/json-rpc/v1"
 )
 
 func NewJsonRPCClient(ctx context.Context, config *types.Config) (*JsonRPCClient, error) {
@@ -99,7 +110,7 @@ func (r *JsonRPCClient) Authenticate() (*types.Authenticate, error) {
 	// Load a session key if it hasn't expired yet
 
 	body := []byte(fmt.Sprintf("username=%s&password=%s", r.Config.User, r.Config.Password))
-	req, err := retryablehttp.NewRequest(http.MethodPost, authenticateUrl, bytes.NewBuffer(body))
+	req, err := retryablehttp.NewRequest(http.MethodPost, endpoint(r.Config.IdentityURL, authenticateUrl), bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
 	}
@@ -137,21 +148,40 @@ func (r *JsonRPCClient) Do(id int, method string, filter *types.MarketFilter, ad
 		if instructionParams, ok := additionalParams.(*types.PlaceInstructionParams); ok {
 			params = createPlaceParams(instructionParams)
 		}
+		if cancelParams, ok := additionalParams.(*types.CancelInstructionParams); ok {
+			params = createCancelParams(cancelParams)
+		}
+		if replaceParams, ok := additionalParams.(*types.ReplaceInstructionParams); ok {
+			params = createReplaceParams(replaceParams)
+		}
+		if clearedParams, ok := additionalParams.(*types.ClearedOrdersParams); ok {
+			params = createClearedParams(clearedParams)
+		}
 	} else {
 		params.Filter = filter
 		params.Locale = "en"
 	}
+	return r.Call(id, fmt.Sprintf("SportsAPING/v1.0/%s", method), params)
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("Error returned from API %d [%s]", e.Code, e.Message)
+}
+
+// Call invokes a fully qualified JSON-RPC method such as HeartbeatAPING/v1.0/heartbeat,
+// routing it to the endpoint that serves its API
+func (r *JsonRPCClient) Call(id int, method string, params interface{}) ([]byte, error) {
 	query := types.JsonRPC{
 		JsonRPC:   "2.0",
 		RPCParams: params,
-		Method:    fmt.Sprintf("SportsAPING/v1.0/%s", method),
+		Method:    method,
 		ID:        id,
 	}
 	body, err := json.Marshal(&query)
 	if err != nil {
 		return nil, err
 	}
-	req, err := retryablehttp.NewRequest(http.MethodPost, jsonRPCUrl, bytes.NewBuffer(body))
+	req, err := retryablehttp.NewRequest(http.MethodPost, r.endpointFor(method), bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
 	}
@@ -174,8 +204,7 @@ func (r *JsonRPCClient) Do(id int, method string, filter *types.MarketFilter, ad
 	var rpcresp types.JsonRPCResponse
 	_ = json.Unmarshal(buf, &rpcresp)
 	if rpcresp.Error != nil {
-		fmt.Printf("Error returned from API %d [%s]", rpcresp.Error.Code, rpcresp.Error.Message)
-		return nil, fmt.Errorf("Error returned from API %d [%s]", rpcresp.Error.Code, rpcresp.Error.Message)
+		return nil, &RPCError{Code: rpcresp.Error.Code, Message: rpcresp.Error.Message}
 	}
 
 	payload, err := json.Marshal(rpcresp.Result)
@@ -186,6 +215,48 @@ func (r *JsonRPCClient) Do(id int, method string, filter *types.MarketFilter, ad
 	return payload, nil
 }
 
+// Get fetches a REST resource such as the navigation menu with the same
+// application key and session token as the JSON-RPC calls
+func (r *JsonRPCClient) Get(url string) ([]byte, error) {
+	req, err := retryablehttp.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Application", r.Config.AppKey)
+	req.Header.Set("X-Authentication", r.AuthData.SessionToken)
+	req.Header.Set("Accept", "application/json")
+	req = req.WithContext(r.Ctx)
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch %s with error %s [%d]", url, resp.Status, resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (r *JsonRPCClient) endpointFor(method string) string {
+	switch strings.SplitN(method, "/", 2)[0] {
+	case "AccountAPING":
+		return endpoint(r.Config.AccountURL, accountJsonRPCUrl)
+	case "HeartbeatAPING":
+		return endpoint(r.Config.HeartbeatURL, heartbeatJsonRPCUrl)
+	case "ScoresAPING":
+		return endpoint(r.Config.ScoresURL, scoresJsonRPCUrl)
+	}
+	return endpoint(r.Config.BettingURL, jsonRPCUrl)
+}
+
+func endpoint(configured, fallback string) string {
+	if configured != "" {
+		return configured
+	}
+	return fallback
+}
+
 func createParams(filter *types.MarketFilter, marketParams *types.MarketFilterParams) types.Params {
 	params := types.Params{
 		Filter: filter,
@@ -220,7 +291,7 @@ func createParams(filter *types.MarketFilter, marketParams *types.MarketFilterPa
 			params.PriceProjection = marketParams.PriceProjection
 		}
 		if marketParams.DateRange != nil {
-			params.DateRange = *marketParams.DateRange
+			params.DateRange = marketParams.DateRange
 		}
 	}
 
@@ -247,3 +318,48 @@ func createPlaceParams(instructionParams *types.PlaceInstructionParams) types.Pa
 
 	return params
 }
+
+func createCancelParams(cancelParams *types.CancelInstructionParams) types.Params {
+	params := types.Params{
+		Locale:      "en",
+		MarketId:    cancelParams.MarketID,
+		CustomerRef: cancelParams.CustomerRef,
+	}
+	// Omitting the instructions cancels every bet on the market, or on every
+	// market when the market ID is omitted too
+	if cancelParams.Instructions != nil {
+		params.Instructions = cancelParams.Instructions
+	}
+
+	return params
+}
+
+func createReplaceParams(replaceParams *types.ReplaceInstructionParams) types.Params {
+	params := types.Params{
+		Locale:      "en",
+		MarketId:    replaceParams.MarketID,
+		CustomerRef: replaceParams.CustomerRef,
+	}
+	if replaceParams.Instructions != nil {
+		params.Instructions = replaceParams.Instructions
+	}
+
+	return params
+}
+
+func createClearedParams(clearedParams *types.ClearedOrdersParams) types.Params {
+	return types.Params{
+		Locale:           "en",
+		BetStatus:        clearedParams.BetStatus,
+		EventTypeIds:     clearedParams.EventTypeIds,
+		EventIds:         clearedParams.EventIds,
+		MarketIds:        clearedParams.MarketIds,
+		RunnerIds:        clearedParams.RunnerIds,
+		BetIds:           clearedParams.BetIds,
+		Side:             clearedParams.Side,
+		SettledDateRange: clearedParams.SettledDateRange,
+		GroupBy:          clearedParams.GroupBy,
+		FromRecord:       clearedParams.FromRecord,
+		RecordCount:      clearedParams.RecordCount,
+	}
+}
@@ -0,0 +1,681 @@
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/matching"
+	"github.com/guysports/go-betfair-api/pkg/stream"
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type (
+	// PriceSource supplies the market books a SimulatedTransport matches
+	// orders against. Markets the source does not know are left out
+	PriceSource interface {
+		MarketBooks(marketIds []string) ([]types.MarketBookWrapper, error)
+	}
+
+	// LivePriceSource reads full depth books from the exchange
+	LivePriceSource struct {
+		Client types.TransportInterface
+	}
+
+	// CachePriceSource reads books from a stream cache, such as one fed from a
+	// historic file with historic.Reader.ReplayUntil
+	CachePriceSource struct {
+		Cache *stream.MarketCache
+	}
+
+	// SimulatedTransport is a paper trading exchange. Market books come from
+	// the price source, orders are matched locally by a matching.Engine and
+	// current and cleared orders are served from its own book, so code written
+	// against betting.API runs unchanged without money at risk. Every other
+	// method is passed to Upstream when one is set
+	SimulatedTransport struct {
+		Source   PriceSource
+		Upstream types.TransportInterface
+		Engine   *matching.Engine
+		// Now is the simulated clock, time.Now unless replaying recorded prices
+		Now func() time.Time
+
+		mu      sync.Mutex
+		settled map[string]bool
+		cleared []clearedOrder
+	}
+
+	clearedOrder struct {
+		betStatus string
+		summary   types.ClearedOrderSummary
+	}
+)
+
+var (
+	fullDepthProjection = &types.PriceProjection{
+		PriceData: []string{"EX_ALL_OFFERS", "EX_TRADED", "SP_AVAILABLE", "SP_TRADED"},
+	}
+)
+
+func NewSimulatedTransport(source PriceSource, upstream types.TransportInterface) *SimulatedTransport {
+	return &SimulatedTransport{
+		Source:   source,
+		Upstream: upstream,
+		Engine:   matching.NewEngine(),
+		Now:      time.Now,
+		settled:  map[string]bool{},
+	}
+}
+
+func (p *LivePriceSource) MarketBooks(marketIds []string) ([]types.MarketBookWrapper, error) {
+	buf, err := p.Client.Do(1, "listMarketBook", nil, &types.MarketFilterParams{
+		MarketIds:       marketIds,
+		PriceProjection: fullDepthProjection,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var books []types.MarketBookWrapper
+	if err := json.Unmarshal(buf, &books); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+func (p *CachePriceSource) MarketBooks(marketIds []string) ([]types.MarketBookWrapper, error) {
+	var books []types.MarketBookWrapper
+	for _, marketId := range marketIds {
+		if book := p.Cache.Book(marketId); book != nil {
+			books = append(books, *book)
+		}
+	}
+	return books, nil
+}
+
+func (s *SimulatedTransport) Authenticate() (*types.Authenticate, error) {
+	if s.Upstream != nil {
+		return s.Upstream.Authenticate()
+	}
+	return &types.Authenticate{SessionToken: "simulated", LoginStatus: "SUCCESS"}, nil
+}
+
+func (s *SimulatedTransport) SetSessionKey(key string) {
+	if s.Upstream != nil {
+		s.Upstream.SetSessionKey(key)
+	}
+}
+
+func (s *SimulatedTransport) Do(id int, method string, filter *types.MarketFilter, additionalParams interface{}) ([]byte, error) {
+	var result interface{}
+	var err error
+	switch method {
+	case "listMarketBook":
+		params, _ := additionalParams.(*types.MarketFilterParams)
+		if params == nil {
+			return nil, fmt.Errorf("listMarketBook requires market IDs")
+		}
+		var books []types.MarketBookWrapper
+		books, err = s.refresh(params.MarketIds)
+		s.withOrders(books, params.OrderProjection, params.MatchProjection)
+		result = books
+	case "listRunnerBook":
+		params, _ := additionalParams.(*types.MarketFilterParams)
+		if params == nil {
+			return nil, fmt.Errorf("listRunnerBook requires a market ID")
+		}
+		var books []types.MarketBookWrapper
+		books, err = s.runnerBook(params.MarketId, params.SelectionId)
+		s.withOrders(books, params.OrderProjection, params.MatchProjection)
+		result = books
+	case "placeOrders":
+		params, _ := additionalParams.(*types.PlaceInstructionParams)
+		if params == nil {
+			return nil, fmt.Errorf("placeOrders requires instructions")
+		}
+		result, err = s.place(params)
+	case "cancelOrders":
+		params, _ := additionalParams.(*types.CancelInstructionParams)
+		if params == nil {
+			params = &types.CancelInstructionParams{}
+		}
+		result, err = s.cancel(params)
+	case "replaceOrders":
+		params, _ := additionalParams.(*types.ReplaceInstructionParams)
+		if params == nil {
+			return nil, fmt.Errorf("replaceOrders requires instructions")
+		}
+		result, err = s.replace(params)
+	case "listCurrentOrders":
+		params, _ := additionalParams.(*types.MarketFilterParams)
+		if params == nil {
+			params = &types.MarketFilterParams{}
+		}
+		result, err = s.currentOrders(params)
+	case "listClearedOrders":
+		params, _ := additionalParams.(*types.ClearedOrdersParams)
+		if params == nil {
+			params = &types.ClearedOrdersParams{}
+		}
+		result, err = s.clearedOrders(params)
+	default:
+		if s.Upstream == nil {
+			return nil, fmt.Errorf("%s is not supported by the simulated exchange", method)
+		}
+		return s.Upstream.Do(id, method, filter, additionalParams)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+func (s *SimulatedTransport) Call(id int, method string, params interface{}) ([]byte, error) {
+	if s.Upstream == nil {
+		return nil, fmt.Errorf("%s is not supported by the simulated exchange", method)
+	}
+	return s.Upstream.Call(id, method, params)
+}
+
+// refresh fetches the latest books, advances the simulated orders against
+// them and settles any market that has closed
+func (s *SimulatedTransport) refresh(marketIds []string) ([]types.MarketBookWrapper, error) {
+	if len(marketIds) == 0 {
+		return []types.MarketBookWrapper{}, nil
+	}
+	books, err := s.Source.MarketBooks(marketIds)
+	if err != nil {
+		return nil, err
+	}
+	now := s.Now()
+	for i := range books {
+		book := books[i]
+		s.Engine.Update(&book, now)
+		if book.Status == types.MarketStatusClosed {
+			s.settle(&book, now)
+		}
+	}
+	return books, nil
+}
+
+func (s *SimulatedTransport) book(marketId string) (*types.MarketBookWrapper, error) {
+	books, err := s.refresh([]string{marketId})
+	if err != nil {
+		return nil, err
+	}
+	for i := range books {
+		if books[i].MarketId == marketId {
+			return &books[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *SimulatedTransport) runnerBook(marketId string, selectionId int) ([]types.MarketBookWrapper, error) {
+	book, err := s.book(marketId)
+	if err != nil || book == nil {
+		return []types.MarketBookWrapper{}, err
+	}
+	runners := book.Runners[:0:0]
+	for _, runner := range book.Runners {
+		if runner.SelectionID == selectionId {
+			runners = append(runners, runner)
+		}
+	}
+	book.Runners = runners
+	return []types.MarketBookWrapper{*book}, nil
+}
+
+// withOrders adds the simulated orders and matches to the runners of books,
+// as the exchange does when an order or match projection is requested
+func (s *SimulatedTransport) withOrders(books []types.MarketBookWrapper, orderProjection, matchProjection string) {
+	if orderProjection == "" && matchProjection == "" {
+		return
+	}
+	for i := range books {
+		book := &books[i]
+		orders := s.Engine.Orders(book.MarketId)
+		// The runners may be shared with the price source's cache
+		book.Runners = append([]types.Runner(nil), book.Runners...)
+		for j := range book.Runners {
+			runner := &book.Runners[j]
+			var matched []*matching.Order
+			for _, order := range orders {
+				if order.SelectionId != runner.SelectionID || order.Handicap != runner.Handicap {
+					continue
+				}
+				complete := order.Status == types.OrderStatusExecutionComplete
+				if orderProjection == types.OrderProjectionAll ||
+					(orderProjection == types.OrderProjectionExecutable && !complete) ||
+					(orderProjection == types.OrderProjectionExecutionComplete && complete) {
+					runner.Orders = append(runner.Orders, runnerOrder(order))
+				}
+				matched = append(matched, order)
+			}
+			if matchProjection != "" {
+				runner.Matches = runnerMatches(matched, matchProjection)
+			}
+		}
+	}
+}
+
+func runnerOrder(order *matching.Order) types.RunnerOrder {
+	current := currentOrder(order)
+	return types.RunnerOrder{
+		BetId:               current.BetId,
+		OrderType:           current.OrderType,
+		Status:              current.Status,
+		PersistenceType:     current.PersistanceType,
+		Side:                current.Side,
+		Price:               current.PriceSize.Price,
+		Size:                current.PriceSize.Size,
+		BspLiability:        current.BspLiability,
+		PlacedDate:          current.PlacedDate,
+		AveragePriceMatched: current.AveragePriceMatched,
+		SizeMatched:         current.SizeMatched,
+		SizeRemaining:       current.SizeRemaining,
+		SizeLapsed:          current.SizeLapsed,
+		SizeCancelled:       current.SizeCancelled,
+		SizeVoided:          current.SizeVoided,
+		CustomerOrderRef:    current.CustomerOrderRef,
+		CustomerStrategyRef: current.CustomerStrategyRef,
+	}
+}
+
+// runnerMatches lists every match, or rolls them up by side and price or by
+// side at the average price
+func runnerMatches(orders []*matching.Order, projection string) []types.RunnerMatch {
+	var matches []types.RunnerMatch
+	rollUp := func(side string, price, size types.Decimal) {
+		for i := range matches {
+			if matches[i].Side != side {
+				continue
+			}
+			switch {
+			case projection == types.MatchProjectionRolledUpByAvgPrice:
+				total := matches[i].Size.Add(size)
+				matches[i].Price = matches[i].Price.Mul(matches[i].Size).Add(price.Mul(size)).Div(total).Round(types.PricePlaces)
+				matches[i].Size = total
+				return
+			case matches[i].Price == price:
+				matches[i].Size = matches[i].Size.Add(size)
+				return
+			}
+		}
+		matches = append(matches, types.RunnerMatch{Side: side, Price: price, Size: size})
+	}
+	for _, order := range orders {
+		for n, match := range order.Matches {
+			if projection == types.MatchProjectionNoRollup {
+				matches = append(matches, types.RunnerMatch{
+					BetId:     order.BetId,
+					MatchId:   fmt.Sprintf("%s-%d", order.BetId, n+1),
+					Side:      order.Side,
+					Price:     match.Price,
+					Size:      match.Size,
+					MatchDate: match.At.UTC().Format(time.RFC3339Nano),
+				})
+				continue
+			}
+			rollUp(order.Side, match.Price, match.Size)
+		}
+	}
+	return matches
+}
+
+func (s *SimulatedTransport) place(params *types.PlaceInstructionParams) (*types.PlaceExecutionReport, error) {
+	book, err := s.book(params.MarketID)
+	if err != nil {
+		return nil, err
+	}
+	if book == nil || book.Status == types.MarketStatusClosed {
+		return &types.PlaceExecutionReport{
+			Status:      types.InstructionStatusFailure,
+			ErrorCode:   "MARKET_NOT_OPEN_FOR_BETTING",
+			CustomerRef: params.CustomerRef,
+			MarketID:    params.MarketID,
+		}, nil
+	}
+	return s.Engine.Place(params, book, s.Now()), nil
+}
+
+func (s *SimulatedTransport) cancel(params *types.CancelInstructionParams) (*types.CancelExecutionReport, error) {
+	marketIds := s.openMarkets(params.MarketID)
+	if _, err := s.refresh(marketIds); err != nil {
+		return nil, err
+	}
+
+	// Without instructions every unmatched order on the market is cancelled
+	instructions := params.Instructions
+	if len(instructions) == 0 {
+		for _, marketId := range marketIds {
+			for _, order := range s.Engine.Orders(marketId) {
+				if order.SizeRemaining > 0 {
+					instructions = append(instructions, types.CancelInstruction{BetId: order.BetId})
+				}
+			}
+		}
+	}
+
+	report := &types.CancelExecutionReport{
+		Status:      types.InstructionStatusSuccess,
+		CustomerRef: params.CustomerRef,
+		MarketID:    params.MarketID,
+	}
+	now := s.Now().UTC().Format(time.RFC3339Nano)
+	for _, instruction := range instructions {
+		ir := types.CancelInstructionReport{Instruction: instruction}
+		cancelled, err := s.cancelOrder(params.MarketID, instruction.BetId, instruction.SizeReduction)
+		if err != nil {
+			ir.Status = types.InstructionStatusFailure
+			ir.ErrorCode = cancelErrorCode(err)
+			report.Status = types.InstructionStatusFailure
+			report.ErrorCode = "BET_ACTION_ERROR"
+		} else {
+			ir.Status = types.InstructionStatusSuccess
+			ir.SizeCancelled = cancelled
+			ir.CancelledDate = now
+		}
+		report.InstructionReports = append(report.InstructionReports, ir)
+	}
+	return report, nil
+}
+
+func (s *SimulatedTransport) cancelOrder(marketId, betId string, sizeReduction types.Decimal) (types.Decimal, error) {
+	order := s.Engine.Order(betId)
+	if order == nil || (marketId != "" && order.MarketId != marketId) {
+		return 0, matching.ErrUnknownOrder
+	}
+	return s.Engine.Cancel(betId, sizeReduction)
+}
+
+func cancelErrorCode(err error) string {
+	if err == matching.ErrUnknownOrder {
+		return "INVALID_BET_ID"
+	}
+	return "BET_TAKEN_OR_LAPSED"
+}
+
+func (s *SimulatedTransport) replace(params *types.ReplaceInstructionParams) (*types.ReplaceExecutionReport, error) {
+	book, err := s.book(params.MarketID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.ReplaceExecutionReport{
+		Status:      types.InstructionStatusSuccess,
+		CustomerRef: params.CustomerRef,
+		MarketID:    params.MarketID,
+	}
+	fail := func(ir types.ReplaceInstructionReport, code string) {
+		ir.Status = types.InstructionStatusFailure
+		ir.ErrorCode = code
+		report.Status = types.InstructionStatusFailure
+		report.ErrorCode = "BET_ACTION_ERROR"
+		report.InstructionReports = append(report.InstructionReports, ir)
+	}
+	for _, instruction := range params.Instructions {
+		ir := types.ReplaceInstructionReport{}
+		order := s.Engine.Order(instruction.BetId)
+		if book == nil || book.Status == types.MarketStatusClosed {
+			fail(ir, "MARKET_NOT_OPEN_FOR_BETTING")
+			continue
+		}
+		if order == nil || order.MarketId != params.MarketID || order.OrderType != types.OrderTypeLimit {
+			fail(ir, "INVALID_BET_ID")
+			continue
+		}
+
+		cancel := types.CancelInstruction{BetId: instruction.BetId}
+		cancelled, err := s.Engine.Cancel(instruction.BetId, 0)
+		if err != nil {
+			ir.CancelInstructionReport = &types.CancelInstructionReport{
+				Status:      types.InstructionStatusFailure,
+				ErrorCode:   cancelErrorCode(err),
+				Instruction: cancel,
+			}
+			fail(ir, "CANCELLED_NOT_PLACED")
+			continue
+		}
+		ir.CancelInstructionReport = &types.CancelInstructionReport{
+			Status:        types.InstructionStatusSuccess,
+			Instruction:   cancel,
+			SizeCancelled: cancelled,
+			CancelledDate: s.Now().UTC().Format(time.RFC3339Nano),
+		}
+
+		// The replacement carries the cancelled remainder at the new price
+		placed := s.Engine.Place(&types.PlaceInstructionParams{
+			MarketID:            order.MarketId,
+			CustomerStrategyRef: order.CustomerStrategyRef,
+			Instructions: []types.PlaceInstruction{{
+				OrderType:   types.OrderTypeLimit,
+				SelectionId: order.SelectionId,
+				Handicap:    order.Handicap,
+				Side:        order.Side,
+				LimitOrder: &types.LimitOrder{
+					Size:            cancelled,
+					Price:           instruction.NewPrice,
+					PersistanceType: order.PersistenceType,
+				},
+				CustomerOrderRef: order.CustomerOrderRef,
+			}},
+		}, book, s.Now())
+		ir.PlaceInstructionReport = &placed.InstructionReports[0]
+		if placed.Status != types.InstructionStatusSuccess {
+			fail(ir, "CANCELLED_NOT_PLACED")
+			continue
+		}
+		ir.Status = types.InstructionStatusSuccess
+		report.InstructionReports = append(report.InstructionReports, ir)
+	}
+	return report, nil
+}
+
+// openMarkets lists the unsettled markets holding simulated orders, limited to
+// marketId when it is set
+func (s *SimulatedTransport) openMarkets(marketId string) []string {
+	if marketId != "" {
+		return []string{marketId}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var marketIds []string
+	seen := map[string]bool{}
+	for _, order := range s.Engine.Orders("") {
+		if !seen[order.MarketId] && !s.settled[order.MarketId] {
+			seen[order.MarketId] = true
+			marketIds = append(marketIds, order.MarketId)
+		}
+	}
+	return marketIds
+}
+
+func (s *SimulatedTransport) currentOrders(params *types.MarketFilterParams) (*types.CurrentOrdersWrapper, error) {
+	marketIds := params.MarketIds
+	if len(marketIds) == 0 {
+		marketIds = s.openMarkets("")
+	}
+	if _, err := s.refresh(marketIds); err != nil {
+		return nil, err
+	}
+
+	result := &types.CurrentOrdersWrapper{Orders: []types.CurrentOrder{}}
+	for _, marketId := range marketIds {
+		if s.isSettled(marketId) {
+			continue
+		}
+		for _, order := range s.Engine.Orders(marketId) {
+			complete := order.Status == types.OrderStatusExecutionComplete
+			if (params.OrderProjection == types.OrderProjectionExecutable && complete) ||
+				(params.OrderProjection == types.OrderProjectionExecutionComplete && !complete) {
+				continue
+			}
+			result.Orders = append(result.Orders, currentOrder(order))
+		}
+	}
+	return result, nil
+}
+
+func currentOrder(order *matching.Order) types.CurrentOrder {
+	current := types.CurrentOrder{
+		BetId:               order.BetId,
+		MarketId:            order.MarketId,
+		SelectionId:         order.SelectionId,
+		Handicap:            order.Handicap,
+		PriceSize:           types.Price{Price: order.Price, Size: order.Size},
+		Side:                order.Side,
+		Status:              order.Status,
+		PersistanceType:     order.PersistenceType,
+		OrderType:           order.OrderType,
+		PlacedDate:          order.PlacedAt.UTC().Format(time.RFC3339Nano),
+		AveragePriceMatched: order.AveragePriceMatched,
+		SizeMatched:         order.SizeMatched,
+		SizeRemaining:       order.SizeRemaining,
+		SizeLapsed:          order.SizeLapsed,
+		SizeCancelled:       order.SizeCancelled,
+		SizeVoided:          order.SizeVoided,
+		CustomerOrderRef:    order.CustomerOrderRef,
+		CustomerStrategyRef: order.CustomerStrategyRef,
+	}
+	if order.OrderType != types.OrderTypeLimit {
+		current.PriceSize.Size = 0
+		current.BspLiability = order.Size
+	}
+	if n := len(order.Matches); n > 0 {
+		current.MatchedDate = order.Matches[n-1].At.UTC().Format(time.RFC3339Nano)
+	}
+	return current
+}
+
+func (s *SimulatedTransport) isSettled(marketId string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.settled[marketId]
+}
+
+// settle moves a closed market's orders to the cleared book. Each order is
+// reported SETTLED, or VOIDED for a removed runner, when any of it matched and
+// LAPSED or CANCELLED otherwise
+func (s *SimulatedTransport) settle(book *types.MarketBookWrapper, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.settled[book.MarketId] {
+		return
+	}
+	s.settled[book.MarketId] = true
+
+	settledDate := at.UTC().Format(time.RFC3339Nano)
+	for _, settlement := range s.Engine.Settle(book) {
+		order := settlement.Order
+		summary := types.ClearedOrderSummary{
+			MarketId:            order.MarketId,
+			SelectionId:         order.SelectionId,
+			Handicap:            order.Handicap,
+			BetId:               order.BetId,
+			PlacedDate:          order.PlacedAt.UTC().Format(time.RFC3339Nano),
+			PersistenceType:     order.PersistenceType,
+			OrderType:           order.OrderType,
+			Side:                order.Side,
+			PriceRequested:      order.Price,
+			SettledDate:         settledDate,
+			BetCount:            1,
+			PriceMatched:        order.AveragePriceMatched,
+			SizeSettled:         order.SizeMatched,
+			Profit:              settlement.Profit,
+			SizeCancelled:       order.SizeCancelled,
+			CustomerOrderRef:    order.CustomerOrderRef,
+			CustomerStrategyRef: order.CustomerStrategyRef,
+		}
+		if n := len(order.Matches); n > 0 {
+			summary.LastMatchedDate = order.Matches[n-1].At.UTC().Format(time.RFC3339Nano)
+		}
+
+		status := types.BetStatusSettled
+		switch {
+		case order.SizeMatched == 0 && order.SizeCancelled > 0:
+			status = types.BetStatusCancelled
+		case order.SizeMatched == 0:
+			status = types.BetStatusLapsed
+		case removed(book, order):
+			status = types.BetStatusVoided
+		case settlement.Profit >= 0:
+			summary.BetOutcome = types.BetOutcomeWon
+		default:
+			summary.BetOutcome = types.BetOutcomeLost
+		}
+		s.cleared = append(s.cleared, clearedOrder{betStatus: status, summary: summary})
+	}
+}
+
+func removed(book *types.MarketBookWrapper, order *matching.Order) bool {
+	for _, runner := range book.Runners {
+		if runner.SelectionID == order.SelectionId && runner.Handicap == order.Handicap {
+			return runner.Status == types.RunnerStatusRemoved
+		}
+	}
+	return true
+}
+
+func (s *SimulatedTransport) clearedOrders(params *types.ClearedOrdersParams) (*types.ClearedOrderSummaryReport, error) {
+	// Bring markets holding open orders up to date so any that have closed settle
+	if _, err := s.refresh(s.openMarkets("")); err != nil {
+		return nil, err
+	}
+
+	betStatus := params.BetStatus
+	if betStatus == "" {
+		betStatus = types.BetStatusSettled
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := &types.ClearedOrderSummaryReport{ClearedOrders: []types.ClearedOrderSummary{}}
+	for _, cleared := range s.cleared {
+		summary := cleared.summary
+		if cleared.betStatus != betStatus ||
+			!matches(params.MarketIds, summary.MarketId) ||
+			!matches(params.BetIds, summary.BetId) ||
+			(params.Side != "" && params.Side != summary.Side) {
+			continue
+		}
+		if len(params.RunnerIds) > 0 && !containsInt(params.RunnerIds, summary.SelectionId) {
+			continue
+		}
+		result.ClearedOrders = append(result.ClearedOrders, summary)
+	}
+
+	if params.FromRecord > 0 {
+		if params.FromRecord >= len(result.ClearedOrders) {
+			result.ClearedOrders = result.ClearedOrders[:0]
+		} else {
+			result.ClearedOrders = result.ClearedOrders[params.FromRecord:]
+		}
+	}
+	if params.RecordCount > 0 && len(result.ClearedOrders) > params.RecordCount {
+		result.ClearedOrders = result.ClearedOrders[:params.RecordCount]
+		result.MoreAvailable = true
+	}
+	return result, nil
+}
+
+func matches(filter []string, value string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, v := range filter {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
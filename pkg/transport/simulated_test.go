@@ -0,0 +1,203 @@
+package transport
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/guysports/go-betfair-api/pkg/types"
+)
+
+type staticPriceSource struct {
+	book types.MarketBookWrapper
+}
+
+func (p *staticPriceSource) MarketBooks(marketIds []string) ([]types.MarketBookWrapper, error) {
+	for _, marketId := range marketIds {
+		if marketId == p.book.MarketId {
+			return []types.MarketBookWrapper{p.book}, nil
+		}
+	}
+	return nil, nil
+}
+
+func simulatedBook(status string, winner int) types.MarketBookWrapper {
+	runner := func(id int) types.Runner {
+		r := types.Runner{SelectionID: id, Status: types.RunnerStatusActive}
+		if status == types.MarketStatusClosed {
+			r.Status = types.RunnerStatusLoser
+			if id == winner {
+				r.Status = types.RunnerStatusWinner
+			}
+		}
+		return r
+	}
+	book := types.MarketBookWrapper{
+		MarketId: "1.1",
+		Status:   status,
+		Runners:  []types.Runner{runner(1), runner(2)},
+	}
+	book.Runners[0].Exchange = types.ExchangePrices{
+		AvailableToBack: []types.Odds{{Price: types.MustParseDecimal("2.5"), Size: types.MustParseDecimal("20")}},
+		AvailableToLay:  []types.Odds{{Price: types.MustParseDecimal("2.6"), Size: types.MustParseDecimal("30")}},
+	}
+	return book
+}
+
+func simulatedDo(t *testing.T, s *SimulatedTransport, method string, params interface{}, result interface{}) {
+	t.Helper()
+	buf, err := s.Do(1, method, nil, params)
+	if err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+	if err := json.Unmarshal(buf, result); err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+}
+
+func simulatedLimit(side, price, size string) *types.PlaceInstructionParams {
+	return &types.PlaceInstructionParams{
+		MarketID: "1.1",
+		Instructions: []types.PlaceInstruction{{
+			OrderType:   types.OrderTypeLimit,
+			SelectionId: 1,
+			Side:        side,
+			LimitOrder: &types.LimitOrder{
+				Price:           types.MustParseDecimal(price),
+				Size:            types.MustParseDecimal(size),
+				PersistanceType: types.PersistenceLapse,
+			},
+		}},
+	}
+}
+
+func TestSimulatedTransport_OrderLifecycle(t *testing.T) {
+	source := &staticPriceSource{book: simulatedBook(types.MarketStatusOpen, 0)}
+	s := NewSimulatedTransport(source, nil)
+	s.Now = func() time.Time { return time.Unix(1600000000, 0) }
+
+	var placed types.PlaceExecutionReport
+	simulatedDo(t, s, "placeOrders", simulatedLimit(types.SideBack, "2.4", "10"), &placed)
+	if placed.Status != types.InstructionStatusSuccess || placed.SizeMatched != types.NewMoney(10) || placed.AveragePriceMatched != types.MustParseDecimal("2.5") {
+		t.Fatalf("back: %+v", placed)
+	}
+	var lay types.PlaceExecutionReport
+	simulatedDo(t, s, "placeOrders", simulatedLimit(types.SideLay, "2", "5"), &lay)
+	if lay.OrderStatus != types.OrderStatusExecutable {
+		t.Fatalf("lay: %+v", lay)
+	}
+
+	var current types.CurrentOrdersWrapper
+	simulatedDo(t, s, "listCurrentOrders", &types.MarketFilterParams{OrderProjection: types.OrderStatusExecutable}, &current)
+	if len(current.Orders) != 1 || current.Orders[0].BetId != lay.BetId || current.Orders[0].SelectionId != 1 {
+		t.Fatalf("executable orders: %+v", current.Orders)
+	}
+
+	var cancelled types.CancelExecutionReport
+	simulatedDo(t, s, "cancelOrders", &types.CancelInstructionParams{MarketID: "1.1"}, &cancelled)
+	if cancelled.Status != types.InstructionStatusSuccess || len(cancelled.InstructionReports) != 1 ||
+		cancelled.InstructionReports[0].SizeCancelled != types.NewMoney(5) {
+		t.Fatalf("cancel: %+v", cancelled)
+	}
+	simulatedDo(t, s, "cancelOrders", &types.CancelInstructionParams{
+		MarketID:     "1.1",
+		Instructions: []types.CancelInstruction{{BetId: lay.BetId}},
+	}, &cancelled)
+	if cancelled.Status != types.InstructionStatusFailure || cancelled.InstructionReports[0].ErrorCode != "BET_TAKEN_OR_LAPSED" {
+		t.Fatalf("second cancel: %+v", cancelled)
+	}
+
+	// Nothing is offered at 3, so the bet waits until it is moved to 2.5
+	var unmatched types.PlaceExecutionReport
+	simulatedDo(t, s, "placeOrders", simulatedLimit(types.SideBack, "3", "4"), &unmatched)
+	var replaced types.ReplaceExecutionReport
+	simulatedDo(t, s, "replaceOrders", &types.ReplaceInstructionParams{
+		MarketID:     "1.1",
+		Instructions: []types.ReplaceInstruction{{BetId: unmatched.BetId, NewPrice: types.MustParseDecimal("2.5")}},
+	}, &replaced)
+	if replaced.Status != types.InstructionStatusSuccess {
+		t.Fatalf("replace: %+v", replaced)
+	}
+	if ir := replaced.InstructionReports[0]; ir.CancelInstructionReport.SizeCancelled != types.NewMoney(4) ||
+		ir.PlaceInstructionReport.SizeMatched != types.NewMoney(4) {
+		t.Fatalf("replace reports: %+v %+v", ir.CancelInstructionReport, ir.PlaceInstructionReport)
+	}
+
+	source.book = simulatedBook(types.MarketStatusClosed, 1)
+	var settled types.ClearedOrderSummaryReport
+	simulatedDo(t, s, "listClearedOrders", &types.ClearedOrdersParams{}, &settled)
+	var profit types.Decimal
+	for _, order := range settled.ClearedOrders {
+		if order.BetOutcome != types.BetOutcomeWon {
+			t.Errorf("bet %s outcome %s", order.BetId, order.BetOutcome)
+		}
+		profit = profit.Add(order.Profit)
+	}
+	if len(settled.ClearedOrders) != 2 || profit != types.NewMoney(21) {
+		t.Errorf("settled %d orders for %s", len(settled.ClearedOrders), profit)
+	}
+
+	var voided types.ClearedOrderSummaryReport
+	simulatedDo(t, s, "listClearedOrders", &types.ClearedOrdersParams{BetStatus: types.BetStatusCancelled}, &voided)
+	if len(voided.ClearedOrders) != 2 {
+		t.Errorf("cancelled orders: %+v", voided.ClearedOrders)
+	}
+
+	simulatedDo(t, s, "listCurrentOrders", &types.MarketFilterParams{}, &current)
+	if len(current.Orders) != 0 {
+		t.Errorf("current orders after settlement: %+v", current.Orders)
+	}
+	simulatedDo(t, s, "placeOrders", simulatedLimit(types.SideBack, "2", "2"), &placed)
+	if placed.Status != types.InstructionStatusFailure || placed.ErrorCode != "MARKET_NOT_OPEN_FOR_BETTING" {
+		t.Errorf("place on closed market: %+v", placed)
+	}
+}
+
+func TestSimulatedTransport_Unsupported(t *testing.T) {
+	s := NewSimulatedTransport(&staticPriceSource{}, nil)
+	if _, err := s.Do(1, "listEventTypes", nil, nil); err == nil {
+		t.Error("expected an error without an upstream transport")
+	}
+}
+
+func TestSimulatedTransport_MatchesOnce(t *testing.T) {
+	s := NewSimulatedTransport(&staticPriceSource{book: simulatedBook(types.MarketStatusOpen, 0)}, nil)
+	s.Now = func() time.Time { return time.Unix(1600000000, 0) }
+
+	// 20 is offered at 2.5 and the book never changes
+	var placed types.PlaceExecutionReport
+	simulatedDo(t, s, "placeOrders", simulatedLimit(types.SideBack, "2.5", "100"), &placed)
+	if placed.SizeMatched != types.NewMoney(20) {
+		t.Fatalf("placed: matched %s", placed.SizeMatched)
+	}
+	for i := 0; i < 3; i++ {
+		var current types.CurrentOrdersWrapper
+		simulatedDo(t, s, "listCurrentOrders", &types.MarketFilterParams{}, &current)
+		if len(current.Orders) != 1 || current.Orders[0].SizeMatched != types.NewMoney(20) || current.Orders[0].SizeRemaining != types.NewMoney(80) {
+			t.Fatalf("poll %d: %+v", i, current.Orders)
+		}
+	}
+
+	var books []types.MarketBookWrapper
+	simulatedDo(t, s, "listMarketBook", &types.MarketFilterParams{
+		MarketIds:       []string{"1.1"},
+		OrderProjection: types.OrderProjectionExecutable,
+		MatchProjection: types.MatchProjectionRolledUpByPrice,
+	}, &books)
+	runner := books[0].Runners[0]
+	if len(runner.Orders) != 1 || runner.Orders[0].BetId != placed.BetId || runner.Orders[0].SizeRemaining != types.NewMoney(80) {
+		t.Errorf("runner orders: %+v", runner.Orders)
+	}
+	if len(runner.Matches) != 1 || runner.Matches[0].Price != types.MustParseDecimal("2.5") || runner.Matches[0].Size != types.NewMoney(20) {
+		t.Errorf("runner matches: %+v", runner.Matches)
+	}
+	if len(books[0].Runners[1].Orders) != 0 {
+		t.Errorf("orders on the other runner: %+v", books[0].Runners[1].Orders)
+	}
+
+	var plain []types.MarketBookWrapper
+	simulatedDo(t, s, "listMarketBook", &types.MarketFilterParams{MarketIds: []string{"1.1"}}, &plain)
+	if len(plain[0].Runners[0].Orders) != 0 || len(plain[0].Runners[0].Matches) != 0 {
+		t.Errorf("orders without a projection: %+v", plain[0].Runners[0])
+	}
+}
@@ -0,0 +1,21 @@
+package types
+
+const (
+	WalletUK = "UK"
+)
+
+type (
+	AccountFundsParams struct {
+		Wallet string `json:"wallet,omitempty"`
+	}
+
+	AccountFundsResponse struct {
+		AvailableToBetBalance Decimal `json:"availableToBetBalance"`
+		Exposure              Decimal `json:"exposure"`
+		RetainedCommission    Decimal `json:"retainedCommission"`
+		ExposureLimit         Decimal `json:"exposureLimit"`
+		DiscountRate          Decimal `json:"discountRate"`
+		PointsBalance         int     `json:"pointsBalance"`
+		Wallet                string  `json:"wallet"`
+	}
+)
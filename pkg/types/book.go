@@ -0,0 +1,193 @@
+package types
+
+import (
+	"sort"
+)
+
+// Runner returns the runner with the selection and handicap, or nil
+func (m *MarketBookWrapper) Runner(selectionId int, handicap Decimal) *Runner {
+	for i := range m.Runners {
+		if m.Runners[i].SelectionID == selectionId && m.Runners[i].Handicap == handicap {
+			return &m.Runners[i]
+		}
+	}
+	return nil
+}
+
+// BackBookPercentage is the sum of 100 / best back price over the active
+// runners. Above 100 the book is overround
+func (m *MarketBookWrapper) BackBookPercentage() Decimal {
+	return m.bookPercentage(SideBack)
+}
+
+// LayBookPercentage is the sum of 100 / best lay price over the active runners
+func (m *MarketBookWrapper) LayBookPercentage() Decimal {
+	return m.bookPercentage(SideLay)
+}
+
+func (m *MarketBookWrapper) bookPercentage(side string) Decimal {
+	hundred := NewDecimalFromInt(100)
+	var total Decimal
+	for i := range m.Runners {
+		if m.Runners[i].Status != RunnerStatusActive {
+			continue
+		}
+		if best, ok := m.Runners[i].best(side); ok {
+			total = total.Add(hundred.Div(best.Price))
+		}
+	}
+	return total.Round(MoneyPlaces)
+}
+
+// ImpliedProbabilities is 1 / best back price for each active runner with a
+// back price, keyed by selection
+func (m *MarketBookWrapper) ImpliedProbabilities() map[int]float64 {
+	probabilities := map[int]float64{}
+	for i := range m.Runners {
+		if p, ok := m.Runners[i].ImpliedProbability(); ok && m.Runners[i].Status == RunnerStatusActive {
+			probabilities[m.Runners[i].SelectionID] = p
+		}
+	}
+	return probabilities
+}
+
+// NormalisedProbabilities scales the implied probabilities to sum to one,
+// removing the overround
+func (m *MarketBookWrapper) NormalisedProbabilities() map[int]float64 {
+	probabilities := m.ImpliedProbabilities()
+	var total float64
+	for _, p := range probabilities {
+		total += p
+	}
+	if total > 0 {
+		for selectionId, p := range probabilities {
+			probabilities[selectionId] = p / total
+		}
+	}
+	return probabilities
+}
+
+// FavouriteRanking orders the active runners' selections from favourite to
+// outsider by best back price, then last price traded. Runners with neither
+// come last
+func (m *MarketBookWrapper) FavouriteRanking() []int {
+	type ranked struct {
+		selectionId int
+		price       Decimal
+	}
+	var runners []ranked
+	for i := range m.Runners {
+		runner := &m.Runners[i]
+		if runner.Status != RunnerStatusActive {
+			continue
+		}
+		price := runner.LastPriceTraded
+		if best, ok := runner.BestBack(); ok {
+			price = best.Price
+		}
+		runners = append(runners, ranked{selectionId: runner.SelectionID, price: price})
+	}
+	sort.SliceStable(runners, func(i, j int) bool {
+		if (runners[i].price == 0) != (runners[j].price == 0) {
+			return runners[j].price == 0
+		}
+		return runners[i].price < runners[j].price
+	})
+	selections := make([]int, 0, len(runners))
+	for _, runner := range runners {
+		selections = append(selections, runner.selectionId)
+	}
+	return selections
+}
+
+func (r *Runner) BestBack() (Odds, bool) {
+	return r.best(SideBack)
+}
+
+func (r *Runner) BestLay() (Odds, bool) {
+	return r.best(SideLay)
+}
+
+func (r *Runner) best(side string) (Odds, bool) {
+	levels := r.levels(side)
+	if len(levels) == 0 || levels[0].Price == 0 {
+		return Odds{}, false
+	}
+	return levels[0], true
+}
+
+// levels are the offers a back or lay order would match against
+func (r *Runner) levels(side string) []Odds {
+	if side == SideLay {
+		return r.Exchange.AvailableToLay
+	}
+	return r.Exchange.AvailableToBack
+}
+
+// ImpliedProbability is 1 / best back price
+func (r *Runner) ImpliedProbability() (float64, bool) {
+	best, ok := r.BestBack()
+	if !ok {
+		return 0, false
+	}
+	return 1 / best.Price.Float64(), true
+}
+
+// Spread is the number of ticks between the best back and best lay prices
+func (r *Runner) Spread(ladder PriceLadder) (int, bool) {
+	back, ok := r.BestBack()
+	if !ok {
+		return 0, false
+	}
+	lay, ok := r.BestLay()
+	if !ok {
+		return 0, false
+	}
+	return ladder.Ticks(back.Price, lay.Price)
+}
+
+// WeightOfMoney is the share of the money in the top levels of the book that
+// is available to back, between 0 and 1. Zero levels counts every level
+func (r *Runner) WeightOfMoney(levels int) (float64, bool) {
+	back := sumSize(r.Exchange.AvailableToBack, levels)
+	lay := sumSize(r.Exchange.AvailableToLay, levels)
+	if back.Add(lay) <= 0 {
+		return 0, false
+	}
+	return back.Float64() / back.Add(lay).Float64(), true
+}
+
+// AvailableWithin totals the size offered to a back or lay order at the best
+// price and up to ticks worse
+func (r *Runner) AvailableWithin(ladder PriceLadder, side string, ticks int) Decimal {
+	best, ok := r.best(side)
+	if !ok {
+		return 0
+	}
+	// Backers take the available to back prices downwards, layers the
+	// available to lay prices upwards
+	step := -ticks
+	if side == SideLay {
+		step = ticks
+	}
+	// Past the end of the ladder every level counts
+	limit, bounded := ladder.Tick(best.Price, step)
+	var total Decimal
+	for _, level := range r.levels(side) {
+		if !bounded || (side == SideLay && level.Price <= limit) || (side != SideLay && level.Price >= limit) {
+			total = total.Add(level.Size)
+		}
+	}
+	return total
+}
+
+func sumSize(levels []Odds, n int) Decimal {
+	var total Decimal
+	for i, level := range levels {
+		if n > 0 && i >= n {
+			break
+		}
+		total = total.Add(level.Size)
+	}
+	return total
+}
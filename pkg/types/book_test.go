@@ -0,0 +1,101 @@
+package types
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func analyticsBook() *MarketBookWrapper {
+	d := MustParseDecimal
+	return &MarketBookWrapper{
+		MarketId: "1.1",
+		Runners: []Runner{
+			{SelectionID: 1, Status: RunnerStatusActive, LastPriceTraded: d("3"), Exchange: ExchangePrices{
+				AvailableToBack: []Odds{{Price: d("3"), Size: d("100")}, {Price: d("2.98"), Size: d("50")}, {Price: d("2.9"), Size: d("20")}},
+				AvailableToLay:  []Odds{{Price: d("3.05"), Size: d("80")}, {Price: d("3.1"), Size: d("40")}},
+			}},
+			{SelectionID: 2, Status: RunnerStatusActive, Exchange: ExchangePrices{
+				AvailableToBack: []Odds{{Price: d("2"), Size: d("200")}},
+				AvailableToLay:  []Odds{{Price: d("2.02"), Size: d("10")}},
+			}},
+			{SelectionID: 3, Status: RunnerStatusActive, LastPriceTraded: d("8"), Exchange: ExchangePrices{
+				AvailableToLay: []Odds{{Price: d("10"), Size: d("5")}},
+			}},
+			{SelectionID: 4, Status: RunnerStatusRemoved, Exchange: ExchangePrices{
+				AvailableToBack: []Odds{{Price: d("1.5"), Size: d("5")}},
+			}},
+		},
+	}
+}
+
+func TestMarketBookWrapper_Analytics(t *testing.T) {
+	book := analyticsBook()
+
+	if got := book.BackBookPercentage(); got.String() != "83.33" {
+		t.Errorf("BackBookPercentage() = %s, want 83.33", got)
+	}
+	if got := book.LayBookPercentage(); got.String() != "92.29" {
+		t.Errorf("LayBookPercentage() = %s, want 92.29", got)
+	}
+
+	implied := book.ImpliedProbabilities()
+	if len(implied) != 2 || math.Abs(implied[1]-1.0/3) > 1e-9 || implied[2] != 0.5 {
+		t.Errorf("ImpliedProbabilities() = %v", implied)
+	}
+	normalised := book.NormalisedProbabilities()
+	if math.Abs(normalised[1]-0.4) > 1e-9 || math.Abs(normalised[2]-0.6) > 1e-9 {
+		t.Errorf("NormalisedProbabilities() = %v, want 0.4 and 0.6", normalised)
+	}
+
+	if got := book.FavouriteRanking(); !reflect.DeepEqual(got, []int{2, 1, 3}) {
+		t.Errorf("FavouriteRanking() = %v, want [2 1 3]", got)
+	}
+	if book.Runner(4, 0) == nil || book.Runner(5, 0) != nil {
+		t.Error("Runner() lookup failed")
+	}
+}
+
+func TestRunner_Analytics(t *testing.T) {
+	book := analyticsBook()
+	favourite, outsider := book.Runner(1, 0), book.Runner(3, 0)
+
+	if spread, ok := favourite.Spread(ClassicLadder); !ok || spread != 1 {
+		t.Errorf("Spread() = %d, %v, want 1", spread, ok)
+	}
+	if _, ok := outsider.Spread(ClassicLadder); ok {
+		t.Error("Spread() without a back price succeeded")
+	}
+
+	tests := []struct {
+		levels int
+		want   float64
+	}{
+		{1, 100.0 / 180},
+		{0, 170.0 / 290},
+	}
+	for _, tt := range tests {
+		if got, ok := favourite.WeightOfMoney(tt.levels); !ok || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("WeightOfMoney(%d) = %v, want %v", tt.levels, got, tt.want)
+		}
+	}
+	if got, _ := outsider.WeightOfMoney(0); got != 0 {
+		t.Errorf("WeightOfMoney() with only lays = %v, want 0", got)
+	}
+
+	within := []struct {
+		side  string
+		ticks int
+		want  string
+	}{
+		{SideBack, 0, "100"},
+		{SideBack, 2, "150"},
+		{SideLay, 0, "80"},
+		{SideLay, 1, "120"},
+	}
+	for _, tt := range within {
+		if got := favourite.AvailableWithin(ClassicLadder, tt.side, tt.ticks); got.String() != tt.want {
+			t.Errorf("AvailableWithin(%s, %d) = %s, want %s", tt.side, tt.ticks, got, tt.want)
+		}
+	}
+}
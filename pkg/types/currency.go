@@ -0,0 +1,58 @@
+package types
+
+const (
+	DefaultCurrency = "GBP"
+)
+
+type (
+	// CurrencyRules are the exchange's per-currency stake limits. A stake below
+	// MinBetSize is still accepted when its payout reaches MinBetPayout. BSP
+	// backs need MinBSPBackStake and BSP lays a liability of MinBSPLiability
+	CurrencyRules struct {
+		MinBetSize      Decimal
+		MinBetPayout    Decimal
+		MinBSPBackStake Decimal
+		MinBSPLiability Decimal
+	}
+)
+
+var (
+	CurrencyParameters = map[string]CurrencyRules{
+		"GBP": {MinBetSize: NewMoney(1), MinBetPayout: NewMoney(10), MinBSPBackStake: NewMoney(1), MinBSPLiability: NewMoney(10)},
+		"EUR": {MinBetSize: NewMoney(1), MinBetPayout: NewMoney(10), MinBSPBackStake: NewMoney(1), MinBSPLiability: NewMoney(10)},
+		"USD": {MinBetSize: NewMoney(3), MinBetPayout: NewMoney(20), MinBSPBackStake: NewMoney(3), MinBSPLiability: NewMoney(20)},
+		"AUD": {MinBetSize: NewMoney(5), MinBetPayout: NewMoney(30), MinBSPBackStake: NewMoney(5), MinBSPLiability: NewMoney(30)},
+		"CAD": {MinBetSize: NewMoney(6), MinBetPayout: NewMoney(30), MinBSPBackStake: NewMoney(6), MinBSPLiability: NewMoney(30)},
+		"SGD": {MinBetSize: NewMoney(6), MinBetPayout: NewMoney(30), MinBSPBackStake: NewMoney(6), MinBSPLiability: NewMoney(30)},
+		"HKD": {MinBetSize: NewMoney(25), MinBetPayout: NewMoney(125), MinBSPBackStake: NewMoney(25), MinBSPLiability: NewMoney(125)},
+		"DKK": {MinBetSize: NewMoney(30), MinBetPayout: NewMoney(150), MinBSPBackStake: NewMoney(30), MinBSPLiability: NewMoney(150)},
+		"NOK": {MinBetSize: NewMoney(30), MinBetPayout: NewMoney(150), MinBSPBackStake: NewMoney(30), MinBSPLiability: NewMoney(150)},
+		"SEK": {MinBetSize: NewMoney(30), MinBetPayout: NewMoney(150), MinBSPBackStake: NewMoney(30), MinBSPLiability: NewMoney(150)},
+	}
+)
+
+// RulesForCurrency falls back to the GBP rules for an unknown or empty currency
+func RulesForCurrency(currency string) CurrencyRules {
+	if rules, ok := CurrencyParameters[currency]; ok {
+		return rules
+	}
+	return CurrencyParameters[DefaultCurrency]
+}
+
+// MeetsMinimumStake applies the minimum bet size and minimum bet payout rules
+// to a limit order stake at the given price
+func (c CurrencyRules) MeetsMinimumStake(size, price Decimal) bool {
+	if size >= c.MinBetSize {
+		return true
+	}
+	return size > 0 && size.Mul(price) >= c.MinBetPayout
+}
+
+// MinBSP is the smallest liability a BSP order on side may carry. For a back
+// the liability is the stake
+func (c CurrencyRules) MinBSP(side string) Decimal {
+	if side == SideLay {
+		return c.MinBSPLiability
+	}
+	return c.MinBSPBackStake
+}
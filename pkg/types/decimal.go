@@ -0,0 +1,280 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Betfair quotes money and odds to two decimal places
+	MoneyPlaces = 2
+	PricePlaces = 2
+
+	decimalPlaces = 6
+	decimalScale  = 1000000
+)
+
+type (
+	// Decimal is a fixed-point number stored as millionths so that stakes, prices
+	// and P&L round-trip through JSON without binary floating point drift
+	Decimal int64
+)
+
+var (
+	bigScale = big.NewInt(decimalScale)
+	pow10    = [...]int64{1, 10, 100, 1000, 10000, 100000, 1000000}
+)
+
+func NewDecimal(f float64) Decimal {
+	return Decimal(math.Round(f * decimalScale))
+}
+
+func NewDecimalFromInt(i int64) Decimal {
+	return Decimal(i * decimalScale)
+}
+
+// NewMoney rounds f to the nearest penny
+func NewMoney(f float64) Decimal {
+	return NewDecimal(f).Round(MoneyPlaces)
+}
+
+// NewPrice rounds f to the precision Betfair uses for odds
+func NewPrice(f float64) Decimal {
+	return NewDecimal(f).Round(PricePlaces)
+}
+
+// ParseDecimal reads a decimal or exponent number. Fractions such as "5/2",
+// which big.Rat would accept, are rejected
+func ParseDecimal(s string) (Decimal, error) {
+	if strings.Contains(s, "/") {
+		return 0, fmt.Errorf("invalid decimal %q", s)
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return 0, fmt.Errorf("invalid decimal %q", s)
+	}
+	r.Mul(r, new(big.Rat).SetInt(bigScale))
+	n := roundRat(r)
+	if !n.IsInt64() {
+		return 0, fmt.Errorf("decimal %q out of range", s)
+	}
+	return Decimal(n.Int64()), nil
+}
+
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// roundRat rounds half away from zero to the nearest integer
+func roundRat(r *big.Rat) *big.Int {
+	num := new(big.Int).Set(r.Num())
+	den := r.Denom()
+	neg := num.Sign() < 0
+	num.Abs(num)
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	if m.Lsh(m, 1).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if neg {
+		q.Neg(q)
+	}
+	return q
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	return d + o
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	return d - o
+}
+
+// Mul panics if the product is out of range
+func (d Decimal) Mul(o Decimal) Decimal {
+	r := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(int64(d)), big.NewInt(int64(o))), bigScale)
+	return fromRat(r)
+}
+
+// Div panics if o is zero, in the same way as integer division, or if the
+// quotient is out of range
+func (d Decimal) Div(o Decimal) Decimal {
+	if o == 0 {
+		panic("types: decimal division by zero")
+	}
+	r := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(int64(d)), bigScale), big.NewInt(int64(o)))
+	return fromRat(r)
+}
+
+func fromRat(r *big.Rat) Decimal {
+	n := roundRat(r)
+	if !n.IsInt64() {
+		panic("types: decimal overflow")
+	}
+	return Decimal(n.Int64())
+}
+
+func (d Decimal) MulInt(i int64) Decimal {
+	return d * Decimal(i)
+}
+
+func (d Decimal) Neg() Decimal {
+	return -d
+}
+
+func (d Decimal) Abs() Decimal {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func (d Decimal) Sign() int {
+	switch {
+	case d < 0:
+		return -1
+	case d > 0:
+		return 1
+	}
+	return 0
+}
+
+func (d Decimal) IsZero() bool {
+	return d == 0
+}
+
+func (d Decimal) Cmp(o Decimal) int {
+	return (d - o).Sign()
+}
+
+// Round rounds half away from zero to the given number of decimal places
+func (d Decimal) Round(places int) Decimal {
+	unit := placesUnit(places)
+	if unit == 1 {
+		return d
+	}
+	q, r := int64(d)/unit, int64(d)%unit
+	if r*2 >= unit {
+		q++
+	} else if r*2 <= -unit {
+		q--
+	}
+	return Decimal(q * unit)
+}
+
+// Truncate drops any digits beyond the given number of decimal places
+func (d Decimal) Truncate(places int) Decimal {
+	unit := placesUnit(places)
+	return Decimal(int64(d) / unit * unit)
+}
+
+func placesUnit(places int) int64 {
+	if places < 0 {
+		places = 0
+	}
+	if places >= decimalPlaces {
+		return 1
+	}
+	return pow10[decimalPlaces-places]
+}
+
+func (d Decimal) Float64() float64 {
+	return float64(d) / decimalScale
+}
+
+func (d Decimal) String() string {
+	s := d.StringFixed(decimalPlaces)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	return s
+}
+
+// StringFixed formats d rounded to exactly the given number of decimal places
+func (d Decimal) StringFixed(places int) string {
+	if places > decimalPlaces {
+		places = decimalPlaces
+	}
+	v := int64(d.Round(places))
+	sign := ""
+	if v < 0 {
+		sign = "-"
+	}
+	abs := uint64(v)
+	if v < 0 {
+		abs = uint64(-v)
+	}
+	whole := abs / decimalScale
+	if places <= 0 {
+		return sign + strconv.FormatUint(whole, 10)
+	}
+	frac := fmt.Sprintf("%06d", abs%decimalScale)[:places]
+	return sign + strconv.FormatUint(whole, 10) + "." + frac
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 1 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(string(data))
+		if err != nil {
+			return err
+		}
+		data = []byte(unquoted)
+	}
+	parsed, err := ParseDecimal(string(data))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// ProjectedPrice is a projected starting price. The exchange sends "NaN" or
+// "Infinity" until it can make a projection, which decode as zero
+type ProjectedPrice Decimal
+
+func (p ProjectedPrice) Decimal() Decimal {
+	return Decimal(p)
+}
+
+func (p ProjectedPrice) String() string {
+	return Decimal(p).String()
+}
+
+func (p ProjectedPrice) MarshalJSON() ([]byte, error) {
+	return Decimal(p).MarshalJSON()
+}
+
+func (p *ProjectedPrice) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case `"NaN"`, `"Infinity"`, `"-Infinity"`:
+		*p = 0
+		return nil
+	}
+	return (*Decimal)(p).UnmarshalJSON(data)
+}
+
+// UnmarshalText parses a decimal string, so a Decimal can be a command line flag
+func (d *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,108 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    Decimal
+		wantErr bool
+	}{
+		{name: "integer", in: "2", want: 2000000},
+		{name: "two places", in: "1.83", want: 1830000},
+		{name: "negative", in: "-0.5", want: -500000},
+		{name: "exponent", in: "1.2345E3", want: 1234500000},
+		{name: "rounds beyond six places", in: "0.0000005", want: 1},
+		{name: "garbage", in: "abc", wantErr: true},
+		{name: "fraction", in: "5/2", wantErr: true},
+		{name: "quoted fraction", in: "1/3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDecimal(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDecimal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDecimal() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	stake := MustParseDecimal("2.00")
+	price := MustParseDecimal("1.83")
+	tests := []struct {
+		name string
+		got  Decimal
+		want string
+	}{
+		{name: "payout", got: stake.Mul(price), want: "3.66"},
+		{name: "profit", got: stake.Mul(price.Sub(NewDecimalFromInt(1))), want: "1.66"},
+		{name: "implied probability", got: NewDecimalFromInt(1).Div(price), want: "0.546448"},
+		{name: "round half away from zero", got: MustParseDecimal("-1.125").Round(2), want: "-1.13"},
+		{name: "truncate", got: MustParseDecimal("1.129").Truncate(2), want: "1.12"},
+		{name: "money from float", got: NewMoney(1.8300000429), want: "1.83"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got.String() != tt.want {
+				t.Errorf("got %s, want %s", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimal_Overflow(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Mul() did not panic on overflow")
+		}
+	}()
+	NewDecimalFromInt(10000000).Mul(NewDecimalFromInt(10000000))
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	in := []byte(`{"price":1.83,"size":"2.10"}`)
+	var odds Odds
+	if err := json.Unmarshal(in, &odds); err != nil {
+		t.Fatal(err)
+	}
+	want := Odds{Price: MustParseDecimal("1.83"), Size: MustParseDecimal("2.1")}
+	if !reflect.DeepEqual(odds, want) {
+		t.Errorf("Unmarshal() = %v, want %v", odds, want)
+	}
+	out, err := json.Marshal(odds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"price":1.83,"size":2.1}` {
+		t.Errorf("Marshal() = %s", out)
+	}
+	if err := json.Unmarshal([]byte(`{"price":"5/2"}`), &odds); err == nil {
+		t.Errorf("Unmarshal() of a fraction = %v, want an error", odds)
+	}
+	if odds.Size.StringFixed(MoneyPlaces) != "2.10" {
+		t.Errorf("StringFixed() = %s", odds.Size.StringFixed(MoneyPlaces))
+	}
+}
+
+func TestProjectedPrice_JSON(t *testing.T) {
+	in := []byte(`{"nearPrice":"NaN","farPrice":"Infinity","actualSP":3.5}`)
+	var sp StartingPrices
+	if err := json.Unmarshal(in, &sp); err != nil {
+		t.Fatal(err)
+	}
+	if sp.NearPrice != 0 || sp.FarPrice != 0 || sp.ActualSP != MustParseDecimal("3.5") {
+		t.Errorf("Unmarshal() = %+v", sp)
+	}
+	if err := json.Unmarshal([]byte(`{"nearPrice":4.2}`), &sp); err != nil || sp.NearPrice.Decimal() != MustParseDecimal("4.2") {
+		t.Errorf("Unmarshal() = %+v, %v", sp, err)
+	}
+}
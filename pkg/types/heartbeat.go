@@ -0,0 +1,20 @@
+package types
+
+const (
+	HeartbeatActionNone                         = "NONE"
+	HeartbeatActionAllBetsCancelled             = "ALL_BETS_CANCELLED"
+	HeartbeatActionSomeBetsNotCancelled         = "SOME_BETS_NOT_CANCELLED"
+	HeartbeatActionCancellationRequestSubmitted = "CANCELLATION_REQUEST_SUBMITTED"
+	HeartbeatActionCancellationRequestError     = "CANCELLATION_REQUEST_ERROR"
+)
+
+type (
+	HeartbeatParams struct {
+		PreferredTimeoutSeconds int `json:"preferredTimeoutSeconds"`
+	}
+
+	HeartbeatReport struct {
+		ActionPerformed      string `json:"actionPerformed"`
+		ActualTimeoutSeconds int    `json:"actualTimeoutSeconds"`
+	}
+)
@@ -0,0 +1,44 @@
+package types
+
+type (
+	// HistoricFilter selects files from the historic data service. Plan is one
+	// of "Basic Plan", "Advanced Plan" or "Pro Plan"
+	HistoricFilter struct {
+		Sport                 string   `json:"sport"`
+		Plan                  string   `json:"plan"`
+		FromDay               int      `json:"fromDay"`
+		FromMonth             int      `json:"fromMonth"`
+		FromYear              int      `json:"fromYear"`
+		ToDay                 int      `json:"toDay"`
+		ToMonth               int      `json:"toMonth"`
+		ToYear                int      `json:"toYear"`
+		EventId               string   `json:"eventId,omitempty"`
+		EventName             string   `json:"eventName,omitempty"`
+		MarketTypesCollection []string `json:"marketTypesCollection"`
+		CountriesCollection   []string `json:"countriesCollection"`
+		FileTypeCollection    []string `json:"fileTypeCollection"`
+	}
+
+	DataPackage struct {
+		Sport          string `json:"sport"`
+		Plan           string `json:"plan"`
+		ForDate        string `json:"forDate"`
+		PurchaseItemId int    `json:"purchaseItemId"`
+	}
+
+	CollectionOption struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	CollectionOptions struct {
+		MarketTypesCollection []CollectionOption `json:"marketTypesCollection"`
+		CountriesCollection   []CollectionOption `json:"countriesCollection"`
+		FileTypeCollection    []CollectionOption `json:"fileTypeCollection"`
+	}
+
+	BasketDataSize struct {
+		TotalSizeMB float64 `json:"totalSizeMB"`
+		FileCount   int     `json:"fileCount"`
+	}
+)
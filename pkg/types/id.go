@@ -0,0 +1,27 @@
+package types
+
+import (
+	"encoding/json"
+)
+
+type (
+	// ID is an identifier that some APIs send as a number where the Betting
+	// API sends a string
+	ID string
+)
+
+func (id *ID) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = ID(s)
+		return nil
+	}
+	if string(data) == "null" {
+		return nil
+	}
+	*id = ID(data)
+	return nil
+}
@@ -0,0 +1,161 @@
+package types
+
+const (
+	PriceLadderClassic   = "CLASSIC"
+	PriceLadderFinest    = "FINEST"
+	PriceLadderLineRange = "LINE_RANGE"
+)
+
+type (
+	// PriceLadder describes the prices a market accepts, from the market
+	// description's priceLadderDescription and lineRangeInfo
+	PriceLadder struct {
+		Type string
+		Line *LineRangeInfo
+	}
+
+	ladderBand struct {
+		From      Decimal
+		To        Decimal
+		Increment Decimal
+	}
+)
+
+var (
+	ClassicLadder = PriceLadder{Type: PriceLadderClassic}
+	FinestLadder  = PriceLadder{Type: PriceLadderFinest}
+
+	MinPrice = MustParseDecimal("1.01")
+	MaxPrice = MustParseDecimal("1000")
+
+	classicBands = []ladderBand{
+		{From: MustParseDecimal("1.01"), To: MustParseDecimal("2"), Increment: MustParseDecimal("0.01")},
+		{From: MustParseDecimal("2"), To: MustParseDecimal("3"), Increment: MustParseDecimal("0.02")},
+		{From: MustParseDecimal("3"), To: MustParseDecimal("4"), Increment: MustParseDecimal("0.05")},
+		{From: MustParseDecimal("4"), To: MustParseDecimal("6"), Increment: MustParseDecimal("0.1")},
+		{From: MustParseDecimal("6"), To: MustParseDecimal("10"), Increment: MustParseDecimal("0.2")},
+		{From: MustParseDecimal("10"), To: MustParseDecimal("20"), Increment: MustParseDecimal("0.5")},
+		{From: MustParseDecimal("20"), To: MustParseDecimal("30"), Increment: MustParseDecimal("1")},
+		{From: MustParseDecimal("30"), To: MustParseDecimal("50"), Increment: MustParseDecimal("2")},
+		{From: MustParseDecimal("50"), To: MustParseDecimal("100"), Increment: MustParseDecimal("5")},
+		{From: MustParseDecimal("100"), To: MustParseDecimal("1000"), Increment: MustParseDecimal("10")},
+	}
+	finestBands = []ladderBand{
+		{From: MustParseDecimal("1.01"), To: MustParseDecimal("1000"), Increment: MustParseDecimal("0.01")},
+	}
+)
+
+// LadderFor returns the ladder for a market, defaulting to CLASSIC when the
+// catalogue was fetched without the MARKET_DESCRIPTION projection
+func LadderFor(description *MarketDescription) PriceLadder {
+	if description == nil || description.PriceLadderDescription == nil {
+		return ClassicLadder
+	}
+	return PriceLadder{
+		Type: description.PriceLadderDescription.Type,
+		Line: description.LineRangeInfo,
+	}
+}
+
+func (l PriceLadder) bands() []ladderBand {
+	switch l.Type {
+	case PriceLadderFinest:
+		return finestBands
+	case PriceLadderLineRange:
+		if l.Line == nil || l.Line.Interval <= 0 {
+			return nil
+		}
+		return []ladderBand{{From: l.Line.MinUnitValue, To: l.Line.MaxUnitValue, Increment: l.Line.Interval}}
+	}
+	return classicBands
+}
+
+// Index returns the position of price on the ladder counting from the lowest price
+func (l PriceLadder) Index(price Decimal) (int, bool) {
+	bands := l.bands()
+	offset := 0
+	for i, band := range bands {
+		last := i == len(bands)-1
+		if price >= band.From && (price < band.To || (last && price <= band.To)) {
+			steps := price.Sub(band.From)
+			if steps%band.Increment != 0 {
+				return 0, false
+			}
+			return offset + int(steps/band.Increment), true
+		}
+		offset += int(band.To.Sub(band.From) / band.Increment)
+	}
+	return 0, false
+}
+
+// Price returns the price at a ladder index
+func (l PriceLadder) Price(index int) (Decimal, bool) {
+	if index < 0 {
+		return 0, false
+	}
+	bands := l.bands()
+	for i, band := range bands {
+		count := int(band.To.Sub(band.From) / band.Increment)
+		if i == len(bands)-1 {
+			count++
+		}
+		if index < count {
+			return band.From.Add(band.Increment.MulInt(int64(index))), true
+		}
+		index -= count
+	}
+	return 0, false
+}
+
+func (l PriceLadder) IsValid(price Decimal) bool {
+	_, ok := l.Index(price)
+	return ok
+}
+
+// Tick moves price n ticks up the ladder, or down for negative n
+func (l PriceLadder) Tick(price Decimal, n int) (Decimal, bool) {
+	index, ok := l.Index(price)
+	if !ok {
+		return 0, false
+	}
+	return l.Price(index + n)
+}
+
+// Ticks returns the number of ticks from one price to another
+func (l PriceLadder) Ticks(from, to Decimal) (int, bool) {
+	fromIndex, ok := l.Index(from)
+	if !ok {
+		return 0, false
+	}
+	toIndex, ok := l.Index(to)
+	if !ok {
+		return 0, false
+	}
+	return toIndex - fromIndex, true
+}
+
+// Nearest snaps price to the ladder, rounding down for a back and up for a lay
+// so that the snapped order is never less likely to match
+func (l PriceLadder) Nearest(price Decimal, side string) (Decimal, bool) {
+	bands := l.bands()
+	if len(bands) == 0 {
+		return 0, false
+	}
+	lowest, highest := bands[0].From, bands[len(bands)-1].To
+	if price <= lowest {
+		return lowest, true
+	}
+	if price >= highest {
+		return highest, true
+	}
+	for _, band := range bands {
+		if price >= band.From && price < band.To {
+			below := band.From.Add(price.Sub(band.From) / band.Increment * band.Increment)
+			if below == price || side == SideBack {
+				return below, true
+			}
+			return below.Add(band.Increment), true
+		}
+	}
+	return 0, false
+}
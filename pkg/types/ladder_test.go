@@ -0,0 +1,63 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestPriceLadder_Classic(t *testing.T) {
+	tests := []struct {
+		name  string
+		price string
+		ticks int
+		want  string
+		valid bool
+	}{
+		{name: "bottom of ladder", price: "1.01", ticks: 1, want: "1.02", valid: true},
+		{name: "band boundary up", price: "1.99", ticks: 2, want: "2.02", valid: true},
+		{name: "band boundary down", price: "3", ticks: -1, want: "2.98", valid: true},
+		{name: "top of ladder", price: "990", ticks: 1, want: "1000", valid: true},
+		{name: "off the top", price: "1000", ticks: 1, valid: false},
+		{name: "not on ladder", price: "2.01", ticks: 0, valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ClassicLadder.Tick(MustParseDecimal(tt.price), tt.ticks)
+			if ok != tt.valid {
+				t.Fatalf("Tick() ok = %v, want %v", ok, tt.valid)
+			}
+			if ok && got.String() != tt.want {
+				t.Errorf("Tick() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if ticks, _ := ClassicLadder.Ticks(MinPrice, MaxPrice); ticks != 349 {
+		t.Errorf("Ticks() across the ladder = %d, want 349", ticks)
+	}
+}
+
+func TestPriceLadder_Nearest(t *testing.T) {
+	tests := []struct {
+		name   string
+		ladder PriceLadder
+		price  string
+		side   string
+		want   string
+	}{
+		{name: "back rounds down", ladder: ClassicLadder, price: "2.03", side: SideBack, want: "2.02"},
+		{name: "lay rounds up", ladder: ClassicLadder, price: "2.03", side: SideLay, want: "2.04"},
+		{name: "already valid", ladder: ClassicLadder, price: "4.5", side: SideLay, want: "4.5"},
+		{name: "finest", ladder: FinestLadder, price: "2.033", side: SideBack, want: "2.03"},
+		{name: "line range", ladder: PriceLadder{Type: PriceLadderLineRange, Line: &LineRangeInfo{
+			MinUnitValue: MustParseDecimal("0.5"), MaxUnitValue: MustParseDecimal("10.5"), Interval: MustParseDecimal("1"),
+		}}, price: "3", side: SideLay, want: "3.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.ladder.Nearest(MustParseDecimal(tt.price), tt.side)
+			if !ok || got.String() != tt.want {
+				t.Errorf("Nearest() = %s, %v, want %s", got, ok, tt.want)
+			}
+		})
+	}
+}
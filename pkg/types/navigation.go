@@ -0,0 +1,35 @@
+package types
+
+const (
+	NavigationGroup     = "GROUP"
+	NavigationEventType = "EVENT_TYPE"
+	NavigationEvent     = "EVENT"
+	NavigationRace      = "RACE"
+	NavigationMarket    = "MARKET"
+)
+
+type (
+	// NavigationNode is one entry in the navigation menu. Which of the optional
+	// fields are present depends on Type
+	NavigationNode struct {
+		Type     string            `json:"type"`
+		ID       ID                `json:"id"`
+		Name     string            `json:"name"`
+		Children []*NavigationNode `json:"children,omitempty"`
+
+		// EVENT
+		CountryCode string `json:"countryCode,omitempty"`
+		OpenDate    string `json:"openDate,omitempty"`
+
+		// RACE
+		Venue      string `json:"venue,omitempty"`
+		StartTime  string `json:"startTime,omitempty"`
+		RaceNumber string `json:"raceNumber,omitempty"`
+
+		// MARKET
+		ExchangeId      string `json:"exchangeId,omitempty"`
+		MarketType      string `json:"marketType,omitempty"`
+		MarketStartTime string `json:"marketStartTime,omitempty"`
+		NumberOfWinners ID     `json:"numberOfWinners,omitempty"`
+	}
+)
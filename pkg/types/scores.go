@@ -0,0 +1,269 @@
+package types
+
+import (
+	"strconv"
+)
+
+const (
+	RaceStatusDormant     RaceStatus = "DORMANT"
+	RaceStatusDelayed     RaceStatus = "DELAYED"
+	RaceStatusParading    RaceStatus = "PARADING"
+	RaceStatusGoingDown   RaceStatus = "GOINGDOWN"
+	RaceStatusGoingBehind RaceStatus = "GOINGBEHIND"
+	RaceStatusAtThePost   RaceStatus = "ATTHEPOST"
+	RaceStatusUnderOrders RaceStatus = "UNDERORDERS"
+	RaceStatusOff         RaceStatus = "OFF"
+	RaceStatusFinished    RaceStatus = "FINISHED"
+	RaceStatusFalseStart  RaceStatus = "FALSESTART"
+	RaceStatusPhotograph  RaceStatus = "PHOTOGRAPH"
+	RaceStatusResult      RaceStatus = "RESULT"
+	RaceStatusWeighedIn   RaceStatus = "WEIGHEDIN"
+	RaceStatusRaceVoid    RaceStatus = "RACEVOID"
+	RaceStatusAbandoned   RaceStatus = "ABANDONED"
+
+	ScoresResponseOK                     = "OK"
+	ScoresResponseNoNewUpdates           = "NO_NEW_UPDATES"
+	ScoresResponseNoLiveDataAvailable    = "NO_LIVE_DATA_AVAILABLE"
+	ScoresResponseServiceUnavailable     = "SERVICE_UNAVAILABLE"
+	ScoresResponseUnexpectedError        = "UNEXPECTED_ERROR"
+	ScoresResponseLiveDataTemporarilyOff = "LIVE_DATA_TEMPORARILY_UNAVAILABLE"
+)
+
+type (
+	RaceStatus string
+
+	RaceDetailsParams struct {
+		MeetingIds []string `json:"meetingIds,omitempty"`
+		RaceIds    []string `json:"raceIds,omitempty"`
+	}
+
+	RaceDetails struct {
+		MeetingId    string     `json:"meetingId"`
+		RaceId       string     `json:"raceId"`
+		RaceStatus   RaceStatus `json:"raceStatus"`
+		LastUpdated  string     `json:"lastUpdated"`
+		ResponseCode string     `json:"responseCode"`
+	}
+)
+
+var (
+	raceStatusOrder = map[RaceStatus]int{
+		RaceStatusDormant:     0,
+		RaceStatusDelayed:     1,
+		RaceStatusParading:    2,
+		RaceStatusGoingDown:   3,
+		RaceStatusGoingBehind: 4,
+		RaceStatusAtThePost:   5,
+		RaceStatusUnderOrders: 6,
+		RaceStatusFalseStart:  6,
+		RaceStatusOff:         7,
+		RaceStatusFinished:    8,
+		RaceStatusPhotograph:  8,
+		RaceStatusResult:      9,
+		RaceStatusWeighedIn:   10,
+		RaceStatusRaceVoid:    10,
+		RaceStatusAbandoned:   10,
+	}
+)
+
+// AtOrAfter reports whether the race has progressed at least as far as other,
+// e.g. status.AtOrAfter(RaceStatusUnderOrders) to stop quoting before the off.
+// Unknown statuses are never at or after anything
+func (s RaceStatus) AtOrAfter(other RaceStatus) bool {
+	position, ok := raceStatusOrder[s]
+	if !ok {
+		return false
+	}
+	return position >= raceStatusOrder[other]
+}
+
+const (
+	ScoresEventStatusNotStarted = "NOT_STARTED"
+	ScoresEventStatusInPlay     = "IN_PLAY"
+	ScoresEventStatusFinished   = "FINISHED"
+
+	IncidentGoal          = "GOAL"
+	IncidentOwnGoal       = "OWN_GOAL"
+	IncidentPenaltyGoal   = "PENALTY_GOAL"
+	IncidentYellowCard    = "YELLOW_CARD"
+	IncidentRedCard       = "RED_CARD"
+	IncidentSecondYellow  = "SECOND_YELLOW_CARD"
+	IncidentCorner        = "CORNER"
+	IncidentBreakOfServe  = "BREAK_OF_SERVE"
+	IncidentSetWon        = "SET_WON"
+	IncidentMatchFinished = "MATCH_FINISHED"
+)
+
+type (
+	AvailableEventsParams struct {
+		EventIds     []string `json:"eventIds,omitempty"`
+		EventTypeIds []string `json:"eventTypeIds,omitempty"`
+		EventStatus  []string `json:"eventStatus,omitempty"`
+	}
+
+	UpdateKey struct {
+		EventId                     string `json:"eventId"`
+		LastUpdateSequenceProcessed int64  `json:"lastUpdateSequenceProcessed,omitempty"`
+	}
+
+	UpdateKeysParams struct {
+		UpdateKeys []UpdateKey `json:"updateKeys"`
+	}
+
+	AvailableEvent struct {
+		EventId     ID     `json:"eventId"`
+		EventTypeId ID     `json:"eventTypeId"`
+		EventStatus string `json:"eventStatus"`
+	}
+
+	UpdateContext struct {
+		EventTime        string `json:"eventTime"`
+		UpdateSequence   int64  `json:"updateSequence"`
+		UpdateType       string `json:"updateType"`
+		EventTimeElapsed int    `json:"eventTimeElapsed,omitempty"`
+	}
+
+	TeamScore struct {
+		Name                      string   `json:"name"`
+		Score                     string   `json:"score"`
+		HalfTimeScore             string   `json:"halfTimeScore,omitempty"`
+		FullTimeScore             string   `json:"fullTimeScore,omitempty"`
+		PenaltiesScore            string   `json:"penaltiesScore,omitempty"`
+		PenaltiesSequence         []string `json:"penaltiesSequence,omitempty"`
+		Games                     string   `json:"games,omitempty"`
+		Sets                      string   `json:"sets,omitempty"`
+		NumberOfYellowCards       int      `json:"numberOfYellowCards,omitempty"`
+		NumberOfRedCards          int      `json:"numberOfRedCards,omitempty"`
+		NumberOfCards             int      `json:"numberOfCards,omitempty"`
+		NumberOfCorners           int      `json:"numberOfCorners,omitempty"`
+		NumberOfCornersFirstHalf  int      `json:"numberOfCornersFirstHalf,omitempty"`
+		NumberOfCornersSecondHalf int      `json:"numberOfCornersSecondHalf,omitempty"`
+		BookingPoints             int      `json:"bookingPoints,omitempty"`
+		IsServing                 bool     `json:"isServing,omitempty"`
+		GameSequence              []string `json:"gameSequence,omitempty"`
+	}
+
+	Score struct {
+		Home                TeamScore `json:"home"`
+		Away                TeamScore `json:"away"`
+		NumberOfYellowCards int       `json:"numberOfYellowCards,omitempty"`
+		NumberOfRedCards    int       `json:"numberOfRedCards,omitempty"`
+		NumberOfCorners     int       `json:"numberOfCorners,omitempty"`
+	}
+
+	EventScore struct {
+		EventId            ID             `json:"eventId"`
+		EventTypeId        ID             `json:"eventTypeId"`
+		EventStatus        string         `json:"eventStatus"`
+		ResponseCode       string         `json:"responseCode"`
+		UpdateContext      *UpdateContext `json:"updateContext,omitempty"`
+		Score              *Score         `json:"score,omitempty"`
+		MatchStatus        string         `json:"matchStatus,omitempty"`
+		TimeElapsed        int            `json:"timeElapsed,omitempty"`
+		ElapsedRegularTime int            `json:"elapsedRegularTime,omitempty"`
+		ElapsedAddedTime   int            `json:"elapsedAddedTime,omitempty"`
+		CurrentSet         int            `json:"currentSet,omitempty"`
+		CurrentGame        int            `json:"currentGame,omitempty"`
+	}
+
+	Incident struct {
+		Type           string `json:"type"`
+		Team           string `json:"team,omitempty"`
+		Player         string `json:"player,omitempty"`
+		ElapsedTime    int    `json:"elapsedTime,omitempty"`
+		UpdateSequence int64  `json:"updateSequence,omitempty"`
+	}
+
+	EventIncidents struct {
+		EventId       ID             `json:"eventId"`
+		EventTypeId   ID             `json:"eventTypeId"`
+		EventStatus   string         `json:"eventStatus"`
+		ResponseCode  string         `json:"responseCode"`
+		Values        []Incident     `json:"values"`
+		UpdateContext *UpdateContext `json:"updateContext,omitempty"`
+	}
+
+	FootballTeam struct {
+		Name          string
+		Goals         int
+		HalfTimeGoals int
+		YellowCards   int
+		RedCards      int
+		Corners       int
+	}
+
+	FootballScore struct {
+		Home        FootballTeam
+		Away        FootballTeam
+		MatchStatus string
+		Elapsed     int
+	}
+
+	TennisPlayer struct {
+		Name    string
+		Sets    int
+		Games   int
+		Points  string
+		Serving bool
+	}
+
+	TennisScore struct {
+		Home        TennisPlayer
+		Away        TennisPlayer
+		CurrentSet  int
+		CurrentGame int
+	}
+)
+
+// Football interprets the score for a soccer event
+func (e *EventScore) Football() FootballScore {
+	result := FootballScore{
+		MatchStatus: e.MatchStatus,
+		Elapsed:     e.TimeElapsed,
+	}
+	if e.Score == nil {
+		return result
+	}
+	team := func(t TeamScore) FootballTeam {
+		return FootballTeam{
+			Name:          t.Name,
+			Goals:         atoi(t.Score),
+			HalfTimeGoals: atoi(t.HalfTimeScore),
+			YellowCards:   t.NumberOfYellowCards,
+			RedCards:      t.NumberOfRedCards,
+			Corners:       t.NumberOfCorners,
+		}
+	}
+	result.Home = team(e.Score.Home)
+	result.Away = team(e.Score.Away)
+	return result
+}
+
+// Tennis interprets the score for a tennis event, where score holds the points
+// in the current game
+func (e *EventScore) Tennis() TennisScore {
+	result := TennisScore{
+		CurrentSet:  e.CurrentSet,
+		CurrentGame: e.CurrentGame,
+	}
+	if e.Score == nil {
+		return result
+	}
+	player := func(t TeamScore) TennisPlayer {
+		return TennisPlayer{
+			Name:    t.Name,
+			Sets:    atoi(t.Sets),
+			Games:   atoi(t.Games),
+			Points:  t.Score,
+			Serving: t.IsServing,
+		}
+	}
+	result.Home = player(e.Score.Home)
+	result.Away = player(e.Score.Away)
+	return result
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,119 @@
+package types
+
+import (
+	"time"
+)
+
+const (
+	StreamOpMarketChange = "mcm"
+
+	ChangeTypeSubImage   = "SUB_IMAGE"
+	ChangeTypeResubDelta = "RESUB_DELTA"
+	ChangeTypeHeartbeat  = "HEARTBEAT"
+)
+
+type (
+	// MarketChangeMessage is an Exchange Stream API mcm message, which is also
+	// the line format of Betfair's historic data files
+	MarketChangeMessage struct {
+		Op            string         `json:"op"`
+		ID            int            `json:"id,omitempty"`
+		Clk           string         `json:"clk,omitempty"`
+		InitialClk    string         `json:"initialClk,omitempty"`
+		PublishTime   int64          `json:"pt"`
+		ChangeType    string         `json:"ct,omitempty"`
+		SegmentType   string         `json:"segmentType,omitempty"`
+		ConflateMs    int            `json:"conflateMs,omitempty"`
+		HeartbeatMs   int            `json:"heartbeatMs,omitempty"`
+		MarketChanges []MarketChange `json:"mc,omitempty"`
+	}
+
+	MarketChange struct {
+		ID               string            `json:"id"`
+		MarketDefinition *MarketDefinition `json:"marketDefinition,omitempty"`
+		RunnerChanges    []RunnerChange    `json:"rc,omitempty"`
+		Image            bool              `json:"img,omitempty"`
+		TotalVolume      *Decimal          `json:"tv,omitempty"`
+		Conflated        bool              `json:"con,omitempty"`
+	}
+
+	MarketDefinition struct {
+		Status                string                  `json:"status"`
+		InPlay                bool                    `json:"inPlay"`
+		BetDelay              int                     `json:"betDelay"`
+		BspMarket             bool                    `json:"bspMarket"`
+		BspReconciled         bool                    `json:"bspReconciled"`
+		Complete              bool                    `json:"complete"`
+		CrossMatching         bool                    `json:"crossMatching"`
+		RunnersVoidable       bool                    `json:"runnersVoidable"`
+		TurnInPlayEnabled     bool                    `json:"turnInPlayEnabled"`
+		PersistenceEnabled    bool                    `json:"persistenceEnabled"`
+		NumberOfWinners       int                     `json:"numberOfWinners"`
+		NumberOfActiveRunners int                     `json:"numberOfActiveRunners"`
+		MarketBaseRate        Decimal                 `json:"marketBaseRate"`
+		MarketTime            string                  `json:"marketTime"`
+		SuspendTime           string                  `json:"suspendTime,omitempty"`
+		SettledTime           string                  `json:"settledTime,omitempty"`
+		OpenDate              string                  `json:"openDate,omitempty"`
+		EventId               string                  `json:"eventId"`
+		EventTypeId           string                  `json:"eventTypeId"`
+		EventName             string                  `json:"eventName,omitempty"`
+		Name                  string                  `json:"name,omitempty"`
+		MarketType            string                  `json:"marketType"`
+		BettingType           string                  `json:"bettingType"`
+		CountryCode           string                  `json:"countryCode,omitempty"`
+		Venue                 string                  `json:"venue,omitempty"`
+		Timezone              string                  `json:"timezone,omitempty"`
+		Regulators            []string                `json:"regulators,omitempty"`
+		Version               int64                   `json:"version"`
+		PriceLadderDefinition *PriceLadderDescription `json:"priceLadderDefinition,omitempty"`
+		LineRangeInfo         *LineRangeInfo          `json:"lineRangeInfo,omitempty"`
+		Runners               []RunnerDefinition      `json:"runners"`
+	}
+
+	RunnerDefinition struct {
+		ID               int      `json:"id"`
+		Handicap         Decimal  `json:"hc,omitempty"`
+		Name             string   `json:"name,omitempty"`
+		Status           string   `json:"status"`
+		SortPriority     int      `json:"sortPriority"`
+		AdjustmentFactor Decimal  `json:"adjustmentFactor,omitempty"`
+		BSP              *Decimal `json:"bsp,omitempty"`
+		RemovalDate      string   `json:"removalDate,omitempty"`
+	}
+
+	// RunnerChange carries full-depth ladders as [price, size] pairs and the
+	// best-price ladders as [level, price, size] triples. A size of zero
+	// removes the price or level
+	RunnerChange struct {
+		ID                         int             `json:"id"`
+		Handicap                   Decimal         `json:"hc,omitempty"`
+		LastTradedPrice            *Decimal        `json:"ltp,omitempty"`
+		TotalVolume                *Decimal        `json:"tv,omitempty"`
+		StartingPriceNear          *ProjectedPrice `json:"spn,omitempty"`
+		StartingPriceFar           *ProjectedPrice `json:"spf,omitempty"`
+		AvailableToBack            []PriceVol      `json:"atb,omitempty"`
+		AvailableToLay             []PriceVol      `json:"atl,omitempty"`
+		Traded                     []PriceVol      `json:"trd,omitempty"`
+		StartingPriceBack          []PriceVol      `json:"spb,omitempty"`
+		StartingPriceLay           []PriceVol      `json:"spl,omitempty"`
+		BestAvailableToBack        []LevelPriceVol `json:"batb,omitempty"`
+		BestAvailableToLay         []LevelPriceVol `json:"batl,omitempty"`
+		BestDisplayAvailableToBack []LevelPriceVol `json:"bdatb,omitempty"`
+		BestDisplayAvailableToLay  []LevelPriceVol `json:"bdatl,omitempty"`
+	}
+
+	PriceVol      [2]Decimal
+	LevelPriceVol [3]Decimal
+)
+
+func (m *MarketChangeMessage) Time() time.Time {
+	return time.Unix(0, m.PublishTime*int64(time.Millisecond)).UTC()
+}
+
+func (p PriceVol) Price() Decimal { return p[0] }
+func (p PriceVol) Size() Decimal  { return p[1] }
+
+func (l LevelPriceVol) Level() int     { return int(l[0].Truncate(0) / decimalScale) }
+func (l LevelPriceVol) Price() Decimal { return l[1] }
+func (l LevelPriceVol) Size() Decimal  { return l[2] }
@@ -8,11 +8,61 @@ const (
 	DefaultTimeout = 20 * time.Second
 )
 
+const (
+	SideBack = "BACK"
+	SideLay  = "LAY"
+
+	OrderTypeLimit         = "LIMIT"
+	OrderTypeLimitOnClose  = "LIMIT_ON_CLOSE"
+	OrderTypeMarketOnClose = "MARKET_ON_CLOSE"
+
+	PersistenceLapse         = "LAPSE"
+	PersistencePersist       = "PERSIST"
+	PersistenceMarketOnClose = "MARKET_ON_CLOSE"
+
+	MarketStatusInactive  = "INACTIVE"
+	MarketStatusOpen      = "OPEN"
+	MarketStatusSuspended = "SUSPENDED"
+	MarketStatusClosed    = "CLOSED"
+
+	RunnerStatusActive  = "ACTIVE"
+	RunnerStatusWinner  = "WINNER"
+	RunnerStatusLoser   = "LOSER"
+	RunnerStatusRemoved = "REMOVED"
+	RunnerStatusPlaced  = "PLACED"
+
+	OrderStatusPending           = "PENDING"
+	OrderStatusExecutable        = "EXECUTABLE"
+	OrderStatusExecutionComplete = "EXECUTION_COMPLETE"
+	OrderStatusExpired           = "EXPIRED"
+
+	InstructionStatusSuccess = "SUCCESS"
+	InstructionStatusFailure = "FAILURE"
+	InstructionStatusTimeout = "TIMEOUT"
+
+	BetStatusSettled   = "SETTLED"
+	BetStatusVoided    = "VOIDED"
+	BetStatusLapsed    = "LAPSED"
+	BetStatusCancelled = "CANCELLED"
+
+	BetOutcomeWon  = "WON"
+	BetOutcomeLost = "LOST"
+
+	OrderProjectionAll               = "ALL"
+	OrderProjectionExecutable        = "EXECUTABLE"
+	OrderProjectionExecutionComplete = "EXECUTION_COMPLETE"
+
+	MatchProjectionNoRollup           = "NO_ROLLUP"
+	MatchProjectionRolledUpByPrice    = "ROLLED_UP_BY_PRICE"
+	MatchProjectionRolledUpByAvgPrice = "ROLLED_UP_BY_AVG_PRICE"
+)
+
 type (
 	TransportInterface interface {
 		Authenticate() (*Authenticate, error)
 		SetSessionKey(key string)
 		Do(id int, method string, filter *MarketFilter, additionalParams interface{}) ([]byte, error)
+		Call(id int, method string, params interface{}) ([]byte, error)
 	}
 	// Login and Authenticate
 	Globals struct {
@@ -26,24 +76,43 @@ type (
 		User       string
 		Password   string
 		AppKey     string
+
+		// Endpoint overrides for other jurisdictions or a test server. Empty
+		// fields use the global exchange
+		IdentityURL  string
+		BettingURL   string
+		AccountURL   string
+		HeartbeatURL string
+		ScoresURL    string
 	}
 
 	Params struct {
-		Filter              *MarketFilter      `json:"filter,omitempty"`
-		Instructions        []PlaceInstruction `json:"instructions,omitempty"`
-		Granularity         *string            `json:"granularity,omitempty"`
-		MaxResults          int                `json:"maxResults,omitempty"`
-		MarketId            string             `json:"marketId,omitempty"`
-		MarketIds           []string           `json:"marketIds,omitempty"`
-		SelectionId         int                `json:"selectionId,omitempty"`
-		PriceProjection     *PriceProjection   `json:"priceProjection,omitempty"`
-		OrderProjection     string             `json:"orderProjection,omitempty"`
-		MatchProjection     string             `json:"matchProjection,omitempty"`
-		MarketProjection    []string           `json:"marketProjection,omitempty"`
-		Locale              string             `json:"locale,omitempty"`
-		CustomerRef         string             `json:"customerRef,omitempty"`
-		CustomerStrategyRef string             `json:"customerStrategyRef,omitempty"`
-		DateRange           TimeRange          `json:"dateRange"`
+		Filter                 *MarketFilter    `json:"filter,omitempty"`
+		Instructions           interface{}      `json:"instructions,omitempty"`
+		Granularity            *string          `json:"granularity,omitempty"`
+		MaxResults             int              `json:"maxResults,omitempty"`
+		MarketId               string           `json:"marketId,omitempty"`
+		MarketIds              []string         `json:"marketIds,omitempty"`
+		SelectionId            int              `json:"selectionId,omitempty"`
+		PriceProjection        *PriceProjection `json:"priceProjection,omitempty"`
+		OrderProjection        string           `json:"orderProjection,omitempty"`
+		MatchProjection        string           `json:"matchProjection,omitempty"`
+		MarketProjection       []string         `json:"marketProjection,omitempty"`
+		Locale                 string           `json:"locale,omitempty"`
+		CustomerRef            string           `json:"customerRef,omitempty"`
+		CustomerStrategyRef    string           `json:"customerStrategyRef,omitempty"`
+		DateRange              *TimeRange       `json:"dateRange,omitempty"`
+		BetStatus              string           `json:"betStatus,omitempty"`
+		EventTypeIds           []string         `json:"eventTypeIds,omitempty"`
+		EventIds               []string         `json:"eventIds,omitempty"`
+		RunnerIds              []int            `json:"runnerIds,omitempty"`
+		BetIds                 []string         `json:"betIds,omitempty"`
+		Side                   string           `json:"side,omitempty"`
+		SettledDateRange       *TimeRange       `json:"settledDateRange,omitempty"`
+		GroupBy                string           `json:"groupBy,omitempty"`
+		IncludeItemDescription bool             `json:"includeItemDescription,omitempty"`
+		FromRecord             int              `json:"fromRecord,omitempty"`
+		RecordCount            int              `json:"recordCount,omitempty"`
 	}
 
 	JsonError struct {
@@ -52,10 +121,10 @@ type (
 	}
 
 	JsonRPC struct {
-		JsonRPC   string `json:"jsonrpc"`
-		Method    string `json:"method"`
-		RPCParams Params `json:"params"`
-		ID        int    `json:"id"`
+		JsonRPC   string      `json:"jsonrpc"`
+		Method    string      `json:"method"`
+		RPCParams interface{} `json:"params"`
+		ID        int         `json:"id"`
 	}
 
 	JsonRPCResponse struct {
@@ -117,6 +186,32 @@ type (
 		CustomerStrategyRef string
 	}
 
+	CancelInstructionParams struct {
+		MarketID     string
+		Instructions []CancelInstruction
+		CustomerRef  string
+	}
+
+	ReplaceInstructionParams struct {
+		MarketID     string
+		Instructions []ReplaceInstruction
+		CustomerRef  string
+	}
+
+	ClearedOrdersParams struct {
+		BetStatus        string
+		EventTypeIds     []string
+		EventIds         []string
+		MarketIds        []string
+		RunnerIds        []int
+		BetIds           []string
+		Side             string
+		SettledDateRange *TimeRange
+		GroupBy          string
+		FromRecord       int
+		RecordCount      int
+	}
+
 	PriceProjection struct {
 		PriceData []string `json:"priceData"`
 	}
@@ -132,7 +227,7 @@ type (
 	Selection struct {
 		SelectionId int               `json:"selectionId"`
 		Name        string            `json:"runnerName"`
-		Handicap    float32           `json:"handicap"`
+		Handicap    Decimal           `json:"handicap"`
 		Ranking     int               `json:"sortPriority"`
 		Metadata    map[string]string `json:"metadata"`
 	}
@@ -174,10 +269,43 @@ type (
 	}
 
 	MarketCatalogueWrapper struct {
-		MarketId     string      `json:"marketId"`
-		MarketName   string      `json:"marketName"`
-		TotalMatched float64     `json:"totalMatched"`
-		Selections   []Selection `json:"runners"`
+		MarketId        string             `json:"marketId"`
+		MarketName      string             `json:"marketName"`
+		MarketStartTime string             `json:"marketStartTime,omitempty"`
+		Description     *MarketDescription `json:"description,omitempty"`
+		TotalMatched    Decimal            `json:"totalMatched"`
+		Selections      []Selection        `json:"runners"`
+		EventType       *Detail            `json:"eventType,omitempty"`
+		Competition     *Detail            `json:"competition,omitempty"`
+		Event           *Detail            `json:"event,omitempty"`
+	}
+
+	MarketDescription struct {
+		PersistenceEnabled     bool                    `json:"persistenceEnabled"`
+		BspMarket              bool                    `json:"bspMarket"`
+		MarketTime             string                  `json:"marketTime"`
+		SuspendTime            string                  `json:"suspendTime"`
+		BettingType            string                  `json:"bettingType"`
+		TurnInPlayEnabled      bool                    `json:"turnInPlayEnabled"`
+		MarketType             string                  `json:"marketType"`
+		Regulator              string                  `json:"regulator"`
+		MarketBaseRate         Decimal                 `json:"marketBaseRate"`
+		DiscountAllowed        bool                    `json:"discountAllowed"`
+		Wallet                 string                  `json:"wallet,omitempty"`
+		RaceType               string                  `json:"raceType,omitempty"`
+		LineRangeInfo          *LineRangeInfo          `json:"lineRangeInfo,omitempty"`
+		PriceLadderDescription *PriceLadderDescription `json:"priceLadderDescription,omitempty"`
+	}
+
+	LineRangeInfo struct {
+		MaxUnitValue Decimal `json:"maxUnitValue"`
+		MinUnitValue Decimal `json:"minUnitValue"`
+		Interval     Decimal `json:"interval"`
+		MarketUnit   string  `json:"marketUnit"`
+	}
+
+	PriceLadderDescription struct {
+		Type string `json:"type"`
 	}
 
 	MarketBookWrapper struct {
@@ -191,8 +319,8 @@ type (
 		NumberOfWinners     int      `json:"numberOfWinners"`
 		NumberOfRunners     int      `json:"numberOfRunners"`
 		LastMatchTime       string   `json:"lastMatchTime"`
-		TotalMatched        float32  `json:"totalMatched"`
-		TotalAvailable      float32  `json:"totalAvailable"`
+		TotalMatched        Decimal  `json:"totalMatched"`
+		TotalAvailable      Decimal  `json:"totalAvailable"`
 		CrossMatching       bool     `json:"crossMatching"`
 		RunnersVoidable     bool     `json:"runnersVoidable"`
 		Version             int64    `json:"version"`
@@ -200,12 +328,58 @@ type (
 	}
 
 	Runner struct {
-		SelectionID     int            `json:"selectionId"`
-		Handicap        float32        `json:"handicap"`
-		Status          string         `json:"status"`
-		LastPriceTraded float32        `json:"lastPriceTraded"`
-		TotalMatched    float32        `json:"totalMatched"`
-		Exchange        ExchangePrices `json:"ex"`
+		SelectionID      int             `json:"selectionId"`
+		Handicap         Decimal         `json:"handicap"`
+		Status           string          `json:"status"`
+		AdjustmentFactor Decimal         `json:"adjustmentFactor,omitempty"`
+		RemovalDate      string          `json:"removalDate,omitempty"`
+		LastPriceTraded  Decimal         `json:"lastPriceTraded"`
+		TotalMatched     Decimal         `json:"totalMatched"`
+		StartingPrices   *StartingPrices `json:"sp,omitempty"`
+		Exchange         ExchangePrices  `json:"ex"`
+		Orders           []RunnerOrder   `json:"orders,omitempty"`
+		Matches          []RunnerMatch   `json:"matches,omitempty"`
+	}
+
+	// RunnerOrder is one of your orders on a runner, returned by
+	// listMarketBook when an order projection is requested
+	RunnerOrder struct {
+		BetId               string  `json:"betId"`
+		OrderType           string  `json:"orderType"`
+		Status              string  `json:"status"`
+		PersistenceType     string  `json:"persistenceType"`
+		Side                string  `json:"side"`
+		Price               Decimal `json:"price"`
+		Size                Decimal `json:"size"`
+		BspLiability        Decimal `json:"bspLiability"`
+		PlacedDate          string  `json:"placedDate"`
+		AveragePriceMatched Decimal `json:"avgPriceMatched,omitempty"`
+		SizeMatched         Decimal `json:"sizeMatched,omitempty"`
+		SizeRemaining       Decimal `json:"sizeRemaining,omitempty"`
+		SizeLapsed          Decimal `json:"sizeLapsed,omitempty"`
+		SizeCancelled       Decimal `json:"sizeCancelled,omitempty"`
+		SizeVoided          Decimal `json:"sizeVoided,omitempty"`
+		CustomerOrderRef    string  `json:"customerOrderRef,omitempty"`
+		CustomerStrategyRef string  `json:"customerStrategyRef,omitempty"`
+	}
+
+	// RunnerMatch is your matched volume on a runner, per bet or rolled up by
+	// the match projection
+	RunnerMatch struct {
+		BetId     string  `json:"betId,omitempty"`
+		MatchId   string  `json:"matchId,omitempty"`
+		Side      string  `json:"side"`
+		Price     Decimal `json:"price"`
+		Size      Decimal `json:"size"`
+		MatchDate string  `json:"matchDate,omitempty"`
+	}
+
+	StartingPrices struct {
+		NearPrice         ProjectedPrice `json:"nearPrice,omitempty"`
+		FarPrice          ProjectedPrice `json:"farPrice,omitempty"`
+		BackStakeTaken    []Odds         `json:"backStakeTaken,omitempty"`
+		LayLiabilityTaken []Odds         `json:"layLiabilityTaken,omitempty"`
+		ActualSP          Decimal        `json:"actualSP,omitempty"`
 	}
 
 	ExchangePrices struct {
@@ -215,54 +389,67 @@ type (
 	}
 
 	Odds struct {
-		Price float32 `json:"price"`
-		Size  float32 `json:"size"`
+		Price Decimal `json:"price"`
+		Size  Decimal `json:"size"`
 	}
 
 	LimitOrder struct {
-		Size            float32
-		Price           float32
-		PersistanceType string
-		TimeInForce     string
-		MinFillSize     float32
-		BetTargetType   string
-		BetTargetSize   float32
+		Size            Decimal `json:"size"`
+		Price           Decimal `json:"price"`
+		PersistanceType string  `json:"persistenceType,omitempty"`
+		TimeInForce     string  `json:"timeInForce,omitempty"`
+		MinFillSize     Decimal `json:"minFillSize,omitempty"`
+		BetTargetType   string  `json:"betTargetType,omitempty"`
+		BetTargetSize   Decimal `json:"betTargetSize,omitempty"`
+	}
+
+	LimitOnCloseOrder struct {
+		Liability Decimal `json:"liability"`
+		Price     Decimal `json:"price"`
+	}
+
+	MarketOnCloseOrder struct {
+		Liability Decimal `json:"liability"`
 	}
 
 	PlaceInstruction struct {
-		OrderType        string  `json:"orderType"`
-		SelectionId      int     `json:"selectionId"`
-		Handicap         float32 `json:"handicap"`
-		Side             string  `json:"side"`
-		LimitOrder       Price   `json:"limitOrder"`
-		CustomerOrderRef string  `json:"customeOrderRef"`
+		OrderType          string              `json:"orderType"`
+		SelectionId        int                 `json:"selectionId"`
+		Handicap           Decimal             `json:"handicap"`
+		Side               string              `json:"side"`
+		LimitOrder         *LimitOrder         `json:"limitOrder,omitempty"`
+		LimitOnCloseOrder  *LimitOnCloseOrder  `json:"limitOnCloseOrder,omitempty"`
+		MarketOnCloseOrder *MarketOnCloseOrder `json:"marketOnCloseOrder,omitempty"`
+		CustomerOrderRef   string              `json:"customerOrderRef,omitempty"`
 	}
 
 	CurrentOrder struct {
 		BetId               string  `json:"betId"`
 		MarketId            string  `json:"marketId"`
-		SelectionId         string  `json:"selectionId"`
-		Handicap            float32 `json:"handicap"`
+		SelectionId         int     `json:"selectionId"`
+		Handicap            Decimal `json:"handicap"`
 		PriceSize           Price   `json:"priceSize"`
-		BspLiability        float32 `json:"bspLiability"`
+		BspLiability        Decimal `json:"bspLiability"`
 		Side                string  `json:"side"`
 		Status              string  `json:"status"`
-		PersistanceType     string  `json:"persistanceType"`
+		PersistanceType     string  `json:"persistenceType"`
 		OrderType           string  `json:"orderType"`
 		PlacedDate          string  `json:"placedDate"`
 		MatchedDate         string  `json:"matchedDate"`
-		AveragePriceMatched float32 `json:"averagePriceMatched"`
-		SizeMatched         float32 `json:"sizeMatched"`
-		SizeRemaining       float32 `json:"sizeRemaining"`
-		SizeLapsed          float32 `json:"sizeLapsed"`
-		SizeCancelled       float32 `json:"sizeCancelled"`
-		SizeVoided          float32 `json:"sizeVoided"`
+		AveragePriceMatched Decimal `json:"averagePriceMatched"`
+		SizeMatched         Decimal `json:"sizeMatched"`
+		SizeRemaining       Decimal `json:"sizeRemaining"`
+		SizeLapsed          Decimal `json:"sizeLapsed"`
+		SizeCancelled       Decimal `json:"sizeCancelled"`
+		SizeVoided          Decimal `json:"sizeVoided"`
 		RegulatorCode       string  `json:"regulatorCode"`
+		CustomerOrderRef    string  `json:"customerOrderRef,omitempty"`
+		CustomerStrategyRef string  `json:"customerStrategyRef,omitempty"`
 	}
 
 	Price struct {
-		Price float32 `json:"price"`
-		Size  float32 `json:"size"`
+		Price Decimal `json:"price"`
+		Size  Decimal `json:"size"`
 	}
 
 	CurrentOrdersWrapper struct {
@@ -271,21 +458,100 @@ type (
 	}
 
 	PlaceInstructionReport struct {
-		Status           string           `json:"status"`
-		Instruction      PlaceInstruction `json:"instruction"`
-		CustomerOrderRef string           `json:"customerOrderRef"`
-		OrderType        string           `json:"orderType"`
-		Side             string           `json:"side"`
+		Status              string           `json:"status"`
+		ErrorCode           string           `json:"errorCode,omitempty"`
+		OrderStatus         string           `json:"orderStatus,omitempty"`
+		Instruction         PlaceInstruction `json:"instruction"`
+		BetId               string           `json:"betId,omitempty"`
+		PlacedDate          string           `json:"placedDate,omitempty"`
+		AveragePriceMatched Decimal          `json:"averagePriceMatched,omitempty"`
+		SizeMatched         Decimal          `json:"sizeMatched,omitempty"`
+		CustomerOrderRef    string           `json:"customerOrderRef"`
+		OrderType           string           `json:"orderType"`
+		Side                string           `json:"side"`
 	}
 	PlaceExecutionReport struct {
 		Status              string                   `json:"status"`
+		ErrorCode           string                   `json:"errorCode,omitempty"`
 		CustomerRef         string                   `json:"customerRef"`
 		MarketID            string                   `json:"marketId"`
 		InstructionReports  []PlaceInstructionReport `json:"instructionReports"`
 		BetId               string                   `json:"betId"`
 		PlacedDate          string                   `json:"placedDate"`
-		AveragePriceMatched float32                  `json:"averagePriceMatched"`
-		SizeMatched         float32                  `json:"sizeMatched"`
+		AveragePriceMatched Decimal                  `json:"averagePriceMatched"`
+		SizeMatched         Decimal                  `json:"sizeMatched"`
 		OrderStatus         string                   `json:"orderStatus"`
 	}
+
+	CancelInstruction struct {
+		BetId         string  `json:"betId"`
+		SizeReduction Decimal `json:"sizeReduction,omitempty"`
+	}
+
+	CancelInstructionReport struct {
+		Status        string            `json:"status"`
+		ErrorCode     string            `json:"errorCode,omitempty"`
+		Instruction   CancelInstruction `json:"instruction"`
+		SizeCancelled Decimal           `json:"sizeCancelled"`
+		CancelledDate string            `json:"cancelledDate,omitempty"`
+	}
+
+	CancelExecutionReport struct {
+		Status             string                    `json:"status"`
+		ErrorCode          string                    `json:"errorCode,omitempty"`
+		CustomerRef        string                    `json:"customerRef,omitempty"`
+		MarketID           string                    `json:"marketId"`
+		InstructionReports []CancelInstructionReport `json:"instructionReports"`
+	}
+
+	ReplaceInstruction struct {
+		BetId    string  `json:"betId"`
+		NewPrice Decimal `json:"newPrice"`
+	}
+
+	ReplaceInstructionReport struct {
+		Status                  string                   `json:"status"`
+		ErrorCode               string                   `json:"errorCode,omitempty"`
+		CancelInstructionReport *CancelInstructionReport `json:"cancelInstructionReport,omitempty"`
+		PlaceInstructionReport  *PlaceInstructionReport  `json:"placeInstructionReport,omitempty"`
+	}
+
+	ReplaceExecutionReport struct {
+		Status             string                     `json:"status"`
+		ErrorCode          string                     `json:"errorCode,omitempty"`
+		CustomerRef        string                     `json:"customerRef,omitempty"`
+		MarketID           string                     `json:"marketId"`
+		InstructionReports []ReplaceInstructionReport `json:"instructionReports"`
+	}
+
+	ClearedOrderSummary struct {
+		EventTypeId         string  `json:"eventTypeId,omitempty"`
+		EventId             string  `json:"eventId,omitempty"`
+		MarketId            string  `json:"marketId"`
+		SelectionId         int     `json:"selectionId"`
+		Handicap            Decimal `json:"handicap"`
+		BetId               string  `json:"betId"`
+		PlacedDate          string  `json:"placedDate"`
+		PersistenceType     string  `json:"persistenceType"`
+		OrderType           string  `json:"orderType"`
+		Side                string  `json:"side"`
+		BetOutcome          string  `json:"betOutcome,omitempty"`
+		PriceRequested      Decimal `json:"priceRequested"`
+		SettledDate         string  `json:"settledDate"`
+		LastMatchedDate     string  `json:"lastMatchedDate,omitempty"`
+		BetCount            int     `json:"betCount"`
+		Commission          Decimal `json:"commission,omitempty"`
+		PriceMatched        Decimal `json:"priceMatched"`
+		PriceReduced        bool    `json:"priceReduced"`
+		SizeSettled         Decimal `json:"sizeSettled"`
+		Profit              Decimal `json:"profit"`
+		SizeCancelled       Decimal `json:"sizeCancelled"`
+		CustomerOrderRef    string  `json:"customerOrderRef,omitempty"`
+		CustomerStrategyRef string  `json:"customerStrategyRef,omitempty"`
+	}
+
+	ClearedOrderSummaryReport struct {
+		ClearedOrders []ClearedOrderSummary `json:"clearedOrders"`
+		MoreAvailable bool                  `json:"moreAvailable"`
+	}
 )